@@ -5,60 +5,489 @@
 // @BasePath        /
 //
 // @schemes         http
+//
+// @securityDefinitions.apikey BearerAuth
+// @in                         header
+// @name                       Authorization
+// @description                JWT в формате "Bearer <token>"
+//
+// @securityDefinitions.apikey ApiKeyAuth
+// @in                         header
+// @name                       Authorization
+// @description                Ключ сервиса в формате "ApiKey <key>"
 package main
 
 import (
 	"context"
+	"errors"
 	"log"
 	"log/slog"
+	"net/http"
 	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
 
 	_ "github.com/Aiszhio/Task/docs"
+	"github.com/Aiszhio/Task/internal/auth"
+	"github.com/Aiszhio/Task/internal/config"
 	"github.com/Aiszhio/Task/internal/db"
+	"github.com/Aiszhio/Task/internal/domain"
+	"github.com/Aiszhio/Task/internal/health"
+	"github.com/Aiszhio/Task/internal/metrics"
 	"github.com/Aiszhio/Task/internal/middleware"
+	"github.com/Aiszhio/Task/internal/notify"
+	"github.com/Aiszhio/Task/internal/outbox"
+	"github.com/Aiszhio/Task/internal/repository/instrumented"
+	"github.com/Aiszhio/Task/internal/repository/memory"
 	"github.com/Aiszhio/Task/internal/repository/postgres"
+	"github.com/Aiszhio/Task/internal/tracing"
 	transport "github.com/Aiszhio/Task/internal/transport/http"
 	"github.com/Aiszhio/Task/internal/usecase"
+	"github.com/Aiszhio/Task/internal/usecase/traced"
+	"github.com/Aiszhio/Task/internal/webhook"
+	"github.com/Aiszhio/Task/internal/worker"
 	"github.com/gin-gonic/gin"
 	"github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 )
 
+// readinessTimeout ограничивает проверки одной пробы готовности
+const readinessTimeout = 2 * time.Second
+
 func main() {
 
-	ctx := context.Background()
-	logger := slog.New(
-		slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo}),
+	cfg, args, err := config.Load(os.Args[1:], os.Stderr)
+	if errors.Is(err, config.ErrHelp) {
+		return
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if len(args) > 0 {
+		if args[0] != "migrate" {
+			log.Fatalf("unknown command %q; %s", args[0], migrateUsage)
+		}
+		if err := runMigrate(context.Background(), cfg, args[1:], os.Stdout); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	ctx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
+
+	// workers позволяет дождаться фоновых задач при остановке, прежде чем закрыть пул соединений
+	var workers sync.WaitGroup
+	spawn := func(run func()) {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			run()
+		}()
+	}
+
+	logger := newLogger(cfg.Log)
+
+	shutdownTracing, err := tracing.Setup(ctx, tracing.Config{
+		ServiceName: cfg.Tracing.ServiceName,
+		Exporter:    cfg.Tracing.Exporter,
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer func() {
+		if err := shutdownTracing(context.Background()); err != nil {
+			logger.Error("failed to flush traces", "err", err)
+		}
+	}()
+
+	var (
+		subRepo    postgres.SubscriptionRepository
+		keyStore   postgres.IdempotencyRepository
+		apiKeyRepo postgres.APIKeyRepository
+		hookRepo   postgres.WebhookRepository
+		outboxRepo postgres.OutboxRepository
+		remindRepo postgres.ReminderRepository
+		pool       *db.Pool
+		migrator   *db.Migrator
 	)
 
-	pool, err := db.NewPool("DATABASE_DSN", ctx)
+	switch cfg.Storage {
+	case "memory":
+		logger.Warn("using in-memory storage, data will be lost on restart")
+		// outbox в памяти пишется под той же блокировкой, что и подписки
+		memSubs := memory.NewRepository(logger)
+		subRepo = memSubs
+		outboxRepo = memory.NewOutboxRepository(memSubs, logger)
+		keyStore = memory.NewIdempotencyRepository(logger)
+		apiKeyRepo = memory.NewAPIKeyRepository(logger)
+		hookRepo = memory.NewWebhookRepository(logger)
+		remindRepo = memory.NewReminderRepository(logger)
+	case "postgres":
+		pool, err = openPool(ctx, cfg.Database)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		migrator, err = db.NewMigrator(pool)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := prepareSchema(ctx, migrator, cfg.Database.MigrateOnStart, logger); err != nil {
+			log.Fatal(err)
+		}
+
+		subRepo = postgres.NewRepository(pool, logger)
+		keyStore = postgres.NewIdempotencyRepository(pool, logger)
+		apiKeyRepo = postgres.NewAPIKeyRepository(pool, logger)
+		hookRepo = postgres.NewWebhookRepository(pool, logger)
+		outboxRepo = postgres.NewOutboxRepository(pool, logger)
+		remindRepo = postgres.NewReminderRepository(pool, logger)
+	}
+
+	appMetrics := metrics.New()
+	if pool != nil {
+		appMetrics.Register(metrics.NewPoolCollector(pool.Client))
+	}
+
+	subRepo = instrumented.NewSubscriptionRepository(subRepo, appMetrics)
+	keyStore = instrumented.NewIdempotencyRepository(keyStore, appMetrics)
+	apiKeyRepo = instrumented.NewAPIKeyRepository(apiKeyRepo, appMetrics)
+	hookRepo = instrumented.NewWebhookRepository(hookRepo, appMetrics)
+	outboxRepo = instrumented.NewOutboxRepository(outboxRepo, appMetrics)
+	remindRepo = instrumented.NewReminderRepository(remindRepo, appMetrics)
+
+	// аренда доставки переживает попытку с запасом, чтобы её не взяла другая реплика
+	webhooks := traced.NewWebhookUseCase(usecase.NewWebhookUseCase(hookRepo,
+		webhook.NewSender(cfg.Webhooks.Timeout),
+		domain.RetryPolicy{
+			MaxAttempts: cfg.Webhooks.MaxAttempts,
+			Base:        cfg.Webhooks.BackoffBase,
+			Max:         cfg.Webhooks.BackoffMax,
+		},
+		2*cfg.Webhooks.Timeout,
+	))
+
+	var sink usecase.EventPublisher
+	switch cfg.Outbox.Sink {
+	case outbox.SinkWebhook:
+		sink = webhooks
+	case outbox.SinkStdout:
+		sink = outbox.NewWriterSink(os.Stdout)
+	case outbox.SinkNATS:
+		natsSink, err := outbox.NewNATSSink(cfg.Outbox.NATSURL, cfg.Outbox.NATSSubject, cfg.Outbox.PublishTimeout)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer func() { _ = natsSink.Close() }()
+		sink = natsSink
+	}
+
+	repo := traced.NewSubscriptionUseCase(usecase.NewSubscriptionUseCase(subRepo, outboxRepo))
+	apiKeys := traced.NewAPIKeyUseCase(usecase.NewAPIKeyUseCase(apiKeyRepo))
+
+	reminderChannels, err := newReminderChannels(cfg.Reminders, webhooks, logger)
 	if err != nil {
 		log.Fatal(err)
 	}
+	reminderWindows, err := domain.ParseReminderWindows(cfg.Reminders.Windows)
+	if err != nil {
+		log.Fatal(err)
+	}
+	// аренда напоминания переживает отправку с запасом, чтобы его не взяла другая реплика
+	reminders := traced.NewReminderUseCase(usecase.NewReminderUseCase(remindRepo, subRepo,
+		reminderChannels,
+		reminderWindows,
+		domain.RetryPolicy{
+			MaxAttempts: cfg.Reminders.MaxAttempts,
+			Base:        cfg.Reminders.BackoffBase,
+			Max:         cfg.Reminders.BackoffMax,
+		},
+		2*cfg.Reminders.Timeout,
+	))
+
+	spawn(func() {
+		worker.RunPurge(ctx, repo,
+			cfg.Trash.PurgeInterval,
+			cfg.Trash.Retention,
+			logger,
+		)
+	})
 
-	pgRepo := postgres.NewRepository(pool, logger)
+	spawn(func() {
+		worker.RunKeyCleanup(ctx, keyStore, time.Hour, logger)
+	})
+	spawn(func() {
+		worker.RunStats(ctx, repo, cfg.Stats.Interval, appMetrics, logger)
+	})
 
-	repo := usecase.NewSubscriptionUseCase(pgRepo)
+	spawn(func() {
+		worker.RunOutboxRelay(ctx, outboxRepo, sink, cfg.Outbox.RelayInterval, cfg.Outbox.BatchSize,
+			cfg.Outbox.PublishTimeout,
+			domain.RetryPolicy{
+				MaxAttempts: cfg.Outbox.MaxAttempts,
+				Base:        cfg.Outbox.BackoffBase,
+				Max:         cfg.Outbox.BackoffMax,
+			},
+			logger,
+		)
+	})
+	spawn(func() {
+		worker.RunOutboxCleanup(ctx, outboxRepo, time.Hour, cfg.Outbox.Retention, logger)
+	})
+
+	if cfg.Features.Reminders {
+		spawn(func() {
+			worker.RunReminders(ctx, reminders, cfg.Reminders.Interval, logger)
+		})
+		spawn(func() {
+			worker.RunReminderCleanup(ctx, remindRepo, time.Hour, cfg.Reminders.Retention, logger)
+		})
+	}
+
+	if cfg.Features.Webhooks {
+		spawn(func() {
+			worker.RunWebhookDispatcher(ctx, webhooks, cfg.Webhooks.DispatchInterval, cfg.Webhooks.BatchSize, logger)
+		})
+		spawn(func() {
+			worker.RunEndingSoon(ctx, repo, cfg.Webhooks.EndingSoonInterval, cfg.Webhooks.EndingSoonWindow, logger)
+		})
+	}
+
+	verifier, err := auth.NewVerifier(auth.Config{
+		HS256Secret: cfg.Auth.HS256Secret,
+		JWKSFile:    cfg.Auth.JWKSFile,
+		Issuer:      cfg.Auth.Issuer,
+		Audience:    cfg.Auth.Audience,
+	})
+	switch {
+	case errors.Is(err, auth.ErrNoKeys) && cfg.Auth.Disabled:
+		logger.Warn("authentication is disabled, every request acts as a trusted internal caller")
+		verifier = nil
+	case err != nil:
+		log.Fatal(err)
+	}
+
+	var limitRepo postgres.RateLimitRepository
+	switch cfg.RateLimit.Store {
+	case "memory":
+		limitRepo = memory.NewRateLimitRepository(logger)
+	case "postgres":
+		limitRepo = postgres.NewRateLimitRepository(pool, logger)
+	}
+	limitRepo = instrumented.NewRateLimitRepository(limitRepo, appMetrics)
+
+	rateLimits, err := transport.ParseRateLimits(cfg.RateLimit.Default, cfg.RateLimit.Routes, cfg.RateLimit.PerIP)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if !cfg.Features.RateLimit {
+		rateLimits = transport.RateLimits{}
+	}
+	spawn(func() {
+		worker.RunBucketCleanup(ctx, limitRepo, 10*time.Minute, rateLimits.Longest(), logger)
+	})
 
 	handlers := transport.NewSubscriptionHandler(repo)
+	keyHandlers := transport.NewAPIKeyHandler(apiKeys)
+	hookHandlers := transport.NewWebhookHandler(webhooks)
+	reminderHandlers := transport.NewReminderHandler(reminders)
+
+	var checks []health.Check
+	if pool != nil {
+		checks = append(checks, health.Database(pool), health.Migrations(migrator))
+	}
+	probe := health.NewProbe(readinessTimeout, checks...)
+	healthHandlers := transport.NewHealthHandler(probe)
 
 	router := gin.Default()
+	if err = router.SetTrustedProxies(cfg.HTTP.Proxies()); err != nil {
+		log.Fatal(err)
+	}
 
-	router.Use(middleware.NewMiddleware(logger))
+	// пробы регистрируются до middleware, чтобы частые проверки не засоряли логи, метрики и трассы
+	router.GET("/healthz", healthHandlers.Live())
+	router.GET("/readyz", healthHandlers.Ready())
 
-	router.Group("/")
-	{
-		router.GET("/subscriptions/:id", handlers.GetByID())
-		router.POST("/subscriptions", handlers.Create())
-		router.PUT("/subscriptions/:id", handlers.Update())
-		router.DELETE("/subscriptions/:id", handlers.DeleteByID())
-		router.POST("/subscriptions/list", handlers.ListByPeriod())
+	router.Use(
+		middleware.Tracing(),
+		middleware.RequestMeta(),
+		middleware.Metrics(appMetrics),
+		middleware.NewMiddleware(logger),
+	)
+	router.NoRoute(transport.NoRoute())
+
+	if cfg.Features.Swagger {
 		router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 	}
+	if cfg.Features.Metrics {
+		router.GET("/metrics", gin.WrapH(appMetrics.Handler()))
+	}
 
-	err = router.Run(":8080")
-	log.Println("Server is running on port 8080")
+	api := router.Group("/")
+	api.Use(transport.RateLimitByIP(limitRepo, rateLimits, logger))
+	if verifier != nil {
+		api.Use(transport.Authenticate(verifier, apiKeys))
+	}
+	api.Use(transport.RateLimit(limitRepo, rateLimits, logger))
+
+	read := transport.RequireScope(auth.ScopeSubscriptionsRead)
+	write := transport.RequireScope(auth.ScopeSubscriptionsWrite)
+	reports := transport.RequireScope(auth.ScopeReportsRead)
+	idempotent := transport.Idempotency(keyStore, cfg.Idempotency.TTL, cfg.Idempotency.Lease, logger)
+	{
+		api.GET("/subscriptions", read, handlers.List())
+		api.GET("/subscriptions/trash", read, handlers.Trash())
+		api.GET("/subscriptions/export", read, handlers.Export())
+		api.GET("/subscriptions/:id", read, handlers.GetByID())
+		api.GET("/subscriptions/:id/history", read, handlers.History())
+		api.POST("/subscriptions", write, idempotent, handlers.Create())
+		api.POST("/subscriptions/import", write, handlers.Import())
+		api.PUT("/subscriptions/:id", write, handlers.Update())
+		api.PATCH("/subscriptions/:id", write, handlers.Patch())
+		api.DELETE("/subscriptions/:id", write, handlers.DeleteByID())
+		api.POST("/subscriptions/:id/restore", write, handlers.Restore())
+		api.POST("/subscriptions/list", reports, handlers.ListByPeriod())
+	}
+	{
+		api.POST("/api-keys", keyHandlers.Create())
+		api.GET("/api-keys", keyHandlers.List())
+		api.DELETE("/api-keys/:id", keyHandlers.Revoke())
+		api.POST("/api-keys/:id/rotate", keyHandlers.Rotate())
+	}
+	if cfg.Features.Webhooks {
+		api.POST("/webhooks", hookHandlers.Create())
+		api.GET("/webhooks", hookHandlers.List())
+		api.DELETE("/webhooks/:id", hookHandlers.Delete())
+		api.GET("/webhooks/:id/deliveries", hookHandlers.Deliveries())
+	}
+	if cfg.Features.Reminders {
+		api.GET("/reminders", read, reminderHandlers.List())
+		api.GET("/reminders/contact", read, reminderHandlers.Contact())
+		api.PUT("/reminders/contact", write, reminderHandlers.SetContact())
+		api.DELETE("/reminders/contact", write, reminderHandlers.DeleteContact())
+	}
+
+	registered := make(map[string]bool)
+	for _, route := range router.Routes() {
+		registered[route.Method+" "+route.Path] = true
+	}
+	for route := range rateLimits.Routes {
+		if !registered[route] {
+			log.Fatalf("rate limit configured for unknown route %q", route)
+		}
+	}
+
+	server := &http.Server{
+		Addr:              cfg.HTTP.Addr,
+		Handler:           router,
+		ReadHeaderTimeout: cfg.HTTP.ReadHeaderTimeout,
+		ReadTimeout:       cfg.HTTP.ReadTimeout,
+		WriteTimeout:      cfg.HTTP.WriteTimeout,
+		IdleTimeout:       cfg.HTTP.IdleTimeout,
+	}
+
+	serverErr := make(chan error, 1)
+	go func() {
+		logger.Info("server is listening", "addr", server.Addr)
+		serverErr <- server.ListenAndServe()
+	}()
+
+	signals, stopSignals := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stopSignals()
+
+	select {
+	case err := <-serverErr:
+		log.Fatal(err)
+	case <-signals.Done():
+	}
+	// повторный сигнал завершает процесс сразу
+	stopSignals()
+
+	// готовность пропадает сразу; SHUTDOWN_DELAY даёт балансировщику время заметить это
+	// до того, как сервер перестанет принимать соединения
+	probe.Drain()
+	logger.Info("shutting down, draining in-flight requests")
+	time.Sleep(cfg.HTTP.ShutdownDelay)
+
+	drainCtx, cancelDrain := context.WithTimeout(context.Background(), cfg.HTTP.ShutdownTimeout)
+	defer cancelDrain()
+
+	if err := server.Shutdown(drainCtx); err != nil {
+		logger.Error("in-flight requests did not finish in time, closing connections", "err", err)
+		_ = server.Close()
+	}
+
+	stopWorkers()
+	if !wait(&workers, cfg.HTTP.ShutdownTimeout) {
+		logger.Error("background workers did not stop in time")
+	}
+	if pool != nil {
+		_ = migrator.Close()
+		pool.Close()
+	}
+
+	logger.Info("server stopped")
+}
+
+// wait ждёт завершения wg не дольше timeout и сообщает, дождался ли
+func wait(wg *sync.WaitGroup, timeout time.Duration) bool {
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
+
+// newReminderChannels создаёт каналы напоминаний, перечисленные в настройках
+func newReminderChannels(cfg config.Reminders, webhooks usecase.WebhookUseCase,
+	logger *slog.Logger) ([]usecase.ReminderChannel, error) {
+	names, err := domain.ParseReminderChannels(cfg.Channels)
 	if err != nil {
-		return
+		return nil, err
+	}
+
+	channels := make([]usecase.ReminderChannel, 0, len(names))
+	for _, name := range names {
+		switch name {
+		case domain.ReminderChannelEmail:
+			smtpChannel, err := notify.NewSMTPChannel(cfg.SMTPAddr, cfg.SMTPUsername, cfg.SMTPPassword, cfg.SMTPFrom,
+				cfg.Timeout)
+			if err != nil {
+				return nil, err
+			}
+			channels = append(channels, smtpChannel)
+		case domain.ReminderChannelWebhook:
+			channels = append(channels, notify.NewWebhookChannel(webhooks))
+		case domain.ReminderChannelLog:
+			channels = append(channels, notify.NewLogChannel(logger))
+		}
+	}
+
+	return channels, nil
+}
+
+// newLogger создаёт логгер с уровнем и форматом из настроек; идентификаторы трассы добавляются к каждой записи
+func newLogger(cfg config.Log) *slog.Logger {
+	var level slog.Level
+	_ = level.UnmarshalText([]byte(cfg.Level))
+
+	options := &slog.HandlerOptions{Level: level}
+
+	var handler slog.Handler = slog.NewTextHandler(os.Stdout, options)
+	if cfg.Format == "json" {
+		handler = slog.NewJSONHandler(os.Stdout, options)
 	}
+
+	return slog.New(tracing.NewLogHandler(handler))
 }
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Aiszhio/Task/internal/config"
+	"github.com/Aiszhio/Task/internal/db"
+	"github.com/pressly/goose/v3"
+)
+
+const migrateUsage = "usage: app [flags] migrate up|down|status|redo"
+
+// runMigrate выполняет подкоманду migrate над базой из настроек и печатает результат в out
+func runMigrate(ctx context.Context, cfg *config.Config, args []string, out io.Writer) error {
+	if len(args) != 1 {
+		return errors.New(migrateUsage)
+	}
+	if cfg.Database.DSN == "" {
+		return errors.New("database.dsn is required to run migrations")
+	}
+
+	pool, err := openPool(ctx, cfg.Database)
+	if err != nil {
+		return err
+	}
+	defer pool.Close()
+
+	migrator, err := db.NewMigrator(pool)
+	if err != nil {
+		return err
+	}
+	defer migrator.Close()
+
+	var results []*goose.MigrationResult
+	switch args[0] {
+	case "up":
+		results, err = migrator.Up(ctx)
+		if err == nil && len(results) == 0 {
+			_, _ = fmt.Fprintln(out, "no migrations to apply")
+		}
+	case "down":
+		var result *goose.MigrationResult
+		result, err = migrator.Down(ctx)
+		if result != nil {
+			results = append(results, result)
+		}
+	case "redo":
+		results, err = migrator.Redo(ctx)
+	case "status":
+		return printStatus(ctx, migrator, out)
+	default:
+		return fmt.Errorf("unknown migrate command %q; %s", args[0], migrateUsage)
+	}
+
+	for _, result := range results {
+		_, _ = fmt.Fprintf(out, "%-4s %s (%s)\n", result.Direction, result.Source.Path,
+			result.Duration.Round(time.Millisecond))
+	}
+
+	return err
+}
+
+func printStatus(ctx context.Context, migrator *db.Migrator, out io.Writer) error {
+	statuses, err := migrator.Status(ctx)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "VERSION\tSTATE\tAPPLIED AT\tFILE")
+	for _, status := range statuses {
+		appliedAt := "-"
+		if !status.AppliedAt.IsZero() {
+			appliedAt = status.AppliedAt.UTC().Format(time.RFC3339)
+		}
+
+		_, _ = fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", status.Source.Version, status.State, appliedAt, status.Source.Path)
+	}
+
+	return w.Flush()
+}
+
+// prepareSchema применяет миграции при запуске, если это включено, и не даёт запуститься
+// со схемой, отстающей от встроенных миграций
+func prepareSchema(ctx context.Context, migrator *db.Migrator, migrate bool, logger *slog.Logger) error {
+	if migrate {
+		results, err := migrator.Up(ctx)
+		for _, result := range results {
+			logger.Info("applied migration", "file", result.Source.Path, "duration", result.Duration)
+		}
+		if err != nil {
+			return fmt.Errorf("apply migrations: %w", err)
+		}
+	}
+
+	pending, err := migrator.Pending(ctx)
+	if err != nil {
+		return fmt.Errorf("check migrations: %w", err)
+	}
+
+	if pending {
+		return errors.New("database schema is behind the embedded migrations: " +
+			"run `app migrate up` or enable database.migrate_on_start")
+	}
+
+	return nil
+}
+
+func openPool(ctx context.Context, cfg config.Database) (*db.Pool, error) {
+	return db.NewPool(ctx, db.Config{
+		DSN:             cfg.DSN,
+		MaxConns:        cfg.MaxConns,
+		MinConns:        cfg.MinConns,
+		MaxConnLifetime: cfg.MaxConnLifetime,
+		MaxConnIdleTime: cfg.MaxConnIdleTime,
+		ConnectTimeout:  cfg.ConnectTimeout,
+	})
+}
@@ -0,0 +1,57 @@
+// Команда webhook-receiver — локальный получатель вебхуков для проверки доставок: проверяет подпись
+// каждого запроса, печатает событие и может отвечать ошибкой, чтобы проверить повторы
+package main
+
+import (
+	"flag"
+	"io"
+	"log"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/Aiszhio/Task/internal/webhook"
+)
+
+func main() {
+	addr := flag.String("addr", ":9000", "address to listen on")
+	secret := flag.String("secret", "", "webhook secret; signatures are not checked when empty")
+	fail := flag.Int64("fail", 0, "answer 500 to this many first requests")
+	tolerance := flag.Duration("tolerance", 5*time.Minute, "maximum age of a signed request")
+	flag.Parse()
+
+	var received atomic.Int64
+
+	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(io.LimitReader(r.Body, 1<<20))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		n := received.Add(1)
+		event, delivery := r.Header.Get(webhook.HeaderEvent), r.Header.Get(webhook.HeaderDelivery)
+
+		if *secret != "" {
+			err = webhook.Verify(*secret, r.Header.Get(webhook.HeaderTimestamp), r.Header.Get(webhook.HeaderSignature),
+				body, time.Now(), *tolerance)
+			if err != nil {
+				log.Printf("#%d %s delivery=%s rejected: %v", n, event, delivery, err)
+				http.Error(w, err.Error(), http.StatusUnauthorized)
+				return
+			}
+		}
+
+		if n <= *fail {
+			log.Printf("#%d %s delivery=%s failed on purpose", n, event, delivery)
+			http.Error(w, "failing on purpose", http.StatusInternalServerError)
+			return
+		}
+
+		log.Printf("#%d %s delivery=%s %s", n, event, delivery, body)
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	log.Printf("webhook receiver is listening on %s", *addr)
+	log.Fatal(http.ListenAndServe(*addr, nil))
+}
@@ -0,0 +1,50 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"slices"
+	"strings"
+)
+
+// Права сервисных ключей
+const (
+	ScopeSubscriptionsRead  = "subscriptions:read"
+	ScopeSubscriptionsWrite = "subscriptions:write"
+	ScopeReportsRead        = "reports:read"
+)
+
+// APIKeyScopes — права, которые можно выдать сервисному ключу
+var APIKeyScopes = []string{ScopeSubscriptionsRead, ScopeSubscriptionsWrite, ScopeReportsRead}
+
+const (
+	apiKeyPrefix        = "sk_"
+	apiKeyBytes         = 32
+	apiKeyVisiblePrefix = len(apiKeyPrefix) + 8
+)
+
+// NewAPIKey генерирует ключ и возвращает его вместе с хешем и видимым началом для хранения
+func NewAPIKey() (key, hash, prefix string, err error) {
+	secret := make([]byte, apiKeyBytes)
+	if _, err = rand.Read(secret); err != nil {
+		return "", "", "", err
+	}
+
+	key = apiKeyPrefix + base64.RawURLEncoding.EncodeToString(secret)
+
+	return key, HashAPIKey(key), key[:apiKeyVisiblePrefix], nil
+}
+
+// HashAPIKey возвращает хеш, по которому ключ ищется в хранилище. У ключа достаточно энтропии,
+// поэтому медленная функция хеширования, как для паролей, не нужна.
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(strings.TrimSpace(key)))
+	return hex.EncodeToString(sum[:])
+}
+
+// ValidAPIKeyScope сообщает, можно ли выдать право scope сервисному ключу
+func ValidAPIKeyScope(scope string) bool {
+	return slices.Contains(APIKeyScopes, scope)
+}
@@ -0,0 +1,64 @@
+package auth
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNewAPIKey(t *testing.T) {
+	key, hash, prefix, err := NewAPIKey()
+	if err != nil {
+		t.Fatalf("NewAPIKey: %v", err)
+	}
+
+	if !strings.HasPrefix(key, apiKeyPrefix) || !strings.HasPrefix(key, prefix) || len(prefix) != apiKeyVisiblePrefix {
+		t.Errorf("NewAPIKey = %q with prefix %q, want an %s key starting with the prefix", key, prefix, apiKeyPrefix)
+	}
+
+	if hash != HashAPIKey(key) {
+		t.Errorf("NewAPIKey hash = %s, want HashAPIKey of the key", hash)
+	}
+
+	if strings.Contains(hash, key[len(apiKeyPrefix):]) {
+		t.Errorf("hash %s contains the secret", hash)
+	}
+
+	other, otherHash, _, err := NewAPIKey()
+	if err != nil {
+		t.Fatalf("NewAPIKey: %v", err)
+	}
+	if other == key || otherHash == hash {
+		t.Errorf("two NewAPIKey calls returned the same key")
+	}
+}
+
+func TestHashAPIKey(t *testing.T) {
+	const key = "sk_Zm9vYmFy"
+
+	if HashAPIKey(key) != HashAPIKey(" "+key+"\n") {
+		t.Errorf("HashAPIKey depends on surrounding whitespace")
+	}
+
+	if HashAPIKey(key) == HashAPIKey(key+"x") {
+		t.Errorf("HashAPIKey of different keys is the same")
+	}
+
+	if got := HashAPIKey(key); len(got) != 64 {
+		t.Errorf("HashAPIKey = %q, want a hex SHA-256", got)
+	}
+}
+
+func TestValidAPIKeyScope(t *testing.T) {
+	for _, scope := range APIKeyScopes {
+		if !ValidAPIKeyScope(scope) {
+			t.Errorf("ValidAPIKeyScope(%q) = false, want true", scope)
+		}
+	}
+
+	// admin выдаётся только пользователям через JWT
+	for _, scope := range []string{ScopeAdmin, "", "subscriptions:*"} {
+		if ValidAPIKeyScope(scope) {
+			t.Errorf("ValidAPIKeyScope(%q) = true, want false", scope)
+		}
+	}
+}
@@ -0,0 +1,189 @@
+package auth
+
+import (
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+const leeway = 30 * time.Second
+
+// ErrNoKeys — не задан ни секрет HS256, ни файл JWKS
+var ErrNoKeys = errors.New("no JWT verification keys configured")
+
+type Config struct {
+	HS256Secret string
+	JWKSFile    string
+	Issuer      string
+	Audience    string
+}
+
+// Verifier проверяет подпись и сроки JWT (HS256 общим секретом, RS256 ключами из JWKS)
+// и извлекает из него субъекта
+type Verifier struct {
+	secret []byte
+	keys   map[string]*rsa.PublicKey
+	parser *jwt.Parser
+}
+
+// claims — поля токена: user_id (или sub) — пользователь, scope — права через пробел
+type claims struct {
+	jwt.RegisteredClaims
+	UserID string `json:"user_id"`
+	Scope  string `json:"scope"`
+}
+
+func NewVerifier(cfg Config) (*Verifier, error) {
+	verifier := &Verifier{secret: []byte(cfg.HS256Secret)}
+
+	var methods []string
+	if cfg.HS256Secret != "" {
+		methods = append(methods, jwt.SigningMethodHS256.Alg())
+	}
+
+	if cfg.JWKSFile != "" {
+		keys, err := loadJWKS(cfg.JWKSFile)
+		if err != nil {
+			return nil, err
+		}
+
+		verifier.keys = keys
+		methods = append(methods, jwt.SigningMethodRS256.Alg())
+	}
+
+	if len(methods) == 0 {
+		return nil, ErrNoKeys
+	}
+
+	options := []jwt.ParserOption{
+		jwt.WithValidMethods(methods),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(leeway),
+	}
+
+	if cfg.Issuer != "" {
+		options = append(options, jwt.WithIssuer(cfg.Issuer))
+	}
+
+	if cfg.Audience != "" {
+		options = append(options, jwt.WithAudience(cfg.Audience))
+	}
+
+	verifier.parser = jwt.NewParser(options...)
+
+	return verifier, nil
+}
+
+// Verify проверяет токен и возвращает его субъекта. Токен без прав admin должен указывать пользователя.
+func (v *Verifier) Verify(raw string) (*Principal, error) {
+	var tokenClaims claims
+
+	_, err := v.parser.ParseWithClaims(raw, &tokenClaims, v.key)
+	if err != nil {
+		return nil, err
+	}
+
+	principal := &Principal{
+		Subject: tokenClaims.Subject,
+		Scopes:  strings.Fields(tokenClaims.Scope),
+	}
+
+	userID := tokenClaims.UserID
+	if userID == "" {
+		userID = tokenClaims.Subject
+	}
+
+	if parsed, err := uuid.Parse(userID); err == nil {
+		principal.UserID = parsed
+	}
+
+	if principal.UserID == uuid.Nil && !principal.Admin() {
+		return nil, errors.New("token does not identify a user")
+	}
+
+	if principal.Subject == "" {
+		principal.Subject = principal.UserID.String()
+	}
+
+	return principal, nil
+}
+
+func (v *Verifier) key(token *jwt.Token) (any, error) {
+	if token.Method.Alg() == jwt.SigningMethodHS256.Alg() {
+		return v.secret, nil
+	}
+
+	kid, _ := token.Header["kid"].(string)
+	if key, ok := v.keys[kid]; ok {
+		return key, nil
+	}
+
+	// без kid подходит единственный ключ набора
+	if kid == "" && len(v.keys) == 1 {
+		for _, key := range v.keys {
+			return key, nil
+		}
+	}
+
+	return nil, fmt.Errorf("unknown signing key %q", kid)
+}
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+// loadJWKS читает RSA-ключи подписи из файла JWKS (RFC 7517)
+func loadJWKS(path string) (map[string]*rsa.PublicKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read jwks: %w", err)
+	}
+
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err = json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("parse jwks: %w", err)
+	}
+
+	keys := make(map[string]*rsa.PublicKey, len(set.Keys))
+	for _, key := range set.Keys {
+		if key.Kty != "RSA" || (key.Use != "" && key.Use != "sig") {
+			continue
+		}
+
+		n, err := base64.RawURLEncoding.DecodeString(key.N)
+		if err != nil {
+			return nil, fmt.Errorf("jwks key %q: modulus: %w", key.Kid, err)
+		}
+
+		e, err := base64.RawURLEncoding.DecodeString(key.E)
+		if err != nil {
+			return nil, fmt.Errorf("jwks key %q: exponent: %w", key.Kid, err)
+		}
+
+		keys[key.Kid] = &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}
+	}
+
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("jwks %s has no RSA signing keys", path)
+	}
+
+	return keys, nil
+}
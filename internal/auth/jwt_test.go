@@ -0,0 +1,223 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+const testSecret = "test-secret"
+
+func sign(t *testing.T, method jwt.SigningMethod, key any, kid string, tokenClaims jwt.MapClaims) string {
+	t.Helper()
+
+	token := jwt.NewWithClaims(method, tokenClaims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+
+	raw, err := token.SignedString(key)
+	if err != nil {
+		t.Fatalf("sign token: %v", err)
+	}
+
+	return raw
+}
+
+func userClaims(userID uuid.UUID) jwt.MapClaims {
+	return jwt.MapClaims{
+		"sub":     "alice",
+		"user_id": userID.String(),
+		"exp":     time.Now().Add(time.Hour).Unix(),
+	}
+}
+
+// writeJWKS сохраняет открытые ключи в файл JWKS и возвращает его путь
+func writeJWKS(t *testing.T, keys map[string]*rsa.PublicKey) string {
+	t.Helper()
+
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	for kid, key := range keys {
+		set.Keys = append(set.Keys, jwk{
+			Kty: "RSA",
+			Kid: kid,
+			Use: "sig",
+			N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		})
+	}
+
+	data, err := json.Marshal(set)
+	if err != nil {
+		t.Fatalf("marshal jwks: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	if err = os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatalf("write jwks: %v", err)
+	}
+
+	return path
+}
+
+func newRSAKey(t *testing.T) *rsa.PrivateKey {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate rsa key: %v", err)
+	}
+
+	return key
+}
+
+func TestNewVerifierWithoutKeys(t *testing.T) {
+	if _, err := NewVerifier(Config{}); !errors.Is(err, ErrNoKeys) {
+		t.Errorf("NewVerifier without keys = %v, want ErrNoKeys", err)
+	}
+}
+
+func TestVerifyHS256(t *testing.T) {
+	verifier, err := NewVerifier(Config{HS256Secret: testSecret, Issuer: "auth", Audience: "subscriptions"})
+	if err != nil {
+		t.Fatalf("NewVerifier: %v", err)
+	}
+
+	userID := uuid.New()
+	valid := func() jwt.MapClaims {
+		tokenClaims := userClaims(userID)
+		tokenClaims["iss"] = "auth"
+		tokenClaims["aud"] = "subscriptions"
+		tokenClaims["scope"] = "reports:read"
+		return tokenClaims
+	}
+
+	principal, err := verifier.Verify(sign(t, jwt.SigningMethodHS256, []byte(testSecret), "", valid()))
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if principal.UserID != userID || principal.Subject != "alice" || !principal.HasScope(ScopeReportsRead) ||
+		principal.Service {
+		t.Errorf("Verify = %+v, want user %s with reports:read", principal, userID)
+	}
+
+	tests := []struct {
+		name   string
+		key    []byte
+		modify func(jwt.MapClaims)
+	}{
+		{"wrong secret", []byte("other"), func(jwt.MapClaims) {}},
+		{"expired", []byte(testSecret), func(c jwt.MapClaims) { c["exp"] = time.Now().Add(-time.Hour).Unix() }},
+		{"no expiry", []byte(testSecret), func(c jwt.MapClaims) { delete(c, "exp") }},
+		{"wrong issuer", []byte(testSecret), func(c jwt.MapClaims) { c["iss"] = "other" }},
+		{"wrong audience", []byte(testSecret), func(c jwt.MapClaims) { c["aud"] = "other" }},
+		{"no user", []byte(testSecret), func(c jwt.MapClaims) { delete(c, "user_id"); c["sub"] = "alice" }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokenClaims := valid()
+			tt.modify(tokenClaims)
+
+			if _, err := verifier.Verify(sign(t, jwt.SigningMethodHS256, tt.key, "", tokenClaims)); err == nil {
+				t.Errorf("Verify accepted the token")
+			}
+		})
+	}
+}
+
+func TestVerifyUserFromSubject(t *testing.T) {
+	verifier, err := NewVerifier(Config{HS256Secret: testSecret})
+	if err != nil {
+		t.Fatalf("NewVerifier: %v", err)
+	}
+
+	userID := uuid.New()
+	tokenClaims := jwt.MapClaims{"sub": userID.String(), "exp": time.Now().Add(time.Hour).Unix()}
+
+	principal, err := verifier.Verify(sign(t, jwt.SigningMethodHS256, []byte(testSecret), "", tokenClaims))
+	if err != nil || principal.UserID != userID {
+		t.Fatalf("Verify = %+v, %v, want user %s from sub", principal, err, userID)
+	}
+
+	// администратору пользователь не нужен
+	admin := jwt.MapClaims{"sub": "ops", "scope": ScopeAdmin, "exp": time.Now().Add(time.Hour).Unix()}
+	principal, err = verifier.Verify(sign(t, jwt.SigningMethodHS256, []byte(testSecret), "", admin))
+	if err != nil || !principal.Admin() || principal.UserID != uuid.Nil {
+		t.Errorf("Verify of an admin token = %+v, %v, want an admin without a user", principal, err)
+	}
+}
+
+func TestVerifyRS256(t *testing.T) {
+	current, previous, unknown := newRSAKey(t), newRSAKey(t), newRSAKey(t)
+
+	verifier, err := NewVerifier(Config{JWKSFile: writeJWKS(t, map[string]*rsa.PublicKey{
+		"current":  &current.PublicKey,
+		"previous": &previous.PublicKey,
+	})})
+	if err != nil {
+		t.Fatalf("NewVerifier: %v", err)
+	}
+
+	userID := uuid.New()
+	for _, tt := range []struct {
+		kid string
+		key *rsa.PrivateKey
+	}{{"current", current}, {"previous", previous}} {
+		principal, err := verifier.Verify(sign(t, jwt.SigningMethodRS256, tt.key, tt.kid, userClaims(userID)))
+		if err != nil || principal.UserID != userID {
+			t.Errorf("Verify with key %s = %+v, %v, want user %s", tt.kid, principal, err, userID)
+		}
+	}
+
+	rejected := map[string]string{
+		"unknown kid":       sign(t, jwt.SigningMethodRS256, unknown, "unknown", userClaims(userID)),
+		"kid of other key":  sign(t, jwt.SigningMethodRS256, unknown, "current", userClaims(userID)),
+		"no kid of two":     sign(t, jwt.SigningMethodRS256, current, "", userClaims(userID)),
+		"hs256 not enabled": sign(t, jwt.SigningMethodHS256, []byte(testSecret), "", userClaims(userID)),
+	}
+
+	for name, raw := range rejected {
+		if _, err := verifier.Verify(raw); err == nil {
+			t.Errorf("Verify accepted a token with %s", name)
+		}
+	}
+}
+
+func TestVerifyRS256SingleKeyWithoutKid(t *testing.T) {
+	key := newRSAKey(t)
+
+	verifier, err := NewVerifier(Config{JWKSFile: writeJWKS(t, map[string]*rsa.PublicKey{"only": &key.PublicKey})})
+	if err != nil {
+		t.Fatalf("NewVerifier: %v", err)
+	}
+
+	userID := uuid.New()
+	if _, err = verifier.Verify(sign(t, jwt.SigningMethodRS256, key, "", userClaims(userID))); err != nil {
+		t.Errorf("Verify without kid: %v", err)
+	}
+}
+
+func TestVerifyRejectsUnsignedTokens(t *testing.T) {
+	verifier, err := NewVerifier(Config{HS256Secret: testSecret})
+	if err != nil {
+		t.Fatalf("NewVerifier: %v", err)
+	}
+
+	raw := sign(t, jwt.SigningMethodNone, jwt.UnsafeAllowNoneSignatureType, "", userClaims(uuid.New()))
+	if _, err = verifier.Verify(raw); err == nil {
+		t.Errorf("Verify accepted an unsigned token")
+	}
+}
@@ -0,0 +1,58 @@
+// Package auth определяет, от чьего имени выполняется запрос: проверяет токены клиентов
+// и передаёт через context субъекта, которому принадлежит токен
+package auth
+
+import (
+	"context"
+	"slices"
+
+	"github.com/google/uuid"
+)
+
+// ScopeAdmin позволяет действовать от имени любого пользователя
+const ScopeAdmin = "admin"
+
+// Principal — аутентифицированный субъект запроса: пользователь с JWT или сервис с API-ключом.
+// Сервис не привязан к пользователю и работает с подписками всех пользователей в пределах своих прав.
+type Principal struct {
+	Subject string
+	UserID  uuid.UUID
+	Scopes  []string
+	Service bool
+}
+
+type principalKey struct{}
+
+func WithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// FromContext возвращает субъекта запроса; его нет у внутренних вызовов, например фоновых задач
+func FromContext(ctx context.Context) (*Principal, bool) {
+	principal, ok := ctx.Value(principalKey{}).(*Principal)
+	return principal, ok && principal != nil
+}
+
+func (p *Principal) HasScope(scope string) bool {
+	return slices.Contains(p.Scopes, scope)
+}
+
+func (p *Principal) Admin() bool {
+	return p.HasScope(ScopeAdmin)
+}
+
+// AllUsers сообщает, что субъект не ограничен данными одного пользователя
+func (p *Principal) AllUsers() bool {
+	return p.Admin() || p.Service
+}
+
+// CanActFor сообщает, может ли субъект работать с данными пользователя userID
+func (p *Principal) CanActFor(userID uuid.UUID) bool {
+	return p.AllUsers() || (p.UserID != uuid.Nil && p.UserID == userID)
+}
+
+// Allows сообщает, разрешено ли субъекту действие с правом scope. Права проверяются только
+// у сервисов: пользователю доступно всё в пределах его собственных подписок.
+func (p *Principal) Allows(scope string) bool {
+	return !p.Service || p.HasScope(scope)
+}
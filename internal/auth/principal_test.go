@@ -0,0 +1,41 @@
+package auth
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestPrincipalScopes(t *testing.T) {
+	owner, other := uuid.New(), uuid.New()
+
+	user := &Principal{Subject: owner.String(), UserID: owner}
+	admin := &Principal{Subject: "ops", Scopes: []string{ScopeAdmin}}
+	service := &Principal{Subject: "apikey:billing", Scopes: []string{ScopeSubscriptionsRead}, Service: true}
+
+	tests := []struct {
+		name      string
+		principal *Principal
+		scope     string
+		allows    bool
+		actsFor   uuid.UUID
+		canAct    bool
+	}{
+		{"user without scopes", user, ScopeSubscriptionsWrite, true, owner, true},
+		{"user and another user", user, ScopeSubscriptionsRead, true, other, false},
+		{"admin", admin, ScopeReportsRead, true, other, true},
+		{"service with the scope", service, ScopeSubscriptionsRead, true, other, true},
+		{"service without the scope", service, ScopeSubscriptionsWrite, false, other, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.principal.Allows(tt.scope); got != tt.allows {
+				t.Errorf("Allows(%q) = %v, want %v", tt.scope, got, tt.allows)
+			}
+			if got := tt.principal.CanActFor(tt.actsFor); got != tt.canAct {
+				t.Errorf("CanActFor = %v, want %v", got, tt.canAct)
+			}
+		})
+	}
+}
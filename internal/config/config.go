@@ -0,0 +1,366 @@
+// Package config собирает настройки сервиса из значений по умолчанию, файла YAML или TOML,
+// переменных окружения и флагов командной строки — каждый следующий источник важнее предыдущего
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/mail"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Aiszhio/Task/internal/domain"
+)
+
+// Config — все настройки сервиса. Тег key задаёт ключ в файле и имя флага (http.addr → -http.addr),
+// env — переменную окружения; для полей с тегом secret значение можно передать файлом
+// через переменную <env>_FILE, а allowempty разрешает пустой переменной окружения сбросить значение
+type Config struct {
+	HTTP        HTTP        `key:"http"`
+	Log         Log         `key:"log"`
+	Storage     string      `key:"storage" env:"STORAGE"` // postgres или memory
+	Database    Database    `key:"database"`
+	Auth        Auth        `key:"auth"`
+	RateLimit   RateLimit   `key:"rate_limit"`
+	Trash       Trash       `key:"trash"`
+	Idempotency Idempotency `key:"idempotency"`
+	Stats       Stats       `key:"stats"`
+	Tracing     Tracing     `key:"tracing"`
+	Webhooks    Webhooks    `key:"webhooks"`
+	Outbox      Outbox      `key:"outbox"`
+	Reminders   Reminders   `key:"reminders"`
+	Features    Features    `key:"features"`
+}
+
+// HTTP — сервер и его остановка; нулевые ReadTimeout, WriteTimeout и IdleTimeout снимают ограничение
+type HTTP struct {
+	Addr              string        `key:"addr"                env:"HTTP_ADDR"`
+	ReadHeaderTimeout time.Duration `key:"read_header_timeout" env:"HTTP_READ_HEADER_TIMEOUT"`
+	ReadTimeout       time.Duration `key:"read_timeout"        env:"HTTP_READ_TIMEOUT"`
+	WriteTimeout      time.Duration `key:"write_timeout"       env:"HTTP_WRITE_TIMEOUT"`
+	IdleTimeout       time.Duration `key:"idle_timeout"        env:"HTTP_IDLE_TIMEOUT"`
+	ShutdownDelay     time.Duration `key:"shutdown_delay"      env:"SHUTDOWN_DELAY"`
+	ShutdownTimeout   time.Duration `key:"shutdown_timeout"    env:"SHUTDOWN_TIMEOUT"`
+	// TrustedProxies — адреса и подсети прокси через запятую, которым доверяется X-Forwarded-For;
+	// без них IP клиента — адрес соединения
+	TrustedProxies string `key:"trusted_proxies" env:"HTTP_TRUSTED_PROXIES" allowempty:"true"`
+}
+
+// Proxies возвращает доверенные прокси списком
+func (h HTTP) Proxies() []string {
+	var proxies []string
+	for _, proxy := range strings.Split(h.TrustedProxies, ",") {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			proxies = append(proxies, proxy)
+		}
+	}
+
+	return proxies
+}
+
+type Log struct {
+	Level  string `key:"level"  env:"LOG_LEVEL"`  // debug, info, warn или error
+	Format string `key:"format" env:"LOG_FORMAT"` // text или json
+}
+
+// Database — подключение к PostgreSQL, размер пула и применение миграций при запуске
+type Database struct {
+	DSN             string        `key:"dsn"                env:"DATABASE_DSN"          secret:"true"`
+	MaxConns        int32         `key:"max_conns"          env:"DB_MAX_CONNS"`
+	MinConns        int32         `key:"min_conns"          env:"DB_MIN_CONNS"`
+	MaxConnLifetime time.Duration `key:"max_conn_lifetime"  env:"DB_MAX_CONN_LIFETIME"`
+	MaxConnIdleTime time.Duration `key:"max_conn_idle_time" env:"DB_MAX_CONN_IDLE_TIME"`
+	ConnectTimeout  time.Duration `key:"connect_timeout"    env:"DB_CONNECT_TIMEOUT"`
+	MigrateOnStart  bool          `key:"migrate_on_start"   env:"DB_MIGRATE_ON_START"`
+}
+
+// Auth — проверка JWT; Disabled разрешает работать без аутентификации, если ключи не заданы
+type Auth struct {
+	Disabled    bool   `key:"disabled"     env:"AUTH_DISABLED"`
+	HS256Secret string `key:"hs256_secret" env:"JWT_HS256_SECRET" secret:"true"`
+	JWKSFile    string `key:"jwks_file"    env:"JWT_JWKS_FILE"`
+	Issuer      string `key:"issuer"       env:"JWT_ISSUER"`
+	Audience    string `key:"audience"     env:"JWT_AUDIENCE"`
+}
+
+// RateLimit — хранилище вёдер и лимиты в формате transport.ParseRateLimits; пустой лимит снимает ограничение
+type RateLimit struct {
+	Store   string `key:"store"   env:"RATE_LIMIT_STORE"`
+	Default string `key:"default" env:"RATE_LIMIT_DEFAULT" allowempty:"true"`
+	Routes  string `key:"routes"  env:"RATE_LIMITS"        allowempty:"true"`
+	PerIP   string `key:"per_ip"  env:"RATE_LIMIT_PER_IP"  allowempty:"true"`
+}
+
+type Trash struct {
+	Retention     time.Duration `key:"retention"      env:"TRASH_RETENTION"`
+	PurgeInterval time.Duration `key:"purge_interval" env:"TRASH_PURGE_INTERVAL"`
+}
+
+// Idempotency — срок хранения ключей и срок, за который первый запрос с ключом должен получить ответ
+type Idempotency struct {
+	TTL   time.Duration `key:"ttl"   env:"IDEMPOTENCY_TTL"`
+	Lease time.Duration `key:"lease" env:"IDEMPOTENCY_LEASE"`
+}
+
+type Stats struct {
+	Interval time.Duration `key:"interval" env:"STATS_INTERVAL"`
+}
+
+type Tracing struct {
+	Exporter    string `key:"exporter"     env:"OTEL_TRACES_EXPORTER"` // otlp или none
+	ServiceName string `key:"service_name" env:"OTEL_SERVICE_NAME"`
+}
+
+// Webhooks — отправка событий подписок: пачки, таймаут запроса, повторы с задержкой, растущей
+// от BackoffBase до BackoffMax, и поиск подписок, заканчивающихся в ближайшие EndingSoonWindow
+type Webhooks struct {
+	DispatchInterval   time.Duration `key:"dispatch_interval"    env:"WEBHOOK_DISPATCH_INTERVAL"`
+	BatchSize          int           `key:"batch_size"           env:"WEBHOOK_BATCH_SIZE"`
+	Timeout            time.Duration `key:"timeout"              env:"WEBHOOK_TIMEOUT"`
+	MaxAttempts        int           `key:"max_attempts"         env:"WEBHOOK_MAX_ATTEMPTS"`
+	BackoffBase        time.Duration `key:"backoff_base"         env:"WEBHOOK_BACKOFF_BASE"`
+	BackoffMax         time.Duration `key:"backoff_max"          env:"WEBHOOK_BACKOFF_MAX"`
+	EndingSoonWindow   time.Duration `key:"ending_soon_window"   env:"WEBHOOK_ENDING_SOON_WINDOW"`
+	EndingSoonInterval time.Duration `key:"ending_soon_interval" env:"WEBHOOK_ENDING_SOON_INTERVAL"`
+}
+
+// Outbox — публикация событий подписок из таблицы outbox: приёмник, пачки, повторы, время
+// на публикацию одного сообщения и срок хранения опубликованных сообщений
+type Outbox struct {
+	Sink           string        `key:"sink"            env:"OUTBOX_SINK"` // webhook, stdout или nats
+	RelayInterval  time.Duration `key:"relay_interval"  env:"OUTBOX_RELAY_INTERVAL"`
+	BatchSize      int           `key:"batch_size"      env:"OUTBOX_BATCH_SIZE"`
+	MaxAttempts    int           `key:"max_attempts"    env:"OUTBOX_MAX_ATTEMPTS"`
+	BackoffBase    time.Duration `key:"backoff_base"    env:"OUTBOX_BACKOFF_BASE"`
+	BackoffMax     time.Duration `key:"backoff_max"     env:"OUTBOX_BACKOFF_MAX"`
+	Retention      time.Duration `key:"retention"       env:"OUTBOX_RETENTION"`
+	NATSURL        string        `key:"nats_url"        env:"OUTBOX_NATS_URL"`
+	NATSSubject    string        `key:"nats_subject"    env:"OUTBOX_NATS_SUBJECT"`
+	PublishTimeout time.Duration `key:"publish_timeout" env:"OUTBOX_PUBLISH_TIMEOUT"`
+}
+
+// Reminders — напоминания о продлении и окончании подписок: окна и каналы через запятую, проверка
+// раз в Interval, повторы неудачных отправок, срок хранения журнала и почтовый сервер для канала email
+type Reminders struct {
+	Windows      string        `key:"windows"       env:"REMINDER_WINDOWS"`  // например 7d,1d
+	Channels     string        `key:"channels"      env:"REMINDER_CHANNELS"` // email, webhook, log
+	Interval     time.Duration `key:"interval"      env:"REMINDER_INTERVAL"`
+	Timeout      time.Duration `key:"timeout"       env:"REMINDER_TIMEOUT"`
+	MaxAttempts  int           `key:"max_attempts"  env:"REMINDER_MAX_ATTEMPTS"`
+	BackoffBase  time.Duration `key:"backoff_base"  env:"REMINDER_BACKOFF_BASE"`
+	BackoffMax   time.Duration `key:"backoff_max"   env:"REMINDER_BACKOFF_MAX"`
+	Retention    time.Duration `key:"retention"     env:"REMINDER_RETENTION"`
+	SMTPAddr     string        `key:"smtp_addr"     env:"SMTP_ADDR"`
+	SMTPUsername string        `key:"smtp_username" env:"SMTP_USERNAME"`
+	SMTPPassword string        `key:"smtp_password" env:"SMTP_PASSWORD"     secret:"true"`
+	SMTPFrom     string        `key:"smtp_from"     env:"SMTP_FROM"`
+}
+
+// Features — отключаемые возможности сервиса
+type Features struct {
+	Swagger   bool `key:"swagger"    env:"FEATURE_SWAGGER"`
+	Metrics   bool `key:"metrics"    env:"FEATURE_METRICS"`
+	RateLimit bool `key:"rate_limit" env:"FEATURE_RATE_LIMIT"`
+	Webhooks  bool `key:"webhooks"   env:"FEATURE_WEBHOOKS"`
+	Reminders bool `key:"reminders"  env:"FEATURE_REMINDERS"`
+}
+
+// Default возвращает значения по умолчанию
+func Default() Config {
+	return Config{
+		HTTP: HTTP{
+			Addr:              ":8080",
+			ReadHeaderTimeout: 10 * time.Second,
+			ReadTimeout:       time.Minute,
+			IdleTimeout:       2 * time.Minute,
+			ShutdownTimeout:   30 * time.Second,
+		},
+		Log: Log{
+			Level:  "info",
+			Format: "text",
+		},
+		Storage: "postgres",
+		Database: Database{
+			MaxConns:        10,
+			MaxConnLifetime: time.Hour,
+			MaxConnIdleTime: 30 * time.Minute,
+			ConnectTimeout:  5 * time.Second,
+		},
+		RateLimit: RateLimit{
+			Store:   "memory",
+			Default: "300/m",
+			Routes: "POST /subscriptions/list=60/m,POST /subscriptions/import=10/m," +
+				"GET /subscriptions/export=10/m",
+			PerIP: "600/m",
+		},
+		Trash: Trash{
+			Retention:     30 * 24 * time.Hour,
+			PurgeInterval: time.Hour,
+		},
+		Idempotency: Idempotency{TTL: 24 * time.Hour, Lease: time.Minute},
+		Stats:       Stats{Interval: time.Minute},
+		Tracing: Tracing{
+			Exporter:    "none",
+			ServiceName: "subscriptions-api",
+		},
+		Webhooks: Webhooks{
+			DispatchInterval:   5 * time.Second,
+			BatchSize:          20,
+			Timeout:            10 * time.Second,
+			MaxAttempts:        8,
+			BackoffBase:        30 * time.Second,
+			BackoffMax:         6 * time.Hour,
+			EndingSoonWindow:   7 * 24 * time.Hour,
+			EndingSoonInterval: time.Hour,
+		},
+		Outbox: Outbox{
+			Sink:           "webhook",
+			RelayInterval:  time.Second,
+			BatchSize:      100,
+			MaxAttempts:    20,
+			BackoffBase:    time.Second,
+			BackoffMax:     5 * time.Minute,
+			Retention:      30 * 24 * time.Hour,
+			NATSURL:        "nats://localhost:4222",
+			NATSSubject:    "subscriptions",
+			PublishTimeout: 5 * time.Second,
+		},
+		Reminders: Reminders{
+			Windows:     "7d,1d",
+			Channels:    "log",
+			Interval:    10 * time.Minute,
+			Timeout:     30 * time.Second,
+			MaxAttempts: 5,
+			BackoffBase: 10 * time.Minute,
+			BackoffMax:  6 * time.Hour,
+			Retention:   30 * 24 * time.Hour,
+			SMTPAddr:    "localhost:25",
+			SMTPFrom:    "no-reply@localhost",
+		},
+		Features: Features{
+			Swagger:   true,
+			Metrics:   true,
+			RateLimit: true,
+			Webhooks:  true,
+			Reminders: true,
+		},
+	}
+}
+
+// Validate проверяет настройки и перечисляет все найденные ошибки разом
+func (c *Config) Validate() error {
+	var errs []error
+	check := func(ok bool, key, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf("%s: %s", key, fmt.Sprintf(format, args...)))
+		}
+	}
+	oneOf := func(key, value string, allowed ...string) {
+		check(slices.Contains(allowed, value), key, "got %q, expected one of %s",
+			value, strings.Join(allowed, ", "))
+	}
+
+	check(c.HTTP.Addr != "", "http.addr", "must not be empty")
+	check(c.HTTP.ReadHeaderTimeout > 0, "http.read_header_timeout", "must be positive")
+	check(c.HTTP.ReadTimeout >= 0, "http.read_timeout", "must not be negative")
+	check(c.HTTP.WriteTimeout >= 0, "http.write_timeout", "must not be negative")
+	check(c.HTTP.IdleTimeout >= 0, "http.idle_timeout", "must not be negative")
+	check(c.HTTP.ShutdownDelay >= 0, "http.shutdown_delay", "must not be negative")
+	check(c.HTTP.ShutdownTimeout > 0, "http.shutdown_timeout", "must be positive")
+	for _, proxy := range c.HTTP.Proxies() {
+		_, _, cidrErr := net.ParseCIDR(proxy)
+		check(cidrErr == nil || net.ParseIP(proxy) != nil, "http.trusted_proxies",
+			"%q is neither an IP address nor a CIDR", proxy)
+	}
+
+	oneOf("log.level", c.Log.Level, "debug", "info", "warn", "error")
+	oneOf("log.format", c.Log.Format, "text", "json")
+
+	oneOf("storage", c.Storage, "postgres", "memory")
+	if c.Storage == "postgres" {
+		check(c.Database.DSN != "", "database.dsn", "required with postgres storage")
+	}
+	check(c.Database.MaxConns > 0, "database.max_conns", "must be positive")
+	check(c.Database.MinConns >= 0 && c.Database.MinConns <= c.Database.MaxConns,
+		"database.min_conns", "must be between 0 and max_conns")
+	check(c.Database.MaxConnLifetime > 0, "database.max_conn_lifetime", "must be positive")
+	check(c.Database.MaxConnIdleTime > 0, "database.max_conn_idle_time", "must be positive")
+	check(c.Database.ConnectTimeout > 0, "database.connect_timeout", "must be positive")
+
+	oneOf("rate_limit.store", c.RateLimit.Store, "memory", "postgres")
+	if c.RateLimit.Store == "postgres" {
+		check(c.Storage == "postgres", "rate_limit.store", "postgres requires postgres storage")
+	}
+	if strings.TrimSpace(c.RateLimit.Default) != "" {
+		_, err := domain.ParseRateLimit(c.RateLimit.Default)
+		check(err == nil, "rate_limit.default", "%v", err)
+	}
+	if strings.TrimSpace(c.RateLimit.PerIP) != "" {
+		_, err := domain.ParseRateLimit(c.RateLimit.PerIP)
+		check(err == nil, "rate_limit.per_ip", "%v", err)
+	}
+
+	check(c.Trash.Retention > 0, "trash.retention", "must be positive")
+	check(c.Trash.PurgeInterval > 0, "trash.purge_interval", "must be positive")
+	check(c.Idempotency.TTL > 0, "idempotency.ttl", "must be positive")
+	check(c.Idempotency.Lease > 0, "idempotency.lease", "must be positive")
+	check(c.Idempotency.Lease <= c.Idempotency.TTL, "idempotency.lease", "must not exceed idempotency.ttl")
+	check(c.HTTP.WriteTimeout == 0 || c.Idempotency.Lease > c.HTTP.WriteTimeout, "idempotency.lease",
+		"must exceed http.write_timeout")
+	check(c.Stats.Interval > 0, "stats.interval", "must be positive")
+
+	oneOf("tracing.exporter", c.Tracing.Exporter, "otlp", "none")
+	check(c.Tracing.ServiceName != "", "tracing.service_name", "must not be empty")
+
+	check(c.Webhooks.DispatchInterval > 0, "webhooks.dispatch_interval", "must be positive")
+	check(c.Webhooks.BatchSize > 0, "webhooks.batch_size", "must be positive")
+	check(c.Webhooks.Timeout > 0, "webhooks.timeout", "must be positive")
+	check(c.Webhooks.MaxAttempts > 0, "webhooks.max_attempts", "must be positive")
+	check(c.Webhooks.BackoffBase > 0, "webhooks.backoff_base", "must be positive")
+	check(c.Webhooks.BackoffMax >= c.Webhooks.BackoffBase, "webhooks.backoff_max", "must not be less than backoff_base")
+	check(c.Webhooks.EndingSoonWindow > 0, "webhooks.ending_soon_window", "must be positive")
+	check(c.Webhooks.EndingSoonInterval > 0, "webhooks.ending_soon_interval", "must be positive")
+
+	oneOf("outbox.sink", c.Outbox.Sink, "webhook", "stdout", "nats")
+	switch c.Outbox.Sink {
+	case "webhook":
+		check(c.Features.Webhooks, "outbox.sink", "webhook requires features.webhooks")
+	case "nats":
+		check(c.Outbox.NATSURL != "", "outbox.nats_url", "required with nats sink")
+		check(c.Outbox.NATSSubject != "", "outbox.nats_subject", "required with nats sink")
+	}
+	check(c.Outbox.RelayInterval > 0, "outbox.relay_interval", "must be positive")
+	check(c.Outbox.BatchSize > 0, "outbox.batch_size", "must be positive")
+	check(c.Outbox.MaxAttempts > 0, "outbox.max_attempts", "must be positive")
+	check(c.Outbox.BackoffBase > 0, "outbox.backoff_base", "must be positive")
+	check(c.Outbox.BackoffMax >= c.Outbox.BackoffBase, "outbox.backoff_max", "must not be less than backoff_base")
+	// опубликованная запись о событии ending_soon не даёт поставить его повторно, пока подписка в окне
+	check(c.Outbox.Retention >= c.Webhooks.EndingSoonWindow, "outbox.retention",
+		"must not be less than webhooks.ending_soon_window")
+	check(c.Outbox.PublishTimeout > 0, "outbox.publish_timeout", "must be positive")
+
+	_, err := domain.ParseReminderWindows(c.Reminders.Windows)
+	check(err == nil, "reminders.windows", "%v", err)
+	channels, err := domain.ParseReminderChannels(c.Reminders.Channels)
+	check(err == nil, "reminders.channels", "%v", err)
+	if slices.Contains(channels, domain.ReminderChannelWebhook) {
+		check(c.Features.Webhooks, "reminders.channels", "webhook requires features.webhooks")
+	}
+	if slices.Contains(channels, domain.ReminderChannelEmail) {
+		_, _, err = net.SplitHostPort(c.Reminders.SMTPAddr)
+		check(err == nil, "reminders.smtp_addr", "expected host:port, got %q", c.Reminders.SMTPAddr)
+		_, err = mail.ParseAddress(c.Reminders.SMTPFrom)
+		check(err == nil, "reminders.smtp_from", "%v", err)
+	}
+	check(c.Reminders.Interval > 0, "reminders.interval", "must be positive")
+	check(c.Reminders.Timeout > 0, "reminders.timeout", "must be positive")
+	check(c.Reminders.MaxAttempts > 0, "reminders.max_attempts", "must be positive")
+	check(c.Reminders.BackoffBase > 0, "reminders.backoff_base", "must be positive")
+	check(c.Reminders.BackoffMax >= c.Reminders.BackoffBase, "reminders.backoff_max",
+		"must not be less than backoff_base")
+	check(c.Reminders.Retention > 0, "reminders.retention", "must be positive")
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,263 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// ErrHelp — запрошена справка по флагам (-h); справка уже выведена
+var ErrHelp = flag.ErrHelp
+
+// setting — одна настройка: поле Config и его имена в разных источниках
+type setting struct {
+	key        string
+	env        string
+	secret     bool
+	allowEmpty bool
+	value      reflect.Value
+}
+
+// Load собирает настройки: значения по умолчанию, файл из -config или CONFIG_FILE (.yaml, .yml, .toml),
+// переменные окружения, флаги из args — и проверяет результат. Аргументы после флагов
+// (например, подкоманда migrate) возвращаются как есть
+func Load(args []string, output io.Writer) (*Config, []string, error) {
+	cfg := Default()
+	settings := collect(reflect.ValueOf(&cfg).Elem(), "")
+
+	flags := flag.NewFlagSet("app", flag.ContinueOnError)
+	flags.SetOutput(output)
+
+	configFile := flags.String("config", os.Getenv("CONFIG_FILE"), "YAML or TOML config file (env CONFIG_FILE)")
+	for _, s := range settings {
+		flags.Var(&flagValue{setting: s}, s.key, fmt.Sprintf("env %s, default %q", s.env, s.String()))
+	}
+
+	if err := flags.Parse(args); err != nil {
+		return nil, nil, err
+	}
+
+	if *configFile != "" {
+		if err := loadFile(*configFile, settings); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if err := loadEnv(settings); err != nil {
+		return nil, nil, err
+	}
+
+	var errs []error
+	flags.Visit(func(f *flag.Flag) {
+		value, ok := f.Value.(*flagValue)
+		if !ok {
+			return
+		}
+
+		if err := value.setting.set(value.raw); err != nil {
+			errs = append(errs, fmt.Errorf("flag -%s: %w", f.Name, err))
+		}
+	})
+	if err := errors.Join(errs...); err != nil {
+		return nil, nil, err
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, nil, fmt.Errorf("invalid configuration:\n%w", err)
+	}
+
+	return &cfg, flags.Args(), nil
+}
+
+// flagValue запоминает значение флага, чтобы применить его после файла и окружения
+type flagValue struct {
+	setting setting
+	raw     string
+}
+
+func (f *flagValue) String() string {
+	return f.raw
+}
+
+func (f *flagValue) Set(raw string) error {
+	f.raw = raw
+	return nil
+}
+
+func (f *flagValue) IsBoolFlag() bool {
+	return f.setting.value.IsValid() && f.setting.value.Kind() == reflect.Bool
+}
+
+// collect обходит структуру настроек и возвращает её листовые поля
+func collect(v reflect.Value, prefix string) []setting {
+	var settings []setting
+
+	for i := range v.NumField() {
+		field := v.Type().Field(i)
+
+		key := field.Tag.Get("key")
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+
+		if field.Type.Kind() == reflect.Struct {
+			settings = append(settings, collect(v.Field(i), key)...)
+			continue
+		}
+
+		settings = append(settings, setting{
+			key:        key,
+			env:        field.Tag.Get("env"),
+			secret:     field.Tag.Get("secret") == "true",
+			allowEmpty: field.Tag.Get("allowempty") == "true",
+			value:      v.Field(i),
+		})
+	}
+
+	return settings
+}
+
+func loadFile(path string, settings []setting) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("config file: %w", err)
+	}
+
+	var tree map[string]any
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &tree)
+	case ".toml":
+		err = toml.Unmarshal(data, &tree)
+	default:
+		return fmt.Errorf("config file %s: unsupported format, expected .yaml, .yml or .toml", path)
+	}
+	if err != nil {
+		return fmt.Errorf("config file %s: %w", path, err)
+	}
+
+	values := make(map[string]any)
+	flatten("", tree, values)
+
+	byKey := make(map[string]setting, len(settings))
+	for _, s := range settings {
+		byKey[s.key] = s
+	}
+
+	var errs []error
+	for key, value := range values {
+		s, ok := byKey[key]
+		if !ok {
+			errs = append(errs, fmt.Errorf("config file %s: unknown key %q", path, key))
+			continue
+		}
+
+		if err := s.set(fmt.Sprint(value)); err != nil {
+			errs = append(errs, fmt.Errorf("config file %s: %s: %w", path, key, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// flatten превращает вложенные секции файла в ключи через точку: http: {addr: ...} → http.addr
+func flatten(prefix string, tree map[string]any, out map[string]any) {
+	for key, value := range tree {
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+
+		if section, ok := value.(map[string]any); ok {
+			flatten(key, section, out)
+			continue
+		}
+
+		if value != nil {
+			out[key] = value
+		}
+	}
+}
+
+// loadEnv применяет переменные окружения; пустые переменные не учитываются, кроме полей с allowempty
+func loadEnv(settings []setting) error {
+	var errs []error
+
+	for _, s := range settings {
+		value, ok := os.LookupEnv(s.env)
+
+		if s.secret {
+			if path := os.Getenv(s.env + "_FILE"); path != "" {
+				if value != "" {
+					errs = append(errs, fmt.Errorf("%s and %s_FILE are both set", s.env, s.env))
+					continue
+				}
+
+				secret, err := os.ReadFile(path)
+				if err != nil {
+					errs = append(errs, fmt.Errorf("%s_FILE: %w", s.env, err))
+					continue
+				}
+
+				value, ok = strings.TrimRight(string(secret), "\r\n"), true
+			}
+		}
+
+		if !ok || (value == "" && !s.allowEmpty) {
+			continue
+		}
+
+		if err := s.set(value); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", s.env, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (s setting) set(raw string) error {
+	switch s.value.Interface().(type) {
+	case time.Duration:
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return fmt.Errorf("invalid duration %q, expected a value such as 30s or 720h", raw)
+		}
+		s.value.SetInt(int64(d))
+	case string:
+		s.value.SetString(raw)
+	case bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q, expected true or false", raw)
+		}
+		s.value.SetBool(b)
+	case int, int32:
+		n, err := strconv.ParseInt(raw, 10, s.value.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", raw)
+		}
+		s.value.SetInt(n)
+	default:
+		return fmt.Errorf("unsupported setting type %s", s.value.Type())
+	}
+
+	return nil
+}
+
+// String — текущее значение настройки; секреты не раскрываются
+func (s setting) String() string {
+	if s.secret && !s.value.IsZero() {
+		return "<secret>"
+	}
+
+	return fmt.Sprint(s.value.Interface())
+}
@@ -0,0 +1,113 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/Aiszhio/Task/migrations"
+	"github.com/jackc/pgx/v5/stdlib"
+	"github.com/pressly/goose/v3"
+	"github.com/pressly/goose/v3/lock"
+)
+
+// Migrator применяет миграции, встроенные в бинарник. Изменяющие схему операции выполняются
+// под advisory-блокировкой PostgreSQL, поэтому одновременно стартующие реплики не мешают друг другу
+type Migrator struct {
+	provider *goose.Provider
+	// unlocked выполняет шаги составных операций, которые сами держат блокировку locker
+	unlocked *goose.Provider
+	locker   lock.SessionLocker
+	db       *sql.DB
+}
+
+func NewMigrator(pool *Pool) (*Migrator, error) {
+	locker, err := lock.NewPostgresSessionLocker()
+	if err != nil {
+		return nil, fmt.Errorf("migration lock: %w", err)
+	}
+
+	sqlDB := stdlib.OpenDBFromPool(pool.Client)
+
+	provider, err := goose.NewProvider(goose.DialectPostgres, sqlDB, migrations.FS,
+		goose.WithSessionLocker(locker))
+	if err != nil {
+		_ = sqlDB.Close()
+		return nil, fmt.Errorf("load migrations: %w", err)
+	}
+
+	unlocked, err := goose.NewProvider(goose.DialectPostgres, sqlDB, migrations.FS)
+	if err != nil {
+		_ = sqlDB.Close()
+		return nil, fmt.Errorf("load migrations: %w", err)
+	}
+
+	return &Migrator{
+		provider: provider,
+		unlocked: unlocked,
+		locker:   locker,
+		db:       sqlDB,
+	}, nil
+}
+
+// Up применяет все недостающие миграции
+func (m *Migrator) Up(ctx context.Context) ([]*goose.MigrationResult, error) {
+	return m.provider.Up(ctx)
+}
+
+// Down откатывает последнюю применённую миграцию
+func (m *Migrator) Down(ctx context.Context) (*goose.MigrationResult, error) {
+	return m.provider.Down(ctx)
+}
+
+// Redo откатывает последнюю применённую миграцию и применяет её снова под одной блокировкой,
+// чтобы между шагами другая реплика не применила миграции. Если повторно применить не удалось,
+// ошибка называет версию, на которой осталась база
+func (m *Migrator) Redo(ctx context.Context) (results []*goose.MigrationResult, err error) {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("migration lock: %w", err)
+	}
+	defer conn.Close()
+
+	if err = m.locker.SessionLock(ctx, conn); err != nil {
+		return nil, fmt.Errorf("migration lock: %w", err)
+	}
+	defer func() {
+		if unlockErr := m.locker.SessionUnlock(context.WithoutCancel(ctx), conn); unlockErr != nil && err == nil {
+			err = fmt.Errorf("migration unlock: %w", unlockErr)
+		}
+	}()
+
+	down, err := m.unlocked.Down(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	up, err := m.unlocked.UpByOne(ctx)
+	if err != nil {
+		version, versionErr := m.unlocked.GetDBVersion(ctx)
+		if versionErr != nil {
+			return []*goose.MigrationResult{down}, fmt.Errorf("reapply migration %d: %w", down.Source.Version, err)
+		}
+		return []*goose.MigrationResult{down}, fmt.Errorf("reapply migration %d: %w; database is at version %d",
+			down.Source.Version, err, version)
+	}
+
+	return []*goose.MigrationResult{down, up}, nil
+}
+
+// Status возвращает состояние каждой встроенной миграции
+func (m *Migrator) Status(ctx context.Context) ([]*goose.MigrationStatus, error) {
+	return m.provider.Status(ctx)
+}
+
+// Pending сообщает, есть ли встроенные миграции, ещё не применённые к базе; блокировку не берёт
+func (m *Migrator) Pending(ctx context.Context) (bool, error) {
+	return m.provider.HasPending(ctx)
+}
+
+// Close закрывает обёртку database/sql, через которую работает goose; сам пул остаётся открытым
+func (m *Migrator) Close() error {
+	return m.db.Close()
+}
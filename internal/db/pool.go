@@ -3,10 +3,9 @@ package db
 import (
 	"context"
 	"fmt"
-	"log"
 	"time"
 
-	"github.com/Aiszhio/Task/pkg/utils"
+	"github.com/Aiszhio/Task/internal/tracing"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -14,33 +13,54 @@ type Pool struct {
 	Client *pgxpool.Pool
 }
 
-func NewPool(name string, ctx context.Context) (*Pool, error) {
-	var pingDur = 5 * time.Second
+// Config — подключение к базе и размер пула
+type Config struct {
+	DSN             string
+	MaxConns        int32
+	MinConns        int32
+	MaxConnLifetime time.Duration
+	MaxConnIdleTime time.Duration
+	// ConnectTimeout ограничивает первое подключение и проверку связи при запуске
+	ConnectTimeout time.Duration
+}
 
-	dsn, err := utils.GetEnv(name)
+func NewPool(ctx context.Context, cfg Config) (*Pool, error) {
+	config, err := pgxpool.ParseConfig(cfg.DSN)
 	if err != nil {
-		log.Println(err)
+		return nil, fmt.Errorf("parse database dsn: %w", err)
 	}
 
-	config, err := pgxpool.ParseConfig(dsn)
-	if err != nil {
-		log.Println(err)
-	}
+	config.MaxConns = cfg.MaxConns
+	config.MinConns = cfg.MinConns
+	config.MaxConnLifetime = cfg.MaxConnLifetime
+	config.MaxConnIdleTime = cfg.MaxConnIdleTime
+	config.ConnConfig.ConnectTimeout = cfg.ConnectTimeout
+	config.ConnConfig.Tracer = tracing.NewQueryTracer()
 
 	pool, err := pgxpool.NewWithConfig(ctx, config)
 	if err != nil {
-		log.Println(err)
+		return nil, fmt.Errorf("create pool: %w", err)
 	}
 
-	ctxPing, cancel := context.WithTimeout(ctx, pingDur)
+	ctxPing, cancel := context.WithTimeout(ctx, cfg.ConnectTimeout)
 	defer cancel()
 
 	if err = pool.Ping(ctxPing); err != nil {
 		pool.Close()
-		return nil, fmt.Errorf("ping database %w: ", err)
+		return nil, fmt.Errorf("ping database: %w", err)
 	}
 
 	return &Pool{
 		Client: pool,
 	}, nil
 }
+
+// Ping проверяет, что база отвечает
+func (p *Pool) Ping(ctx context.Context) error {
+	return p.Client.Ping(ctx)
+}
+
+// Close закрывает все соединения пула, дождавшись возврата занятых
+func (p *Pool) Close() {
+	p.Client.Close()
+}
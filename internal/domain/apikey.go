@@ -0,0 +1,25 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// APIKey — ключ доступа внутренних сервисов. Сам ключ не хранится: известен только его хеш
+// и начало (Prefix), по которому ключ можно узнать в списке.
+type APIKey struct {
+	Id         uuid.UUID  `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Hash       string     `json:"-"`
+	Scopes     []string   `json:"scopes"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
+
+// Active сообщает, что ключ не отозван
+func (k *APIKey) Active() bool {
+	return k.RevokedAt == nil
+}
@@ -0,0 +1,31 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// EventType — вид изменения подписки в журнале
+type EventType string
+
+const (
+	EventCreated  EventType = "created"
+	EventUpdated  EventType = "updated"
+	EventDeleted  EventType = "deleted"
+	EventRestored EventType = "restored"
+	EventPurged   EventType = "purged"
+)
+
+// SubscriptionEvent — запись журнала изменений: состояние подписки до и после операции,
+// кто её выполнил и в рамках какого запроса. Before пуст при создании, After — при очистке корзины.
+type SubscriptionEvent struct {
+	Id             int64         `json:"id"`
+	SubscriptionId uuid.UUID     `json:"subscription_id"`
+	Type           EventType     `json:"type"`
+	Actor          string        `json:"actor"`
+	RequestId      string        `json:"request_id,omitempty"`
+	Before         *Subscription `json:"before"`
+	After          *Subscription `json:"after"`
+	CreatedAt      time.Time     `json:"created_at"`
+}
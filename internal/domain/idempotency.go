@@ -0,0 +1,26 @@
+package domain
+
+import "time"
+
+// IdempotentRequest — запрос, выполненный с заголовком Idempotency-Key, и сохранённый ответ на него.
+// StatusCode равен нулю, пока первый запрос с этим ключом ещё выполняется; до LockedUntil ключ занят им,
+// а если ответа к этому сроку нет (процесс упал или обработчик завис), ключ может занять повтор.
+type IdempotentRequest struct {
+	Key         string
+	RequestHash string
+	StatusCode  int
+	ContentType string
+	Body        []byte
+	LockedUntil time.Time
+	ExpiresAt   time.Time
+}
+
+// Completed сообщает, что ответ на запрос уже сохранён и его можно повторить
+func (r *IdempotentRequest) Completed() bool {
+	return r.StatusCode != 0
+}
+
+// Abandoned сообщает, что запрос так и не получил ответа до LockedUntil и ключ можно занять снова
+func (r *IdempotentRequest) Abandoned(now time.Time) bool {
+	return !r.Completed() && !r.LockedUntil.After(now)
+}
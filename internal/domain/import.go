@@ -0,0 +1,36 @@
+package domain
+
+import "github.com/google/uuid"
+
+// ImportMode задаёт, как сохраняются строки импорта
+type ImportMode string
+
+const (
+	// ImportAtomic сохраняет все строки в одной транзакции или не сохраняет ни одной
+	ImportAtomic ImportMode = "atomic"
+	// ImportBestEffort сохраняет каждую корректную строку независимо от остальных
+	ImportBestEffort ImportMode = "best_effort"
+)
+
+// ImportRow — строка файла импорта; Err содержит ошибку разбора, если подписку получить не удалось
+type ImportRow struct {
+	Line         int
+	Subscription *Subscription
+	Err          error
+}
+
+// ImportResult — итог обработки строки: идентификатор принятой подписки или причина отказа
+type ImportResult struct {
+	Line int
+	Id   uuid.UUID
+	Err  error
+}
+
+// ImportReport — отчёт об импорте. Принятые строки сохранены, только если Committed.
+type ImportReport struct {
+	Mode      ImportMode
+	DryRun    bool
+	Committed bool
+	Accepted  []ImportResult
+	Rejected  []ImportResult
+}
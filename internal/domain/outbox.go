@@ -0,0 +1,70 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OutboxMessage — событие подписки, записанное в outbox в одной транзакции с её изменением
+// и ожидающее публикации. FailedAt отмечает сообщение, исчерпавшее попытки: оно больше
+// не публикуется и не задерживает следующие события подписки
+type OutboxMessage struct {
+	Id             int64
+	SubscriptionId uuid.UUID
+	Event          WebhookEvent
+	Attempts       int
+	NextAttemptAt  time.Time
+	LastError      string
+	CreatedAt      time.Time
+	PublishedAt    *time.Time
+	FailedAt       *time.Time
+}
+
+// Published возвращает событие для подписчиков, которое порождает изменение из журнала;
+// окончательное удаление из корзины событий не порождает
+func (t EventType) Published() (WebhookEventType, bool) {
+	switch t {
+	case EventCreated:
+		return WebhookSubscriptionCreated, true
+	case EventUpdated, EventRestored:
+		return WebhookSubscriptionUpdated, true
+	case EventDeleted:
+		return WebhookSubscriptionDeleted, true
+	default:
+		return "", false
+	}
+}
+
+// OutboxEvent описывает для подписчиков изменение подписки из журнала: состояние after,
+// а если его нет — before; ok=false, если изменение не публикуется
+func OutboxEvent(eventType EventType, before, after *Subscription, at time.Time) (*WebhookEvent, bool) {
+	published, ok := eventType.Published()
+	if !ok {
+		return nil, false
+	}
+
+	sub := after
+	if sub == nil {
+		sub = before
+	}
+	if sub == nil {
+		return nil, false
+	}
+
+	return NewWebhookEvent(published, *sub, at), true
+}
+
+// Fail откладывает сообщение после неудачной публикации, а когда попытки по retry исчерпаны,
+// отмечает его FailedAt, чтобы сообщение, которое не удаётся опубликовать, не держало следующие
+// события той же подписки
+func (m *OutboxMessage) Fail(at time.Time, err error, retry RetryPolicy) {
+	m.Attempts++
+	m.LastError = err.Error()
+	if retry.Exhausted(m.Attempts) {
+		m.FailedAt = &at
+		return
+	}
+
+	m.NextAttemptAt = at.Add(retry.Backoff(m.Attempts))
+}
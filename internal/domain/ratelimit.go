@@ -0,0 +1,99 @@
+package domain
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RateLimit — не больше Requests запросов за Per. Лимит работает как ведро токенов ёмкостью Requests,
+// которое равномерно наполняется за Per, поэтому после простоя допускается всплеск до Requests запросов.
+type RateLimit struct {
+	Requests int
+	Per      time.Duration
+}
+
+var rateLimitUnits = map[string]time.Duration{
+	"s": time.Second,
+	"m": time.Minute,
+	"h": time.Hour,
+}
+
+// ParseRateLimit разбирает лимит вида 10/s, 600/m или 1000/h
+func ParseRateLimit(s string) (RateLimit, error) {
+	requests, unit, ok := strings.Cut(strings.TrimSpace(s), "/")
+	per, known := rateLimitUnits[unit]
+	n, err := strconv.Atoi(requests)
+	if !ok || !known || err != nil || n <= 0 {
+		return RateLimit{}, fmt.Errorf("invalid rate limit %q: expected <requests>/<s|m|h>, e.g. 600/m", s)
+	}
+
+	return RateLimit{Requests: n, Per: per}, nil
+}
+
+func (l RateLimit) String() string {
+	for unit, per := range rateLimitUnits {
+		if per == l.Per {
+			return strconv.Itoa(l.Requests) + "/" + unit
+		}
+	}
+
+	return strconv.Itoa(l.Requests) + "/" + l.Per.String()
+}
+
+// perSecond — скорость наполнения ведра
+func (l RateLimit) perSecond() float64 {
+	return float64(l.Requests) / l.Per.Seconds()
+}
+
+// RateBucket — состояние ведра токенов одного клиента
+type RateBucket struct {
+	Tokens    float64
+	UpdatedAt time.Time
+}
+
+// RateDecision — результат обращения к лимиту
+type RateDecision struct {
+	Allowed   bool
+	Limit     RateLimit
+	Remaining int
+	// Reset — через сколько ведро наполнится полностью
+	Reset time.Duration
+	// RetryAfter — через сколько появится следующий токен; ноль, если токены есть
+	RetryAfter time.Duration
+}
+
+// NewRateBucket возвращает полное ведро
+func NewRateBucket(limit RateLimit, now time.Time) RateBucket {
+	return RateBucket{Tokens: float64(limit.Requests), UpdatedAt: now}
+}
+
+// Take наполняет ведро за время, прошедшее с прошлого обращения, и забирает токен, если он есть
+func (b *RateBucket) Take(limit RateLimit, now time.Time) RateDecision {
+	capacity := float64(limit.Requests)
+
+	if elapsed := now.Sub(b.UpdatedAt); elapsed > 0 {
+		b.Tokens = math.Min(capacity, b.Tokens+elapsed.Seconds()*limit.perSecond())
+		b.UpdatedAt = now
+	}
+
+	decision := RateDecision{Limit: limit}
+	if b.Tokens >= 1 {
+		b.Tokens--
+		decision.Allowed = true
+	} else {
+		decision.RetryAfter = limit.after(1 - b.Tokens)
+	}
+
+	decision.Remaining = int(b.Tokens)
+	decision.Reset = limit.after(capacity - b.Tokens)
+
+	return decision
+}
+
+// after — за сколько наполнится tokens токенов
+func (l RateLimit) after(tokens float64) time.Duration {
+	return time.Duration(tokens / l.perSecond() * float64(time.Second))
+}
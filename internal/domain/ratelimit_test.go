@@ -0,0 +1,90 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseRateLimit(t *testing.T) {
+	valid := map[string]RateLimit{
+		"10/s":    {Requests: 10, Per: time.Second},
+		" 600/m ": {Requests: 600, Per: time.Minute},
+		"1000/h":  {Requests: 1000, Per: time.Hour},
+	}
+
+	for s, want := range valid {
+		got, err := ParseRateLimit(s)
+		if err != nil || got != want {
+			t.Errorf("ParseRateLimit(%q) = %+v, %v, want %+v", s, got, err, want)
+		}
+		if mustParseRateLimit(t, got.String()) != want {
+			t.Errorf("%+v.String() = %q does not parse back", got, got.String())
+		}
+	}
+
+	for _, s := range []string{"", "10", "10/d", "0/m", "-1/m", "ten/m", "10/"} {
+		if _, err := ParseRateLimit(s); err == nil {
+			t.Errorf("ParseRateLimit(%q) accepted an invalid limit", s)
+		}
+	}
+}
+
+func mustParseRateLimit(t *testing.T, s string) RateLimit {
+	t.Helper()
+
+	limit, err := ParseRateLimit(s)
+	if err != nil {
+		t.Fatalf("ParseRateLimit(%q): %v", s, err)
+	}
+
+	return limit
+}
+
+func TestRateBucketTake(t *testing.T) {
+	limit := RateLimit{Requests: 3, Per: 3 * time.Second}
+	now := time.Date(2025, time.July, 1, 12, 0, 0, 0, time.UTC)
+	bucket := NewRateBucket(limit, now)
+
+	for i := range 3 {
+		decision := bucket.Take(limit, now)
+		if !decision.Allowed || decision.Remaining != 2-i || decision.RetryAfter != 0 {
+			t.Fatalf("request %d = %+v, want allowed with %d remaining", i+1, decision, 2-i)
+		}
+	}
+
+	denied := bucket.Take(limit, now)
+	if denied.Allowed || denied.Remaining != 0 || denied.RetryAfter != time.Second || denied.Reset != 3*time.Second {
+		t.Fatalf("request over the limit = %+v, want denied, retry in 1s, full in 3s", denied)
+	}
+
+	// за полсекунды набирается половина токена: запрос всё ещё отклоняется
+	halfway := bucket.Take(limit, now.Add(500*time.Millisecond))
+	if halfway.Allowed || halfway.RetryAfter != 500*time.Millisecond {
+		t.Errorf("request after 0.5s = %+v, want denied, retry in 0.5s", halfway)
+	}
+
+	if refilled := bucket.Take(limit, now.Add(time.Second)); !refilled.Allowed || refilled.Remaining != 0 {
+		t.Errorf("request after 1s = %+v, want allowed with 0 remaining", refilled)
+	}
+
+	// после долгого простоя ведро полное, но не больше ёмкости
+	idle := bucket.Take(limit, now.Add(time.Hour))
+	if !idle.Allowed || idle.Remaining != 2 || idle.Reset != time.Second {
+		t.Errorf("request after an hour = %+v, want allowed with 2 remaining", idle)
+	}
+}
+
+func TestRateBucketClockSkew(t *testing.T) {
+	limit := RateLimit{Requests: 1, Per: time.Minute}
+	now := time.Date(2025, time.July, 1, 12, 0, 0, 0, time.UTC)
+	bucket := NewRateBucket(limit, now)
+	bucket.Take(limit, now)
+
+	// время другой реплики отстаёт: ведро не опустошается повторно и не наполняется
+	if decision := bucket.Take(limit, now.Add(-time.Second)); decision.Allowed {
+		t.Errorf("request with an earlier clock = %+v, want denied", decision)
+	}
+	if !bucket.UpdatedAt.Equal(now) {
+		t.Errorf("UpdatedAt = %v, want %v", bucket.UpdatedAt, now)
+	}
+}
@@ -0,0 +1,227 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ReminderKind — о чём напоминание: подписка продлится или закончится
+type ReminderKind string
+
+const (
+	ReminderRenewal ReminderKind = "renewal"
+	ReminderExpiry  ReminderKind = "expiry"
+)
+
+// Каналы, по которым отправляются напоминания
+const (
+	ReminderChannelEmail   = "email"
+	ReminderChannelWebhook = "webhook"
+	ReminderChannelLog     = "log"
+)
+
+// ReminderChannels перечисляет каналы напоминаний
+var ReminderChannels = []string{ReminderChannelEmail, ReminderChannelWebhook, ReminderChannelLog}
+
+const day = 24 * time.Hour
+
+// ReminderWindow — за сколько до продления или окончания отправляется напоминание. Записывается
+// в днях (7d), если делится на сутки, иначе как time.Duration (36h)
+type ReminderWindow time.Duration
+
+// ReminderNotice — о чём и за сколько сообщает напоминание
+type ReminderNotice struct {
+	Kind   ReminderKind   `json:"kind"`
+	DueAt  time.Time      `json:"due_at"`
+	Window ReminderWindow `json:"window" swaggertype:"string" example:"7d"`
+}
+
+// Reminder — напоминание о подписке по одному каналу и итог последней попытки его отправить.
+// Subscription и Email нужны только для отправки и не хранятся
+type Reminder struct {
+	Id             uuid.UUID `json:"id"`
+	SubscriptionId uuid.UUID `json:"subscription_id"`
+	UserId         uuid.UUID `json:"user_id"`
+	ReminderNotice
+	Channel       string         `json:"channel"`
+	Status        DeliveryStatus `json:"status"`
+	Attempts      int            `json:"attempts"`
+	NextAttemptAt *time.Time     `json:"next_attempt_at,omitempty"`
+	LastError     string         `json:"last_error,omitempty"`
+	CreatedAt     time.Time      `json:"created_at"`
+	SentAt        *time.Time     `json:"sent_at,omitempty"`
+	Subscription  Subscription   `json:"-"`
+	Email         string         `json:"-"`
+}
+
+// ReminderContact — адрес, на который пользователю приходят напоминания по почте
+type ReminderContact struct {
+	UserId    uuid.UUID `json:"user_id"`
+	Email     string    `json:"email"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// reminderNamespace — пространство имён идентификаторов напоминаний
+var reminderNamespace = uuid.MustParse("0b6f2d8e-7c1a-4f5e-a3d9-6e2c8b41f7a0")
+
+func (w ReminderWindow) String() string {
+	d := time.Duration(w)
+	if d > 0 && d%day == 0 {
+		return strconv.FormatInt(int64(d/day), 10) + "d"
+	}
+
+	return d.String()
+}
+
+func (w ReminderWindow) MarshalText() ([]byte, error) {
+	return []byte(w.String()), nil
+}
+
+// ParseReminderWindows разбирает окна через запятую, например "7d,1d" или "72h,30m".
+// Окна возвращаются от узкого к широкому, без повторов
+func ParseReminderWindows(s string) ([]ReminderWindow, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, errors.New("at least one window is required")
+	}
+
+	var windows []ReminderWindow
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+
+		var (
+			d   time.Duration
+			err error
+		)
+		if days, ok := strings.CutSuffix(part, "d"); ok {
+			var n int
+			n, err = strconv.Atoi(days)
+			d = time.Duration(n) * day
+		} else {
+			d, err = time.ParseDuration(part)
+		}
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("invalid window %q, expected a positive duration such as 7d or 36h", part)
+		}
+
+		windows = append(windows, ReminderWindow(d))
+	}
+
+	slices.Sort(windows)
+	return slices.Compact(windows), nil
+}
+
+// ParseReminderChannels разбирает каналы через запятую, например "email,log"
+func ParseReminderChannels(s string) ([]string, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, errors.New("at least one channel is required")
+	}
+
+	var channels []string
+	for _, part := range strings.Split(s, ",") {
+		channel := strings.TrimSpace(part)
+		if !slices.Contains(ReminderChannels, channel) {
+			return nil, fmt.Errorf("unknown channel %q, expected one of %s", channel,
+				strings.Join(ReminderChannels, ", "))
+		}
+
+		if !slices.Contains(channels, channel) {
+			channels = append(channels, channel)
+		}
+	}
+
+	return channels, nil
+}
+
+// DueReminders возвращает напоминания, которые пора отправить в момент now: о ближайшем продлении
+// и об окончании подписки, если до них осталось не больше одного из windows. Из подходящих окон
+// выбирается самое узкое, чтобы пропущенное широкое не уходило вдогонку вместе с ним
+func (s *Subscription) DueReminders(now time.Time, windows []ReminderWindow) []ReminderNotice {
+	var notices []ReminderNotice
+
+	add := func(kind ReminderKind, dueAt time.Time) {
+		left := dueAt.Sub(now)
+		if left <= 0 {
+			return
+		}
+
+		for _, window := range windows {
+			if left <= time.Duration(window) {
+				notices = append(notices, ReminderNotice{Kind: kind, DueAt: dueAt, Window: window})
+				return
+			}
+		}
+	}
+
+	if renewsAt, ok := s.NextRenewal(now); ok {
+		add(ReminderRenewal, renewsAt)
+	}
+	if endsAt, ok := s.EndsAt(); ok {
+		add(ReminderExpiry, endsAt)
+	}
+
+	return notices
+}
+
+// NewReminder готовит напоминание по каналу channel. Идентификатор выводится из подписки, события,
+// окна и канала, поэтому одно и то же напоминание не заводится дважды, а перенос даты заводит новое
+func NewReminder(sub Subscription, notice ReminderNotice, channel string) *Reminder {
+	key := strings.Join([]string{
+		sub.Id.String(),
+		string(notice.Kind),
+		notice.DueAt.UTC().Format(time.RFC3339),
+		notice.Window.String(),
+		channel,
+	}, "/")
+
+	return &Reminder{
+		Id:             uuid.NewSHA1(reminderNamespace, []byte(key)),
+		SubscriptionId: sub.Id,
+		UserId:         sub.UserId,
+		ReminderNotice: notice,
+		Channel:        channel,
+		Status:         DeliveryPending,
+		Subscription:   sub,
+	}
+}
+
+// ReminderEvent описывает напоминание событием subscription.reminder для вебхуков
+func ReminderEvent(reminder *Reminder, at time.Time) *WebhookEvent {
+	notice := reminder.ReminderNotice
+
+	return &WebhookEvent{
+		Id:           reminder.Id,
+		Type:         WebhookSubscriptionReminder,
+		OccurredAt:   at,
+		Subscription: reminder.Subscription,
+		Reminder:     &notice,
+	}
+}
+
+// Record записывает итог попытки отправки: успех, повтор через Backoff или окончательный провал
+func (r *Reminder) Record(at time.Time, attemptErr error, retry RetryPolicy) {
+	r.Attempts++
+	r.LastError = ""
+	r.NextAttemptAt = nil
+
+	if attemptErr == nil {
+		r.Status = DeliverySucceeded
+		r.SentAt = &at
+		return
+	}
+
+	r.LastError = attemptErr.Error()
+	if retry.Exhausted(r.Attempts) {
+		r.Status = DeliveryFailed
+		return
+	}
+
+	next := at.Add(retry.Backoff(r.Attempts))
+	r.Status = DeliveryPending
+	r.NextAttemptAt = &next
+}
@@ -0,0 +1,126 @@
+package domain
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestParseReminderWindows(t *testing.T) {
+	windows, err := ParseReminderWindows(" 7d, 36h,1d,7d ")
+	want := []ReminderWindow{ReminderWindow(day), ReminderWindow(36 * time.Hour), ReminderWindow(7 * day)}
+	if err != nil || !slices.Equal(windows, want) {
+		t.Fatalf("ParseReminderWindows = %v, %v, want %v from narrow to wide", windows, err, want)
+	}
+
+	for _, s := range []string{"", "0d", "-1h", "week", "7d,"} {
+		if _, err = ParseReminderWindows(s); err == nil {
+			t.Errorf("ParseReminderWindows(%q) accepted invalid windows", s)
+		}
+	}
+}
+
+func TestDueReminders(t *testing.T) {
+	windows := []ReminderWindow{ReminderWindow(day), ReminderWindow(7 * day)}
+	sub := Subscription{
+		Id:              uuid.New(),
+		BillingInterval: BillingMonth,
+		IntervalCount:   1,
+		StartDate:       time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC),
+		EndDate:         time.Date(2025, time.June, 1, 0, 0, 0, 0, time.UTC),
+	}
+	renewal := time.Date(2025, time.March, 1, 0, 0, 0, 0, time.UTC)
+	expiry := time.Date(2025, time.July, 1, 0, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		name string
+		now  time.Time
+		want []ReminderNotice
+	}{
+		{"outside every window", renewal.Add(-8 * day), nil},
+		{"wide window", renewal.Add(-3 * day),
+			[]ReminderNotice{{Kind: ReminderRenewal, DueAt: renewal, Window: ReminderWindow(7 * day)}}},
+		// попав в узкое окно, широкое пропущенное не отправляется вдогонку
+		{"narrowest window", renewal.Add(-time.Hour),
+			[]ReminderNotice{{Kind: ReminderRenewal, DueAt: renewal, Window: ReminderWindow(day)}}},
+		// в последнем месяце продления нет, остаётся только напоминание об окончании
+		{"renewal suppressed at the end", expiry.Add(-2 * day),
+			[]ReminderNotice{{Kind: ReminderExpiry, DueAt: expiry, Window: ReminderWindow(7 * day)}}},
+		{"after the end", expiry.Add(time.Hour), nil},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := sub.DueReminders(tc.now, windows); !slices.Equal(got, tc.want) {
+				t.Fatalf("DueReminders(%v) = %+v, want %+v", tc.now, got, tc.want)
+			}
+		})
+	}
+
+	open := sub
+	open.EndDate = time.Time{}
+	if got := open.DueReminders(expiry.Add(-time.Hour), windows); len(got) != 1 || got[0].Kind != ReminderRenewal {
+		t.Errorf("DueReminders of an open-ended subscription = %+v, want only the renewal", got)
+	}
+}
+
+func TestNextRenewal(t *testing.T) {
+	start := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+	cases := []struct {
+		interval BillingInterval
+		count    int
+		now      time.Time
+		want     time.Time
+	}{
+		{BillingWeek, 2, start, start.AddDate(0, 0, 14)},
+		{BillingMonth, 1, start.AddDate(0, 1, 0), start.AddDate(0, 2, 0)},
+		{BillingQuarter, 1, start, start.AddDate(0, 3, 0)},
+		{BillingYear, 1, start.AddDate(0, 6, 0), start.AddDate(1, 0, 0)},
+	}
+
+	for _, tc := range cases {
+		sub := Subscription{BillingInterval: tc.interval, IntervalCount: tc.count, StartDate: start}
+		if got, ok := sub.NextRenewal(tc.now); !ok || !got.Equal(tc.want) {
+			t.Errorf("%d %s NextRenewal(%v) = %v, %v, want %v", tc.count, tc.interval, tc.now, got, ok, tc.want)
+		}
+	}
+}
+
+func TestNewReminderId(t *testing.T) {
+	sub := Subscription{Id: uuid.New(), UserId: uuid.New()}
+	notice := ReminderNotice{Kind: ReminderRenewal, DueAt: time.Date(2025, time.March, 1, 0, 0, 0, 0, time.UTC),
+		Window: ReminderWindow(7 * day)}
+
+	reminder := NewReminder(sub, notice, ReminderChannelEmail)
+	if reminder.Id.Version() != 5 {
+		t.Errorf("reminder id version = %d, want a name-based uuid v5", reminder.Id.Version())
+	}
+	if again := NewReminder(sub, notice, ReminderChannelEmail); again.Id != reminder.Id {
+		t.Errorf("NewReminder ids %s and %s differ, want the same reminder", reminder.Id, again.Id)
+	}
+
+	narrow := notice
+	narrow.Window = ReminderWindow(day)
+	moved := notice
+	moved.DueAt = notice.DueAt.AddDate(0, 1, 0)
+	expiry := notice
+	expiry.Kind = ReminderExpiry
+	other := sub
+	other.Id = uuid.New()
+
+	ids := map[uuid.UUID]string{reminder.Id: "email"}
+	for name, r := range map[string]*Reminder{
+		"webhook channel":    NewReminder(sub, notice, ReminderChannelWebhook),
+		"narrow window":      NewReminder(sub, narrow, ReminderChannelEmail),
+		"moved date":         NewReminder(sub, moved, ReminderChannelEmail),
+		"expiry":             NewReminder(sub, expiry, ReminderChannelEmail),
+		"other subscription": NewReminder(other, notice, ReminderChannelEmail),
+	} {
+		if prev, ok := ids[r.Id]; ok {
+			t.Errorf("%s reminder reuses the id of %s", name, prev)
+		}
+		ids[r.Id] = name
+	}
+}
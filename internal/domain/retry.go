@@ -0,0 +1,26 @@
+package domain
+
+import "time"
+
+// RetryPolicy — политика повторов: задержка растёт вдвое после каждой неудачи, от Base до Max,
+// а после MaxAttempts попыток повторы прекращаются; нулевой MaxAttempts не ограничивает попытки
+type RetryPolicy struct {
+	MaxAttempts int
+	Base        time.Duration
+	Max         time.Duration
+}
+
+// Backoff возвращает задержку перед следующей попыткой после attempts неудачных
+func (r RetryPolicy) Backoff(attempts int) time.Duration {
+	delay := r.Base
+	for i := 1; i < attempts && delay < r.Max; i++ {
+		delay *= 2
+	}
+
+	return min(delay, r.Max)
+}
+
+// Exhausted сообщает, что после attempts попыток повторять больше нельзя
+func (r RetryPolicy) Exhausted(attempts int) bool {
+	return r.MaxAttempts > 0 && attempts >= r.MaxAttempts
+}
@@ -0,0 +1,77 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	retry := RetryPolicy{MaxAttempts: 5, Base: 30 * time.Second, Max: 5 * time.Minute}
+
+	want := []time.Duration{30 * time.Second, time.Minute, 2 * time.Minute, 4 * time.Minute, 5 * time.Minute,
+		5 * time.Minute}
+	for i, delay := range want {
+		if got := retry.Backoff(i + 1); got != delay {
+			t.Errorf("Backoff(%d) = %v, want %v", i+1, got, delay)
+		}
+	}
+
+	// без предела попыток задержка не переполняется
+	if got := retry.Backoff(1000); got != retry.Max {
+		t.Errorf("Backoff(1000) = %v, want %v", got, retry.Max)
+	}
+}
+
+func TestRetryPolicyExhausted(t *testing.T) {
+	retry := RetryPolicy{MaxAttempts: 3}
+	for attempts, want := range []bool{false, false, false, true, true} {
+		if got := retry.Exhausted(attempts); got != want {
+			t.Errorf("Exhausted(%d) = %v, want %v", attempts, got, want)
+		}
+	}
+
+	if (RetryPolicy{}).Exhausted(1000) {
+		t.Error("zero MaxAttempts exhausted retries, want unlimited attempts")
+	}
+}
+
+func TestWebhookDeliveryRecord(t *testing.T) {
+	retry := RetryPolicy{MaxAttempts: 2, Base: time.Minute, Max: time.Hour}
+	at := time.Date(2025, time.July, 1, 12, 0, 0, 0, time.UTC)
+	delivery := &WebhookDelivery{Status: DeliveryPending}
+
+	delivery.Record(at, 503, errors.New("unexpected response status 503"), retry)
+	if delivery.Status != DeliveryPending || delivery.Attempts != 1 || delivery.ResponseStatus != 503 ||
+		delivery.NextAttemptAt == nil || !delivery.NextAttemptAt.Equal(at.Add(time.Minute)) {
+		t.Fatalf("after the first failure = %+v, want pending, retry in 1m", delivery)
+	}
+
+	delivery.Record(at.Add(time.Minute), 0, errors.New("timeout"), retry)
+	if delivery.Status != DeliveryFailed || delivery.Attempts != 2 || delivery.NextAttemptAt != nil ||
+		delivery.LastError != "timeout" {
+		t.Fatalf("after the last attempt = %+v, want failed without a next attempt", delivery)
+	}
+
+	delivered := &WebhookDelivery{Status: DeliveryPending, LastError: "timeout"}
+	delivered.Record(at, 204, nil, retry)
+	if delivered.Status != DeliverySucceeded || delivered.DeliveredAt == nil || delivered.LastError != "" {
+		t.Fatalf("after a success = %+v, want succeeded without an error", delivered)
+	}
+}
+
+func TestOutboxMessageFail(t *testing.T) {
+	retry := RetryPolicy{MaxAttempts: 2, Base: time.Minute, Max: time.Hour}
+	at := time.Date(2025, time.July, 1, 12, 0, 0, 0, time.UTC)
+	msg := &OutboxMessage{}
+
+	msg.Fail(at, errors.New("sink is down"), retry)
+	if msg.Attempts != 1 || msg.FailedAt != nil || !msg.NextAttemptAt.Equal(at.Add(time.Minute)) {
+		t.Fatalf("after the first failure %+v, want a retry in a minute", msg)
+	}
+
+	msg.Fail(at, errors.New("sink is down"), retry)
+	if msg.Attempts != 2 || msg.FailedAt == nil || !msg.FailedAt.Equal(at) || msg.LastError != "sink is down" {
+		t.Fatalf("after the last attempt %+v, want the message given up at %s", msg, at)
+	}
+}
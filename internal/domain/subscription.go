@@ -6,15 +6,29 @@ import (
 	"github.com/google/uuid"
 )
 
+// BillingInterval задаёт единицу расчётного периода подписки
+type BillingInterval string
+
+const (
+	BillingWeek    BillingInterval = "week"
+	BillingMonth   BillingInterval = "month"
+	BillingQuarter BillingInterval = "quarter"
+	BillingYear    BillingInterval = "year"
+)
+
 type Subscription struct {
-	Id          uuid.UUID `json:"id"`
-	ServiceName string    `json:"service_name"`
-	Price       int       `json:"price"`
-	UserId      uuid.UUID `json:"user_id"`
-	StartDate   time.Time `json:"start_date"`
-	EndDate     time.Time `json:"end_date"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	Id              uuid.UUID       `json:"id"`
+	ServiceName     string          `json:"service_name"`
+	Price           int             `json:"price"`
+	BillingInterval BillingInterval `json:"billing_interval"`
+	IntervalCount   int             `json:"interval_count"`
+	UserId          uuid.UUID       `json:"user_id"`
+	StartDate       time.Time       `json:"start_date"`
+	EndDate         time.Time       `json:"end_date,omitzero"`
+	CreatedAt       time.Time       `json:"created_at"`
+	UpdatedAt       time.Time       `json:"updated_at"`
+	Version         int             `json:"version"`
+	DeletedAt       *time.Time      `json:"deleted_at,omitempty"`
 }
 
 type SubscriptionSummary struct {
@@ -23,3 +37,135 @@ type SubscriptionSummary struct {
 	StartDate   time.Time `json:"start_date"`
 	EndDate     time.Time `json:"end_date"`
 }
+
+type MonthCost struct {
+	Month time.Time `json:"month"`
+	Cost  uint64    `json:"cost"`
+}
+
+type SubscriptionCost struct {
+	TotalCost uint64      `json:"total_cost"`
+	Months    []MonthCost `json:"months"`
+}
+
+type SubscriptionFilter struct {
+	UserID      uuid.UUID
+	ServiceName string
+	ActiveAt    *time.Time
+	MinPrice    *int
+	MaxPrice    *int
+	Deleted     bool // только подписки из корзины вместо действующих
+	SortBy      string
+	SortDesc    bool
+	Limit       int
+	Offset      int
+}
+
+// SubscriptionStats — сводка по подпискам, действующим в месяце: их число и суммарная месячная стоимость
+type SubscriptionStats struct {
+	Active       int
+	MonthlySpend float64
+}
+
+type SubscriptionPage struct {
+	Items []Subscription `json:"items"`
+	Total uint64         `json:"total"`
+}
+
+// SubscriptionSortFields перечисляет поля, по которым допускается сортировка списка
+var SubscriptionSortFields = []string{
+	"id", "service_name", "price", "billing_interval", "interval_count", "user_id", "start_date", "end_date",
+	"created_at", "updated_at", "version", "deleted_at",
+}
+
+func (b BillingInterval) Valid() bool {
+	switch b {
+	case BillingWeek, BillingMonth, BillingQuarter, BillingYear:
+		return true
+	}
+
+	return false
+}
+
+// Next возвращает дату, с которой начинается период, следующий через count интервалов после t
+func (b BillingInterval) Next(t time.Time, count int) time.Time {
+	switch b {
+	case BillingWeek:
+		return t.AddDate(0, 0, 7*count)
+	case BillingQuarter:
+		return t.AddDate(0, 3*count, 0)
+	case BillingYear:
+		return t.AddDate(count, 0, 0)
+	default:
+		return t.AddDate(0, count, 0)
+	}
+}
+
+func (b BillingInterval) perYear() float64 {
+	switch b {
+	case BillingWeek:
+		return 52
+	case BillingQuarter:
+		return 4
+	case BillingYear:
+		return 1
+	default:
+		return 12
+	}
+}
+
+// MonthStart приводит дату к первому числу её месяца
+func MonthStart(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// ActiveIn сообщает, действует ли подписка хотя бы часть указанного месяца
+func (s *Subscription) ActiveIn(month time.Time) bool {
+	month = MonthStart(month)
+
+	if MonthStart(s.StartDate).After(month) {
+		return false
+	}
+
+	return s.EndDate.IsZero() || !MonthStart(s.EndDate).Before(month)
+}
+
+// EndsAt возвращает момент окончания подписки — начало месяца, следующего за EndDate;
+// у бессрочной подписки его нет
+func (s *Subscription) EndsAt() (time.Time, bool) {
+	if s.EndDate.IsZero() {
+		return time.Time{}, false
+	}
+
+	return MonthStart(s.EndDate).AddDate(0, 1, 0), true
+}
+
+// NextRenewal возвращает начало ближайшего после t расчётного периода, кроме первого; если подписка
+// заканчивается раньше, продления нет
+func (s *Subscription) NextRenewal(t time.Time) (time.Time, bool) {
+	count := s.IntervalCount
+	if count <= 0 {
+		count = 1
+	}
+
+	var next time.Time
+	for periods := count; !next.After(t); periods += count {
+		next = s.BillingInterval.Next(s.StartDate, periods)
+	}
+
+	if endsAt, ok := s.EndsAt(); ok && !next.Before(endsAt) {
+		return time.Time{}, false
+	}
+
+	return next, true
+}
+
+// MonthlyCost приводит стоимость подписки к одному месяцу с учётом расчётного периода
+func (s *Subscription) MonthlyCost() float64 {
+	count := s.IntervalCount
+	if count <= 0 {
+		count = 1
+	}
+
+	return float64(s.Price) * s.BillingInterval.perYear() / 12 / float64(count)
+}
@@ -0,0 +1,132 @@
+package domain
+
+import (
+	"encoding/json"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// WebhookEventType — вид события подписки, о котором сообщает вебхук
+type WebhookEventType string
+
+const (
+	WebhookSubscriptionCreated    WebhookEventType = "subscription.created"
+	WebhookSubscriptionUpdated    WebhookEventType = "subscription.updated"
+	WebhookSubscriptionDeleted    WebhookEventType = "subscription.deleted"
+	WebhookSubscriptionEndingSoon WebhookEventType = "subscription.ending_soon"
+	WebhookSubscriptionReminder   WebhookEventType = "subscription.reminder"
+)
+
+// WebhookEventTypes перечисляет события, на которые можно подписать вебхук
+var WebhookEventTypes = []WebhookEventType{
+	WebhookSubscriptionCreated,
+	WebhookSubscriptionUpdated,
+	WebhookSubscriptionDeleted,
+	WebhookSubscriptionEndingSoon,
+	WebhookSubscriptionReminder,
+}
+
+// DeliveryStatus — состояние доставки события на вебхук
+type DeliveryStatus string
+
+const (
+	DeliveryPending   DeliveryStatus = "pending"
+	DeliverySucceeded DeliveryStatus = "succeeded"
+	DeliveryFailed    DeliveryStatus = "failed"
+)
+
+// Webhook — адрес, на который отправляются события выбранных видов. Secret подписывает
+// тело каждого запроса и возвращается клиенту только при регистрации
+type Webhook struct {
+	Id         uuid.UUID          `json:"id"`
+	URL        string             `json:"url"`
+	EventTypes []WebhookEventType `json:"event_types"`
+	Secret     string             `json:"-"`
+	CreatedAt  time.Time          `json:"created_at"`
+}
+
+// WebhookEvent — событие подписки в том виде, в котором оно уходит получателю;
+// Reminder заполнен только у subscription.reminder
+type WebhookEvent struct {
+	Id           uuid.UUID        `json:"id"`
+	Type         WebhookEventType `json:"type"`
+	OccurredAt   time.Time        `json:"occurred_at"`
+	Subscription Subscription     `json:"subscription"`
+	Reminder     *ReminderNotice  `json:"reminder,omitempty"`
+}
+
+// WebhookDelivery — доставка одного события на один вебхук и итог последней попытки
+type WebhookDelivery struct {
+	Id             uuid.UUID        `json:"id"`
+	WebhookId      uuid.UUID        `json:"webhook_id"`
+	EventId        uuid.UUID        `json:"event_id"`
+	EventType      WebhookEventType `json:"event_type"`
+	Payload        json.RawMessage  `json:"payload" swaggertype:"object"`
+	Status         DeliveryStatus   `json:"status"`
+	Attempts       int              `json:"attempts"`
+	NextAttemptAt  *time.Time       `json:"next_attempt_at,omitempty"`
+	LastAttemptAt  *time.Time       `json:"last_attempt_at,omitempty"`
+	ResponseStatus int              `json:"response_status,omitempty"`
+	LastError      string           `json:"last_error,omitempty"`
+	CreatedAt      time.Time        `json:"created_at"`
+	DeliveredAt    *time.Time       `json:"delivered_at,omitempty"`
+}
+
+// WebhookJob — доставка, взятая в работу, вместе с адресом и секретом её вебхука
+type WebhookJob struct {
+	Delivery WebhookDelivery
+	URL      string
+	Secret   string
+}
+
+// endingSoonNamespace — пространство имён идентификаторов событий subscription.ending_soon
+var endingSoonNamespace = uuid.MustParse("5d3a1c52-8f0e-4b8e-9c65-0f7d4a6b2e91")
+
+// NewWebhookEvent описывает изменение подписки, произошедшее в момент at
+func NewWebhookEvent(eventType WebhookEventType, sub Subscription, at time.Time) *WebhookEvent {
+	return &WebhookEvent{Id: uuid.New(), Type: eventType, OccurredAt: at, Subscription: sub}
+}
+
+// EndingSoonEvent сообщает о скором окончании подписки. Идентификатор выводится из подписки
+// и даты окончания, поэтому повторные проверки не порождают новых доставок, а перенос даты — порождает
+func EndingSoonEvent(sub Subscription, endsAt, at time.Time) *WebhookEvent {
+	id := uuid.NewSHA1(endingSoonNamespace, []byte(sub.Id.String()+"/"+endsAt.UTC().Format(time.RFC3339)))
+
+	return &WebhookEvent{Id: id, Type: WebhookSubscriptionEndingSoon, OccurredAt: at, Subscription: sub}
+}
+
+// Wants сообщает, подписан ли вебхук на события этого вида
+func (w *Webhook) Wants(eventType WebhookEventType) bool {
+	return slices.Contains(w.EventTypes, eventType)
+}
+
+func (t WebhookEventType) Valid() bool {
+	return slices.Contains(WebhookEventTypes, t)
+}
+
+// Record записывает итог попытки доставки: успех, повтор через Backoff или окончательный провал
+func (d *WebhookDelivery) Record(at time.Time, responseStatus int, attemptErr error, retry RetryPolicy) {
+	d.Attempts++
+	d.LastAttemptAt = &at
+	d.ResponseStatus = responseStatus
+	d.LastError = ""
+	d.NextAttemptAt = nil
+
+	if attemptErr == nil {
+		d.Status = DeliverySucceeded
+		d.DeliveredAt = &at
+		return
+	}
+
+	d.LastError = attemptErr.Error()
+	if retry.Exhausted(d.Attempts) {
+		d.Status = DeliveryFailed
+		return
+	}
+
+	next := at.Add(retry.Backoff(d.Attempts))
+	d.Status = DeliveryPending
+	d.NextAttemptAt = &next
+}
@@ -1,15 +1,174 @@
 package errors_package
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Виды ошибок, по которым транспортный слой выбирает код ответа.
+// Проверяются через errors.Is.
+var (
+	ErrNotFound   = errors.New("not found")
+	ErrConflict   = errors.New("conflict")
+	ErrValidation = errors.New("validation failed")
+	ErrInternal   = errors.New("internal error")
+
+	ErrPreconditionFailed = errors.New("precondition failed")
+	ErrUnauthorized       = errors.New("unauthorized")
+	ErrForbidden          = errors.New("forbidden")
+	ErrTooManyRequests    = errors.New("too many requests")
+)
+
+// Коды нарушений, по которым клиент определяет причину ошибки валидации поля
+const (
+	CodeRequired      = "required"
+	CodeTooSmall      = "too_small"
+	CodeInvalidValue  = "invalid_value"
+	CodeInvalidFormat = "invalid_format"
+	CodeInvalidRange  = "invalid_range"
+	CodeInPast        = "in_past"
+	CodeImmutable     = "immutable"
+)
 
 var (
-	NoVariable         = errors.New("no such variable in env")
-	EmptySub           = errors.New("subscription cannot be empty")
-	EmptySubName       = errors.New("subscription name cannot be empty")
-	InvalidPrice       = errors.New("price cannot be less than zero")
-	SubscriptionInPast = errors.New("subscription in past")
-	EmptyUser          = errors.New("user cannot be empty")
-	ErrEmptyId         = errors.New("id cannot be empty")
-	InvalidPeriod      = errors.New("invalid period")
-	ErrDateRequired    = errors.New("date is required")
+	EmptySub           = Validation("", CodeRequired, "subscription cannot be empty")
+	EmptySubName       = Validation("service_name", CodeRequired, "subscription name cannot be empty")
+	InvalidPrice       = Validation("price", CodeTooSmall, "price cannot be less than zero")
+	SubscriptionInPast = Validation("start_date", CodeInPast, "subscription in past")
+	EmptyUser          = Validation("user_id", CodeRequired, "user cannot be empty")
+	ErrEmptyId         = Validation("id", CodeRequired, "id cannot be empty")
+	InvalidPeriod      = Validation("end_date", CodeInvalidRange, "invalid period")
+	ErrDateRequired    = Validation("end_date", CodeRequired, "date is required")
+	InvalidSortField   = Validation("sort", CodeInvalidValue, "invalid sort field")
+	InvalidPriceRange  = Validation("min_price", CodeInvalidRange, "min price cannot be greater than max price")
+	InvalidPageSize    = Validation("page_size", CodeInvalidRange, "invalid page size")
+	InvalidInterval    = Validation("billing_interval", CodeInvalidValue,
+		"billing interval must be one of week, month, quarter, year")
+	InvalidIntervalCnt = Validation("interval_count", CodeTooSmall, "interval count must be positive")
+	ImmutableUser      = Validation("user_id", CodeImmutable, "user of a subscription cannot be changed")
+	InvalidImportMode  = Validation("mode", CodeInvalidValue, "import mode must be atomic or best_effort")
+	EmptyKeyName       = Validation("name", CodeRequired, "api key name cannot be empty")
+	EmptyKeyScopes     = Validation("scopes", CodeRequired, "api key must have at least one scope")
+	InvalidKeyScope    = Validation("scopes", CodeInvalidValue,
+		"scopes must be subscriptions:read, subscriptions:write or reports:read")
+	InvalidWebhookURL   = Validation("url", CodeInvalidFormat, "webhook url must be an absolute http or https url")
+	EmptyWebhookEvents  = Validation("event_types", CodeRequired, "webhook must subscribe to at least one event type")
+	InvalidWebhookEvent = Validation("event_types", CodeInvalidValue, "event types must be subscription.created, "+
+		"subscription.updated, subscription.deleted, subscription.ending_soon or subscription.reminder")
+	WeakWebhookSecret     = Validation("secret", CodeTooSmall, "webhook secret must be at least 16 characters")
+	InvalidDeliveryStatus = Validation("status", CodeInvalidValue, "status must be pending, succeeded or failed")
+	InvalidReminderEmail  = Validation("email", CodeInvalidFormat, "email must be a valid address")
 )
+
+// Error — ошибка предметной области: вид ошибки, сообщение для клиента и исходная причина.
+// Для ошибок валидации Field и Code указывают на поле запроса и нарушенное правило.
+type Error struct {
+	Kind    error
+	Message string
+	Field   string
+	Code    string
+	Err     error
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %v", e.Message, e.Err)
+	}
+
+	return e.Message
+}
+
+func (e *Error) Is(target error) bool {
+	return target == e.Kind
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// RowError указывает, на какой записи пакетной операции произошла ошибка
+type RowError struct {
+	Index int
+	Err   error
+}
+
+func (e *RowError) Error() string {
+	return fmt.Sprintf("row %d: %v", e.Index, e.Err)
+}
+
+func (e *RowError) Unwrap() error {
+	return e.Err
+}
+
+// RowErrors — ошибки всех записей, не прошедших пакетную операцию
+type RowErrors []*RowError
+
+func (e RowErrors) Error() string {
+	messages := make([]string, 0, len(e))
+	for _, rowErr := range e {
+		messages = append(messages, rowErr.Error())
+	}
+
+	return strings.Join(messages, "; ")
+}
+
+func (e RowErrors) Unwrap() []error {
+	errs := make([]error, 0, len(e))
+	for _, rowErr := range e {
+		errs = append(errs, rowErr)
+	}
+
+	return errs
+}
+
+func NotFound(format string, args ...any) error {
+	return &Error{Kind: ErrNotFound, Message: fmt.Sprintf(format, args...)}
+}
+
+func Conflict(format string, args ...any) error {
+	return &Error{Kind: ErrConflict, Message: fmt.Sprintf(format, args...)}
+}
+
+// PreconditionFailed — запись изменилась с тех пор, как клиент её прочитал
+func PreconditionFailed(format string, args ...any) error {
+	return &Error{Kind: ErrPreconditionFailed, Message: fmt.Sprintf(format, args...)}
+}
+
+// Unauthorized — запрос не аутентифицирован: нет учётных данных или они недействительны
+func Unauthorized(format string, args ...any) error {
+	return &Error{Kind: ErrUnauthorized, Message: fmt.Sprintf(format, args...)}
+}
+
+// Forbidden — субъект запроса аутентифицирован, но не может выполнить операцию
+func Forbidden(format string, args ...any) error {
+	return &Error{Kind: ErrForbidden, Message: fmt.Sprintf(format, args...)}
+}
+
+// TooManyRequests — клиент превысил лимит запросов
+func TooManyRequests(format string, args ...any) error {
+	return &Error{Kind: ErrTooManyRequests, Message: fmt.Sprintf(format, args...)}
+}
+
+func Validation(field, code, message string) error {
+	return &Error{Kind: ErrValidation, Field: field, Code: code, Message: message}
+}
+
+// Internal оборачивает сбой инфраструктуры; причина не должна попадать в ответ клиенту
+func Internal(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return &Error{Kind: ErrInternal, Message: ErrInternal.Error(), Err: err}
+}
+
+// Message возвращает текст ошибки, безопасный для показа клиенту
+func Message(err error) string {
+	var domainErr *Error
+	if errors.As(err, &domainErr) {
+		return domainErr.Message
+	}
+
+	return err.Error()
+}
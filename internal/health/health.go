@@ -0,0 +1,81 @@
+// Package health отвечает на пробы живости и готовности сервиса
+package health
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+
+	"github.com/Aiszhio/Task/internal/db"
+)
+
+// ErrDraining — сервис останавливается и новых запросов не принимает
+var ErrDraining = errors.New("shutting down")
+
+// Check — одна проверка готовности, например доступность базы
+type Check struct {
+	Name  string
+	Check func(ctx context.Context) error
+}
+
+// Probe проверяет готовность сервиса принимать трафик. С началом остановки (Drain)
+// готовность пропадает сразу, не дожидаясь проверок
+type Probe struct {
+	checks   []Check
+	timeout  time.Duration
+	draining atomic.Bool
+}
+
+func NewProbe(timeout time.Duration, checks ...Check) *Probe {
+	return &Probe{
+		checks:  checks,
+		timeout: timeout,
+	}
+}
+
+// Drain переводит сервис в состояние остановки
+func (p *Probe) Drain() {
+	p.draining.Store(true)
+}
+
+// Ready выполняет проверки и возвращает ошибку каждой из них по имени; nil — проверка пройдена
+func (p *Probe) Ready(ctx context.Context) (map[string]error, bool) {
+	if p.draining.Load() {
+		return map[string]error{"shutdown": ErrDraining}, false
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	results := make(map[string]error, len(p.checks))
+	ready := true
+	for _, check := range p.checks {
+		err := check.Check(ctx)
+		results[check.Name] = err
+		ready = ready && err == nil
+	}
+
+	return results, ready
+}
+
+// Database проверяет, что база отвечает
+func Database(pool *db.Pool) Check {
+	return Check{Name: "database", Check: pool.Ping}
+}
+
+// Migrations проверяет, что к базе применены все миграции, встроенные в сервис
+func Migrations(migrator *db.Migrator) Check {
+	return Check{Name: "migrations", Check: func(ctx context.Context) error {
+		pending, err := migrator.Pending(ctx)
+		if err != nil {
+			return err
+		}
+
+		if pending {
+			return errors.New("migrations pending")
+		}
+
+		return nil
+	}}
+}
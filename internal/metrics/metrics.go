@@ -0,0 +1,105 @@
+// Package metrics описывает метрики сервиса в формате Prometheus: HTTP-запросы, пул соединений,
+// обращения к хранилищу и показатели подписок
+package metrics
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "subscriptions_api"
+
+// Metrics — набор метрик сервиса и реестр, из которого их отдаёт /metrics
+type Metrics struct {
+	registry *prometheus.Registry
+
+	requests        *prometheus.CounterVec
+	requestDuration *prometheus.HistogramVec
+	queryDuration   *prometheus.HistogramVec
+
+	activeSubscriptions prometheus.Gauge
+	monthlySpend        prometheus.Gauge
+	statsUpdated        prometheus.Gauge
+}
+
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "HTTP requests by method, route and status code.",
+		}, []string{"method", "route", "status"}),
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "HTTP request latency by method, route and status code.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+		queryDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "repository_operation_duration_seconds",
+			Help:      "Storage operation latency by repository, operation and outcome.",
+			Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5},
+		}, []string{"repository", "operation", "outcome"}),
+		activeSubscriptions: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "active_subscriptions",
+			Help:      "Subscriptions active in the current month, excluding deleted ones.",
+		}),
+		monthlySpend: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "monthly_recurring_spend",
+			Help:      "Monthly cost of active subscriptions, normalized to one month.",
+		}),
+		statsUpdated: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "subscription_stats_updated_timestamp_seconds",
+			Help:      "Unix time of the last successful subscription stats refresh.",
+		}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.requests,
+		m.requestDuration,
+		m.queryDuration,
+		m.activeSubscriptions,
+		m.monthlySpend,
+		m.statsUpdated,
+	)
+
+	return m
+}
+
+// Handler отдаёт метрики в текстовом формате Prometheus
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
+
+// Register добавляет в реестр дополнительный сборщик, например статистику пула соединений
+func (m *Metrics) Register(collector prometheus.Collector) {
+	m.registry.MustRegister(collector)
+}
+
+func (m *Metrics) ObserveRequest(method, route, status string, duration time.Duration) {
+	m.requests.WithLabelValues(method, route, status).Inc()
+	m.requestDuration.WithLabelValues(method, route, status).Observe(duration.Seconds())
+}
+
+// ObserveOperation записывает длительность обращения к хранилищу; outcome — ok или error
+func (m *Metrics) ObserveOperation(repository, operation, outcome string, duration time.Duration) {
+	m.queryDuration.WithLabelValues(repository, operation, outcome).Observe(duration.Seconds())
+}
+
+// SetSubscriptionStats обновляет показатели подписок
+func (m *Metrics) SetSubscriptionStats(active int, monthlySpend float64, at time.Time) {
+	m.activeSubscriptions.Set(float64(active))
+	m.monthlySpend.Set(monthlySpend)
+	m.statsUpdated.Set(float64(at.Unix()))
+}
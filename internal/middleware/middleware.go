@@ -2,9 +2,20 @@ package middleware
 
 import (
 	"log/slog"
+	"net/http"
+	"strconv"
 	"time"
 
+	"github.com/Aiszhio/Task/internal/metrics"
+	"github.com/Aiszhio/Task/internal/requestmeta"
+	"github.com/Aiszhio/Task/internal/tracing"
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
 func NewMiddleware(logger *slog.Logger) gin.HandlerFunc {
@@ -15,22 +26,109 @@ func NewMiddleware(logger *slog.Logger) gin.HandlerFunc {
 
 		duration := time.Since(start)
 
-		logger.Info(
+		logger.InfoContext(ctx.Request.Context(),
 			"method", ctx.Request.Method,
 			"url", ctx.Request.URL.String(),
 			slog.Int("status", ctx.Writer.Status()),
 			slog.Int("latency_ms", int(duration.Milliseconds())),
 			"client_ip", ctx.ClientIP(),
+			"request_id", requestmeta.From(ctx.Request.Context()).RequestID,
 		)
 
 		if len(ctx.Errors) > 0 {
 			for _, err := range ctx.Errors {
-				logger.Error("handle error",
+				logger.ErrorContext(ctx.Request.Context(), "handle error",
 					"method", ctx.Request.Method,
 					"url", ctx.Request.URL.String(),
+					"request_id", requestmeta.From(ctx.Request.Context()).RequestID,
 					"err", err,
 				)
 			}
 		}
 	}
 }
+
+const (
+	maxRequestIDLength = 128
+	anonymousActor     = "anonymous"
+)
+
+// RequestMeta присваивает запросу идентификатор (из X-Request-ID или новый), возвращает его клиенту
+// и кладёт в context запроса; исполнителя уточняет аутентификация
+func RequestMeta() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		requestID := ctx.GetHeader("X-Request-ID")
+		if requestID == "" || len(requestID) > maxRequestIDLength {
+			requestID = uuid.NewString()
+		}
+
+		ctx.Header("X-Request-ID", requestID)
+		ctx.Request = ctx.Request.WithContext(requestmeta.With(ctx.Request.Context(), requestmeta.Meta{
+			RequestID: requestID,
+			Actor:     anonymousActor,
+		}))
+
+		ctx.Next()
+	}
+}
+
+// unmatchedRoute — метка запросов к неизвестным маршрутам, чтобы произвольные пути не плодили серии метрик
+const unmatchedRoute = "unmatched"
+
+// Metrics считает запросы и их длительность по методу, шаблону маршрута и коду ответа
+func Metrics(m *metrics.Metrics) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		start := time.Now()
+
+		ctx.Next()
+
+		route := ctx.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+
+		m.ObserveRequest(ctx.Request.Method, route, strconv.Itoa(ctx.Writer.Status()), time.Since(start))
+	}
+}
+
+// Tracing открывает серверный спан на каждый запрос, продолжая трассу из заголовка traceparent,
+// и кладёт его в context запроса; должен стоять первым, чтобы спан охватывал остальные middleware
+func Tracing() gin.HandlerFunc {
+	tracer := tracing.Tracer()
+
+	return func(ctx *gin.Context) {
+		parent := otel.GetTextMapPropagator().Extract(ctx.Request.Context(),
+			propagation.HeaderCarrier(ctx.Request.Header))
+
+		route := ctx.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+
+		spanCtx, span := tracer.Start(parent, ctx.Request.Method+" "+route,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPRequestMethodKey.String(ctx.Request.Method),
+				semconv.HTTPRoute(route),
+				semconv.URLPath(ctx.Request.URL.Path),
+				semconv.ClientAddress(ctx.ClientIP()),
+				semconv.UserAgentOriginal(ctx.Request.UserAgent()),
+			),
+		)
+		defer span.End()
+
+		ctx.Request = ctx.Request.WithContext(spanCtx)
+
+		ctx.Next()
+
+		status := ctx.Writer.Status()
+		span.SetAttributes(semconv.HTTPResponseStatusCode(status))
+		if status >= 500 {
+			span.SetStatus(codes.Error, http.StatusText(status))
+		}
+
+		for _, err := range ctx.Errors {
+			span.RecordError(err)
+		}
+	}
+}
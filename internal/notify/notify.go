@@ -0,0 +1,75 @@
+// Package notify содержит каналы, по которым worker.RunReminders отправляет напоминания
+// о продлении и окончании подписок: почту, вебхуки и лог
+package notify
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/Aiszhio/Task/internal/domain"
+)
+
+// LogChannel пишет напоминания в лог; подходит для отладки и сбора логов
+type LogChannel struct {
+	logger *slog.Logger
+}
+
+func NewLogChannel(logger *slog.Logger) *LogChannel {
+	return &LogChannel{logger: logger}
+}
+
+func (c *LogChannel) Name() string {
+	return domain.ReminderChannelLog
+}
+
+func (c *LogChannel) Send(ctx context.Context, reminder *domain.Reminder) error {
+	c.logger.InfoContext(ctx, "subscription reminder",
+		"reminder", reminder.Id,
+		"kind", reminder.Kind,
+		"window", reminder.Window,
+		"due_at", reminder.DueAt,
+		"user", reminder.UserId,
+		"subscription", reminder.SubscriptionId,
+		"service", reminder.Subscription.ServiceName,
+	)
+
+	return nil
+}
+
+// Message составляет тему и текст напоминания для пользователя
+func Message(reminder *domain.Reminder) (string, string) {
+	sub := reminder.Subscription
+	service := strings.Join(strings.Fields(sub.ServiceName), " ")
+	date := reminder.DueAt.UTC().Format("2 January 2006")
+
+	var subject, body strings.Builder
+	switch reminder.Kind {
+	case domain.ReminderRenewal:
+		fmt.Fprintf(&subject, "Your %s subscription renews on %s", service, date)
+		fmt.Fprintf(&body, "Your %s subscription renews on %s and %d will be charged %s.\n\n",
+			service, date, sub.Price, period(sub))
+		body.WriteString("If you no longer need it, cancel it before that date.\n")
+	default:
+		fmt.Fprintf(&subject, "Your %s subscription ends on %s", service, date)
+		fmt.Fprintf(&body, "Your %s subscription ends on %s.\n\n", service, date)
+		body.WriteString("If it renews automatically with the provider, cancel it there to avoid being charged.\n")
+	}
+
+	return subject.String(), body.String()
+}
+
+// period описывает расчётный период подписки: "per month", "every 3 months"
+func period(sub domain.Subscription) string {
+	interval := sub.BillingInterval
+	if interval == "" {
+		interval = domain.BillingMonth
+	}
+
+	if sub.IntervalCount > 1 {
+		return fmt.Sprintf("every %d %ss", sub.IntervalCount, interval)
+	}
+
+	return "per " + string(interval)
+}
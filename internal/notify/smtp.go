@@ -0,0 +1,137 @@
+package notify
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strings"
+	"time"
+
+	"github.com/Aiszhio/Task/internal/domain"
+)
+
+// SMTPChannel отправляет напоминания письмом на адрес, который пользователь указал для напоминаний.
+// Если сервер поддерживает STARTTLS, соединение шифруется; учётные данные передаются только
+// по зашифрованному соединению или на localhost
+type SMTPChannel struct {
+	addr    string
+	host    string
+	from    *mail.Address
+	auth    smtp.Auth
+	timeout time.Duration
+}
+
+// NewSMTPChannel создаёт канал для сервера addr (host:port) и отправителя from, например
+// "Subscriptions <no-reply@example.com>"; без username письма отправляются без аутентификации,
+// timeout ограничивает отправку одного письма
+func NewSMTPChannel(addr, username, password, from string, timeout time.Duration) (*SMTPChannel, error) {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, fmt.Errorf("smtp address %q: %w", addr, err)
+	}
+
+	sender, err := mail.ParseAddress(from)
+	if err != nil {
+		return nil, fmt.Errorf("smtp sender %q: %w", from, err)
+	}
+
+	channel := &SMTPChannel{addr: addr, host: host, from: sender, timeout: timeout}
+	if username != "" {
+		channel.auth = smtp.PlainAuth("", username, password, host)
+	}
+
+	return channel, nil
+}
+
+func (c *SMTPChannel) Name() string {
+	return domain.ReminderChannelEmail
+}
+
+func (c *SMTPChannel) Send(ctx context.Context, reminder *domain.Reminder) error {
+	if reminder.Email == "" {
+		return errors.New("user has no email for reminders")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", c.addr)
+	if err != nil {
+		return err
+	}
+
+	// net/smtp не принимает context: срок отправки ограничивается сроком соединения
+	deadline, _ := ctx.Deadline()
+	if err = conn.SetDeadline(deadline); err != nil {
+		_ = conn.Close()
+		return err
+	}
+
+	client, err := smtp.NewClient(conn, c.host)
+	if err != nil {
+		_ = conn.Close()
+		return err
+	}
+	defer func() { _ = client.Close() }()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err = client.StartTLS(&tls.Config{ServerName: c.host}); err != nil {
+			return err
+		}
+	}
+
+	if c.auth != nil {
+		if ok, _ := client.Extension("AUTH"); !ok {
+			return errors.New("smtp: server does not support AUTH")
+		}
+		if err = client.Auth(c.auth); err != nil {
+			return err
+		}
+	}
+
+	if err = client.Mail(c.from.Address); err != nil {
+		return err
+	}
+	if err = client.Rcpt(reminder.Email); err != nil {
+		return err
+	}
+
+	writer, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err = writer.Write(c.message(reminder)); err != nil {
+		return err
+	}
+	if err = writer.Close(); err != nil {
+		return err
+	}
+
+	return client.Quit()
+}
+
+// message собирает письмо; Message-ID выводится из идентификатора напоминания, чтобы почтовые
+// клиенты могли распознать повтор после сбоя
+func (c *SMTPChannel) message(reminder *domain.Reminder) []byte {
+	subject, body := Message(reminder)
+
+	var msg strings.Builder
+	fmt.Fprintf(&msg, "From: %s\r\n", c.from)
+	fmt.Fprintf(&msg, "To: %s\r\n", reminder.Email)
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&msg, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(&msg, "Message-ID: <%s@%s>\r\n", reminder.Id, c.host)
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	msg.WriteString("Content-Transfer-Encoding: 8bit\r\n")
+	msg.WriteString("\r\n")
+	msg.WriteString(strings.ReplaceAll(body, "\n", "\r\n"))
+
+	return []byte(msg.String())
+}
@@ -0,0 +1,31 @@
+package notify
+
+import (
+	"context"
+	"time"
+
+	"github.com/Aiszhio/Task/internal/domain"
+)
+
+// publisher — приёмник событий, например usecase.WebhookUseCase
+type publisher interface {
+	Publish(ctx context.Context, event *domain.WebhookEvent) error
+}
+
+// WebhookChannel отправляет напоминания событием subscription.reminder вебхукам, подписанным на него.
+// Напоминание считается отправленным, когда доставки заведены; повторы доставок ведут сами вебхуки
+type WebhookChannel struct {
+	events publisher
+}
+
+func NewWebhookChannel(events publisher) *WebhookChannel {
+	return &WebhookChannel{events: events}
+}
+
+func (c *WebhookChannel) Name() string {
+	return domain.ReminderChannelWebhook
+}
+
+func (c *WebhookChannel) Send(ctx context.Context, reminder *domain.Reminder) error {
+	return c.events.Publish(ctx, domain.ReminderEvent(reminder, time.Now().UTC()))
+}
@@ -0,0 +1,60 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/Aiszhio/Task/internal/domain"
+	"github.com/nats-io/nats.go"
+)
+
+// NATSSink публикует события в субъекты <prefix>.<тип события>, например
+// subscriptions.subscription.created. Заголовок Nats-Msg-Id равен идентификатору события,
+// поэтому поток JetStream отбросит повторную публикацию в пределах окна дедупликации
+type NATSSink struct {
+	conn    *nats.Conn
+	prefix  string
+	timeout time.Duration
+}
+
+// NewNATSSink подключается к серверу NATS; timeout ограничивает подтверждение каждой публикации
+func NewNATSSink(url, prefix string, timeout time.Duration) (*NATSSink, error) {
+	conn, err := nats.Connect(url,
+		nats.Name("subscriptions-api"),
+		nats.Timeout(timeout),
+		nats.MaxReconnects(-1),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &NATSSink{conn: conn, prefix: prefix, timeout: timeout}, nil
+}
+
+// Publish считается успешным, только когда сервер подтвердил получение: без этого сообщение
+// могло остаться в буфере клиента, а outbox уже отметил бы его опубликованным
+func (s *NATSSink) Publish(ctx context.Context, event *domain.WebhookEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	msg := nats.NewMsg(s.prefix + "." + string(event.Type))
+	msg.Header.Set(nats.MsgIdHdr, event.Id.String())
+	msg.Data = data
+
+	if err = s.conn.PublishMsg(msg); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	return s.conn.FlushWithContext(ctx)
+}
+
+// Close отправляет то, что осталось в буфере, и закрывает соединение
+func (s *NATSSink) Close() error {
+	return s.conn.Drain()
+}
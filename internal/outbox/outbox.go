@@ -0,0 +1,36 @@
+// Package outbox содержит приёмники, в которые worker.RunOutboxRelay публикует события из outbox:
+// вебхуки, поток вывода и NATS
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"sync"
+
+	"github.com/Aiszhio/Task/internal/domain"
+)
+
+// Приёмники событий outbox
+const (
+	SinkWebhook = "webhook"
+	SinkStdout  = "stdout"
+	SinkNATS    = "nats"
+)
+
+// WriterSink пишет каждое событие строкой JSON; подходит для отладки и сбора логов
+type WriterSink struct {
+	mu      sync.Mutex
+	encoder *json.Encoder
+}
+
+func NewWriterSink(w io.Writer) *WriterSink {
+	return &WriterSink{encoder: json.NewEncoder(w)}
+}
+
+func (s *WriterSink) Publish(_ context.Context, event *domain.WebhookEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.encoder.Encode(event)
+}
@@ -0,0 +1,74 @@
+package outbox
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Aiszhio/Task/internal/domain"
+	"github.com/google/uuid"
+)
+
+func TestWriterSink(t *testing.T) {
+	var buf bytes.Buffer
+	sink := NewWriterSink(&buf)
+
+	events := make(map[uuid.UUID]bool)
+	var wg sync.WaitGroup
+	for range 20 {
+		event := &domain.WebhookEvent{Id: uuid.New(), Type: domain.WebhookSubscriptionCreated,
+			OccurredAt: time.Now().UTC()}
+		events[event.Id] = true
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := sink.Publish(context.Background(), event); err != nil {
+				t.Errorf("Publish: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// параллельные публикации не перемешиваются: каждая строка — целое событие
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		var event domain.WebhookEvent
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			t.Fatalf("line %q is not an event: %v", scanner.Text(), err)
+		}
+		if !events[event.Id] {
+			t.Fatalf("line %q repeats or invents an event", scanner.Text())
+		}
+		delete(events, event.Id)
+	}
+
+	if len(events) != 0 {
+		t.Errorf("%d events were not written", len(events))
+	}
+}
+
+func TestNewNATSSinkWithoutServer(t *testing.T) {
+	// свободный порт, на котором никто не слушает
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen: %v", err)
+	}
+	addr := listener.Addr().String()
+	listener.Close()
+
+	started := time.Now()
+	sink, err := NewNATSSink("nats://"+addr, "subscriptions", 200*time.Millisecond)
+	if err == nil {
+		sink.Close()
+		t.Fatal("NewNATSSink connected without a server, want an error")
+	}
+	if elapsed := time.Since(started); elapsed > 5*time.Second {
+		t.Errorf("NewNATSSink failed after %v, want it not to hang", elapsed)
+	}
+}
@@ -0,0 +1,56 @@
+package instrumented
+
+import (
+	"context"
+	"time"
+
+	"github.com/Aiszhio/Task/internal/domain"
+	"github.com/Aiszhio/Task/internal/metrics"
+	"github.com/Aiszhio/Task/internal/repository/postgres"
+	"github.com/google/uuid"
+)
+
+var _ postgres.APIKeyRepository = (*APIKeyRepository)(nil)
+
+type APIKeyRepository struct {
+	observer
+	next postgres.APIKeyRepository
+}
+
+func NewAPIKeyRepository(next postgres.APIKeyRepository, m *metrics.Metrics) *APIKeyRepository {
+	return &APIKeyRepository{
+		observer: observer{metrics: m, repository: "api_keys"},
+		next:     next,
+	}
+}
+
+func (r *APIKeyRepository) CreateAPIKey(ctx context.Context, key *domain.APIKey) (err error) {
+	defer r.observe("CreateAPIKey", time.Now(), &err)
+	return r.next.CreateAPIKey(ctx, key)
+}
+
+func (r *APIKeyRepository) ListAPIKeys(ctx context.Context) (keys []domain.APIKey, err error) {
+	defer r.observe("ListAPIKeys", time.Now(), &err)
+	return r.next.ListAPIKeys(ctx)
+}
+
+func (r *APIKeyRepository) FindAPIKey(ctx context.Context, hash string) (key *domain.APIKey, err error) {
+	defer r.observe("FindAPIKey", time.Now(), &err)
+	return r.next.FindAPIKey(ctx, hash)
+}
+
+func (r *APIKeyRepository) RevokeAPIKey(ctx context.Context, id uuid.UUID) (key *domain.APIKey, err error) {
+	defer r.observe("RevokeAPIKey", time.Now(), &err)
+	return r.next.RevokeAPIKey(ctx, id)
+}
+
+func (r *APIKeyRepository) RotateAPIKey(ctx context.Context, id uuid.UUID, replacement *domain.APIKey) (err error) {
+	defer r.observe("RotateAPIKey", time.Now(), &err)
+	return r.next.RotateAPIKey(ctx, id, replacement)
+}
+
+func (r *APIKeyRepository) TouchAPIKey(ctx context.Context, id uuid.UUID, at time.Time,
+	precision time.Duration) (err error) {
+	defer r.observe("TouchAPIKey", time.Now(), &err)
+	return r.next.TouchAPIKey(ctx, id, at, precision)
+}
@@ -0,0 +1,45 @@
+package instrumented
+
+import (
+	"context"
+	"time"
+
+	"github.com/Aiszhio/Task/internal/domain"
+	"github.com/Aiszhio/Task/internal/metrics"
+	"github.com/Aiszhio/Task/internal/repository/postgres"
+)
+
+var _ postgres.IdempotencyRepository = (*IdempotencyRepository)(nil)
+
+type IdempotencyRepository struct {
+	observer
+	next postgres.IdempotencyRepository
+}
+
+func NewIdempotencyRepository(next postgres.IdempotencyRepository, m *metrics.Metrics) *IdempotencyRepository {
+	return &IdempotencyRepository{
+		observer: observer{metrics: m, repository: "idempotency_keys"},
+		next:     next,
+	}
+}
+
+func (r *IdempotencyRepository) ClaimKey(ctx context.Context, key, requestHash string,
+	ttl, lease time.Duration) (req *domain.IdempotentRequest, claimed bool, err error) {
+	defer r.observe("ClaimKey", time.Now(), &err)
+	return r.next.ClaimKey(ctx, key, requestHash, ttl, lease)
+}
+
+func (r *IdempotencyRepository) SaveResponse(ctx context.Context, req *domain.IdempotentRequest) (err error) {
+	defer r.observe("SaveResponse", time.Now(), &err)
+	return r.next.SaveResponse(ctx, req)
+}
+
+func (r *IdempotencyRepository) ReleaseKey(ctx context.Context, req *domain.IdempotentRequest) (err error) {
+	defer r.observe("ReleaseKey", time.Now(), &err)
+	return r.next.ReleaseKey(ctx, req)
+}
+
+func (r *IdempotencyRepository) DeleteExpiredKeys(ctx context.Context) (deleted int64, err error) {
+	defer r.observe("DeleteExpiredKeys", time.Now(), &err)
+	return r.next.DeleteExpiredKeys(ctx)
+}
@@ -0,0 +1,26 @@
+// Package instrumented оборачивает репозитории и записывает длительность каждой операции в метрики
+package instrumented
+
+import (
+	"errors"
+	"time"
+
+	errors_package "github.com/Aiszhio/Task/internal/errors"
+	"github.com/Aiszhio/Task/internal/metrics"
+)
+
+type observer struct {
+	metrics    *metrics.Metrics
+	repository string
+}
+
+// observe вызывается через defer; ошибки предметной области (не найдено, конфликт) операцию
+// не проваливают, сбоем считается только ErrInternal
+func (o observer) observe(operation string, start time.Time, err *error) {
+	outcome := "ok"
+	if errors.Is(*err, errors_package.ErrInternal) {
+		outcome = "error"
+	}
+
+	o.metrics.ObserveOperation(o.repository, operation, outcome, time.Since(start))
+}
@@ -0,0 +1,40 @@
+package instrumented
+
+import (
+	"context"
+	"time"
+
+	"github.com/Aiszhio/Task/internal/domain"
+	"github.com/Aiszhio/Task/internal/metrics"
+	"github.com/Aiszhio/Task/internal/repository/postgres"
+)
+
+var _ postgres.OutboxRepository = (*OutboxRepository)(nil)
+
+type OutboxRepository struct {
+	observer
+	next postgres.OutboxRepository
+}
+
+func NewOutboxRepository(next postgres.OutboxRepository, m *metrics.Metrics) *OutboxRepository {
+	return &OutboxRepository{
+		observer: observer{metrics: m, repository: "outbox"},
+		next:     next,
+	}
+}
+
+func (r *OutboxRepository) EnqueueMessage(ctx context.Context, event *domain.WebhookEvent) (added bool, err error) {
+	defer r.observe("EnqueueMessage", time.Now(), &err)
+	return r.next.EnqueueMessage(ctx, event)
+}
+
+func (r *OutboxRepository) RelayMessages(ctx context.Context, now time.Time, limit int, retry domain.RetryPolicy,
+	publish func(ctx context.Context, msg *domain.OutboxMessage) error) (relayed int, err error) {
+	defer r.observe("RelayMessages", time.Now(), &err)
+	return r.next.RelayMessages(ctx, now, limit, retry, publish)
+}
+
+func (r *OutboxRepository) DeletePublished(ctx context.Context, olderThan time.Duration) (deleted int64, err error) {
+	defer r.observe("DeletePublished", time.Now(), &err)
+	return r.next.DeletePublished(ctx, olderThan)
+}
@@ -0,0 +1,35 @@
+package instrumented
+
+import (
+	"context"
+	"time"
+
+	"github.com/Aiszhio/Task/internal/domain"
+	"github.com/Aiszhio/Task/internal/metrics"
+	"github.com/Aiszhio/Task/internal/repository/postgres"
+)
+
+var _ postgres.RateLimitRepository = (*RateLimitRepository)(nil)
+
+type RateLimitRepository struct {
+	observer
+	next postgres.RateLimitRepository
+}
+
+func NewRateLimitRepository(next postgres.RateLimitRepository, m *metrics.Metrics) *RateLimitRepository {
+	return &RateLimitRepository{
+		observer: observer{metrics: m, repository: "rate_limit_buckets"},
+		next:     next,
+	}
+}
+
+func (r *RateLimitRepository) TakeToken(ctx context.Context, key string,
+	limit domain.RateLimit) (decision *domain.RateDecision, err error) {
+	defer r.observe("TakeToken", time.Now(), &err)
+	return r.next.TakeToken(ctx, key, limit)
+}
+
+func (r *RateLimitRepository) DeleteIdleBuckets(ctx context.Context, idle time.Duration) (deleted int64, err error) {
+	defer r.observe("DeleteIdleBuckets", time.Now(), &err)
+	return r.next.DeleteIdleBuckets(ctx, idle)
+}
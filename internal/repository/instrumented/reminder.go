@@ -0,0 +1,63 @@
+package instrumented
+
+import (
+	"context"
+	"time"
+
+	"github.com/Aiszhio/Task/internal/domain"
+	"github.com/Aiszhio/Task/internal/metrics"
+	"github.com/Aiszhio/Task/internal/repository/postgres"
+	"github.com/google/uuid"
+)
+
+var _ postgres.ReminderRepository = (*ReminderRepository)(nil)
+
+type ReminderRepository struct {
+	observer
+	next postgres.ReminderRepository
+}
+
+func NewReminderRepository(next postgres.ReminderRepository, m *metrics.Metrics) *ReminderRepository {
+	return &ReminderRepository{
+		observer: observer{metrics: m, repository: "reminders"},
+		next:     next,
+	}
+}
+
+func (r *ReminderRepository) ClaimReminder(ctx context.Context, reminder *domain.Reminder, now time.Time,
+	lease time.Duration) (claimed bool, err error) {
+	defer r.observe("ClaimReminder", time.Now(), &err)
+	return r.next.ClaimReminder(ctx, reminder, now, lease)
+}
+
+func (r *ReminderRepository) SaveReminder(ctx context.Context, reminder *domain.Reminder) (err error) {
+	defer r.observe("SaveReminder", time.Now(), &err)
+	return r.next.SaveReminder(ctx, reminder)
+}
+
+func (r *ReminderRepository) ListReminders(ctx context.Context, userId uuid.UUID,
+	limit int) (reminders []domain.Reminder, err error) {
+	defer r.observe("ListReminders", time.Now(), &err)
+	return r.next.ListReminders(ctx, userId, limit)
+}
+
+func (r *ReminderRepository) DeleteReminders(ctx context.Context, olderThan time.Duration) (deleted int64, err error) {
+	defer r.observe("DeleteReminders", time.Now(), &err)
+	return r.next.DeleteReminders(ctx, olderThan)
+}
+
+func (r *ReminderRepository) SaveContact(ctx context.Context, contact *domain.ReminderContact) (err error) {
+	defer r.observe("SaveContact", time.Now(), &err)
+	return r.next.SaveContact(ctx, contact)
+}
+
+func (r *ReminderRepository) ReadContact(ctx context.Context,
+	userId uuid.UUID) (contact *domain.ReminderContact, err error) {
+	defer r.observe("ReadContact", time.Now(), &err)
+	return r.next.ReadContact(ctx, userId)
+}
+
+func (r *ReminderRepository) DeleteContact(ctx context.Context, userId uuid.UUID) (err error) {
+	defer r.observe("DeleteContact", time.Now(), &err)
+	return r.next.DeleteContact(ctx, userId)
+}
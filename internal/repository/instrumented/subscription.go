@@ -0,0 +1,95 @@
+package instrumented
+
+import (
+	"context"
+	"time"
+
+	"github.com/Aiszhio/Task/internal/domain"
+	"github.com/Aiszhio/Task/internal/metrics"
+	"github.com/Aiszhio/Task/internal/repository/postgres"
+	"github.com/google/uuid"
+)
+
+var _ postgres.SubscriptionRepository = (*SubscriptionRepository)(nil)
+
+type SubscriptionRepository struct {
+	observer
+	next postgres.SubscriptionRepository
+}
+
+func NewSubscriptionRepository(next postgres.SubscriptionRepository, m *metrics.Metrics) *SubscriptionRepository {
+	return &SubscriptionRepository{
+		observer: observer{metrics: m, repository: "subscriptions"},
+		next:     next,
+	}
+}
+
+func (r *SubscriptionRepository) CreateSubscription(ctx context.Context, sub *domain.Subscription) (err error) {
+	defer r.observe("CreateSubscription", time.Now(), &err)
+	return r.next.CreateSubscription(ctx, sub)
+}
+
+func (r *SubscriptionRepository) CreateSubscriptions(ctx context.Context, subs []*domain.Subscription,
+	dryRun bool) (err error) {
+	defer r.observe("CreateSubscriptions", time.Now(), &err)
+	return r.next.CreateSubscriptions(ctx, subs, dryRun)
+}
+
+func (r *SubscriptionRepository) ReadSubscription(ctx context.Context,
+	id uuid.UUID) (sub *domain.Subscription, err error) {
+	defer r.observe("ReadSubscription", time.Now(), &err)
+	return r.next.ReadSubscription(ctx, id)
+}
+
+func (r *SubscriptionRepository) DeleteSubscription(ctx context.Context, id uuid.UUID) (err error) {
+	defer r.observe("DeleteSubscription", time.Now(), &err)
+	return r.next.DeleteSubscription(ctx, id)
+}
+
+func (r *SubscriptionRepository) UpdateSubscription(ctx context.Context, sub *domain.Subscription) (err error) {
+	defer r.observe("UpdateSubscription", time.Now(), &err)
+	return r.next.UpdateSubscription(ctx, sub)
+}
+
+func (r *SubscriptionRepository) ListSubscriptions(ctx context.Context, startDate, endDate time.Time,
+	userId uuid.UUID, serviceName string) (subs []domain.Subscription, err error) {
+	defer r.observe("ListSubscriptions", time.Now(), &err)
+	return r.next.ListSubscriptions(ctx, startDate, endDate, userId, serviceName)
+}
+
+func (r *SubscriptionRepository) FindSubscriptions(ctx context.Context,
+	filter *domain.SubscriptionFilter) (page *domain.SubscriptionPage, err error) {
+	defer r.observe("FindSubscriptions", time.Now(), &err)
+	return r.next.FindSubscriptions(ctx, filter)
+}
+
+func (r *SubscriptionRepository) ReadDeletedSubscription(ctx context.Context,
+	id uuid.UUID) (sub *domain.Subscription, err error) {
+	defer r.observe("ReadDeletedSubscription", time.Now(), &err)
+	return r.next.ReadDeletedSubscription(ctx, id)
+}
+
+func (r *SubscriptionRepository) RestoreSubscription(ctx context.Context,
+	id uuid.UUID) (sub *domain.Subscription, err error) {
+	defer r.observe("RestoreSubscription", time.Now(), &err)
+	return r.next.RestoreSubscription(ctx, id)
+}
+
+func (r *SubscriptionRepository) PurgeSubscriptions(ctx context.Context,
+	retention time.Duration) (purged int64, err error) {
+	defer r.observe("PurgeSubscriptions", time.Now(), &err)
+	return r.next.PurgeSubscriptions(ctx, retention)
+}
+
+func (r *SubscriptionRepository) ListSubscriptionEvents(ctx context.Context,
+	id uuid.UUID) (events []domain.SubscriptionEvent, err error) {
+	defer r.observe("ListSubscriptionEvents", time.Now(), &err)
+	return r.next.ListSubscriptionEvents(ctx, id)
+}
+
+// StreamSubscriptions измеряется целиком, вместе с обработкой строк в fn
+func (r *SubscriptionRepository) StreamSubscriptions(ctx context.Context, filter *domain.SubscriptionFilter,
+	fn func(sub *domain.Subscription) error) (err error) {
+	defer r.observe("StreamSubscriptions", time.Now(), &err)
+	return r.next.StreamSubscriptions(ctx, filter, fn)
+}
@@ -0,0 +1,68 @@
+package instrumented
+
+import (
+	"context"
+	"time"
+
+	"github.com/Aiszhio/Task/internal/domain"
+	"github.com/Aiszhio/Task/internal/metrics"
+	"github.com/Aiszhio/Task/internal/repository/postgres"
+	"github.com/google/uuid"
+)
+
+var _ postgres.WebhookRepository = (*WebhookRepository)(nil)
+
+type WebhookRepository struct {
+	observer
+	next postgres.WebhookRepository
+}
+
+func NewWebhookRepository(next postgres.WebhookRepository, m *metrics.Metrics) *WebhookRepository {
+	return &WebhookRepository{
+		observer: observer{metrics: m, repository: "webhooks"},
+		next:     next,
+	}
+}
+
+func (r *WebhookRepository) CreateWebhook(ctx context.Context, webhook *domain.Webhook) (err error) {
+	defer r.observe("CreateWebhook", time.Now(), &err)
+	return r.next.CreateWebhook(ctx, webhook)
+}
+
+func (r *WebhookRepository) ListWebhooks(ctx context.Context) (webhooks []domain.Webhook, err error) {
+	defer r.observe("ListWebhooks", time.Now(), &err)
+	return r.next.ListWebhooks(ctx)
+}
+
+func (r *WebhookRepository) ReadWebhook(ctx context.Context, id uuid.UUID) (webhook *domain.Webhook, err error) {
+	defer r.observe("ReadWebhook", time.Now(), &err)
+	return r.next.ReadWebhook(ctx, id)
+}
+
+func (r *WebhookRepository) DeleteWebhook(ctx context.Context, id uuid.UUID) (err error) {
+	defer r.observe("DeleteWebhook", time.Now(), &err)
+	return r.next.DeleteWebhook(ctx, id)
+}
+
+func (r *WebhookRepository) EnqueueDeliveries(ctx context.Context, event *domain.WebhookEvent,
+	payload []byte) (enqueued int64, err error) {
+	defer r.observe("EnqueueDeliveries", time.Now(), &err)
+	return r.next.EnqueueDeliveries(ctx, event, payload)
+}
+
+func (r *WebhookRepository) ClaimDeliveries(ctx context.Context, now time.Time, lease time.Duration,
+	limit int) (jobs []domain.WebhookJob, err error) {
+	defer r.observe("ClaimDeliveries", time.Now(), &err)
+	return r.next.ClaimDeliveries(ctx, now, lease, limit)
+}
+
+func (r *WebhookRepository) SaveDelivery(ctx context.Context, delivery *domain.WebhookDelivery) (err error) {
+	defer r.observe("SaveDelivery", time.Now(), &err)
+	return r.next.SaveDelivery(ctx, delivery)
+}
+
+func (r *WebhookRepository) ListDeliveries(ctx context.Context, webhookId uuid.UUID, status domain.DeliveryStatus,
+	limit int) (deliveries []domain.WebhookDelivery, err error) {
+	defer r.observe("ListDeliveries", time.Now(), &err)
+	return r.next.ListDeliveries(ctx, webhookId, status, limit)
+}
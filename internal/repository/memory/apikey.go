@@ -0,0 +1,153 @@
+package memory
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Aiszhio/Task/internal/domain"
+	errors_package "github.com/Aiszhio/Task/internal/errors"
+	"github.com/Aiszhio/Task/internal/repository/postgres"
+	"github.com/google/uuid"
+)
+
+var _ postgres.APIKeyRepository = (*MemoryAPIKeyRepository)(nil)
+
+// MemoryAPIKeyRepository хранит API-ключи в памяти процесса
+type MemoryAPIKeyRepository struct {
+	mu     sync.Mutex
+	keys   map[uuid.UUID]domain.APIKey
+	logger *slog.Logger
+}
+
+func NewAPIKeyRepository(logger *slog.Logger) *MemoryAPIKeyRepository {
+	return &MemoryAPIKeyRepository{
+		keys:   make(map[uuid.UUID]domain.APIKey),
+		logger: logger,
+	}
+}
+
+func (repo *MemoryAPIKeyRepository) CreateAPIKey(_ context.Context, key *domain.APIKey) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	return repo.insert(key)
+}
+
+func (repo *MemoryAPIKeyRepository) insert(key *domain.APIKey) error {
+	if _, ok := repo.keys[key.Id]; ok {
+		return errors_package.Conflict("api key %s already exists", key.Id)
+	}
+
+	for _, stored := range repo.keys {
+		if stored.Hash == key.Hash {
+			return errors_package.Conflict("api key %s already exists", key.Id)
+		}
+	}
+
+	key.CreatedAt = time.Now().UTC()
+	key.LastUsedAt, key.RevokedAt = nil, nil
+	key.Scopes = slices.Clone(key.Scopes)
+	repo.keys[key.Id] = *key
+
+	return nil
+}
+
+func (repo *MemoryAPIKeyRepository) ListAPIKeys(_ context.Context) ([]domain.APIKey, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	keys := make([]domain.APIKey, 0, len(repo.keys))
+	for _, key := range repo.keys {
+		keys = append(keys, cloneKey(key))
+	}
+
+	slices.SortFunc(keys, func(a, b domain.APIKey) int {
+		if c := a.CreatedAt.Compare(b.CreatedAt); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Id.String(), b.Id.String())
+	})
+
+	return keys, nil
+}
+
+func (repo *MemoryAPIKeyRepository) FindAPIKey(_ context.Context, hash string) (*domain.APIKey, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	for _, key := range repo.keys {
+		if key.Hash == hash {
+			found := cloneKey(key)
+			return &found, nil
+		}
+	}
+
+	return nil, errors_package.NotFound("api key not found")
+}
+
+func (repo *MemoryAPIKeyRepository) RevokeAPIKey(_ context.Context, id uuid.UUID) (*domain.APIKey, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	key, ok := repo.keys[id]
+	if !ok {
+		return nil, errors_package.NotFound("api key %s not found", id)
+	}
+
+	if key.RevokedAt == nil {
+		now := time.Now().UTC()
+		key.RevokedAt = &now
+		repo.keys[id] = key
+	}
+
+	revoked := cloneKey(key)
+	return &revoked, nil
+}
+
+func (repo *MemoryAPIKeyRepository) RotateAPIKey(_ context.Context, id uuid.UUID,
+	replacement *domain.APIKey) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	current, ok := repo.keys[id]
+	if !ok || current.RevokedAt != nil {
+		return errors_package.NotFound("active api key %s not found", id)
+	}
+
+	replacement.Name, replacement.Scopes = current.Name, current.Scopes
+	if err := repo.insert(replacement); err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	current.RevokedAt = &now
+	repo.keys[id] = current
+
+	return nil
+}
+
+func (repo *MemoryAPIKeyRepository) TouchAPIKey(_ context.Context, id uuid.UUID, at time.Time,
+	precision time.Duration) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	key, ok := repo.keys[id]
+	if !ok || (key.LastUsedAt != nil && !key.LastUsedAt.Before(at.Add(-precision))) {
+		return nil
+	}
+
+	at = at.UTC()
+	key.LastUsedAt = &at
+	repo.keys[id] = key
+
+	return nil
+}
+
+func cloneKey(key domain.APIKey) domain.APIKey {
+	key.Scopes = slices.Clone(key.Scopes)
+	return key
+}
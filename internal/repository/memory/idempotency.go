@@ -0,0 +1,95 @@
+package memory
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/Aiszhio/Task/internal/domain"
+	"github.com/Aiszhio/Task/internal/repository/postgres"
+)
+
+var _ postgres.IdempotencyRepository = (*MemoryIdempotencyRepository)(nil)
+
+// MemoryIdempotencyRepository хранит ключи идемпотентности в памяти процесса
+type MemoryIdempotencyRepository struct {
+	mu     sync.Mutex
+	keys   map[string]domain.IdempotentRequest
+	logger *slog.Logger
+}
+
+func NewIdempotencyRepository(logger *slog.Logger) *MemoryIdempotencyRepository {
+	return &MemoryIdempotencyRepository{
+		keys:   make(map[string]domain.IdempotentRequest),
+		logger: logger,
+	}
+}
+
+func (repo *MemoryIdempotencyRepository) ClaimKey(_ context.Context, key, requestHash string,
+	ttl, lease time.Duration) (*domain.IdempotentRequest, bool, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	now := time.Now().UTC()
+	stored, ok := repo.keys[key]
+	if ok && stored.ExpiresAt.After(now) && (!stored.Abandoned(now) || stored.RequestHash != requestHash) {
+		return &stored, false, nil
+	}
+
+	claimed := domain.IdempotentRequest{
+		Key:         key,
+		RequestHash: requestHash,
+		LockedUntil: now.Add(lease),
+		ExpiresAt:   now.Add(ttl),
+	}
+	repo.keys[key] = claimed
+
+	return &claimed, true, nil
+}
+
+func (repo *MemoryIdempotencyRepository) SaveResponse(ctx context.Context, req *domain.IdempotentRequest) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	stored, ok := repo.keys[req.Key]
+	if !ok || stored.Completed() || !stored.LockedUntil.Equal(req.LockedUntil) {
+		repo.logger.WarnContext(ctx, "idempotency key was taken over before the response was saved", "key", req.Key)
+		return nil
+	}
+
+	stored.StatusCode = req.StatusCode
+	stored.ContentType = req.ContentType
+	stored.Body = append([]byte(nil), req.Body...)
+	repo.keys[req.Key] = stored
+
+	return nil
+}
+
+func (repo *MemoryIdempotencyRepository) ReleaseKey(_ context.Context, req *domain.IdempotentRequest) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	if stored, ok := repo.keys[req.Key]; ok && !stored.Completed() && stored.LockedUntil.Equal(req.LockedUntil) {
+		delete(repo.keys, req.Key)
+	}
+
+	return nil
+}
+
+func (repo *MemoryIdempotencyRepository) DeleteExpiredKeys(_ context.Context) (int64, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	now := time.Now().UTC()
+
+	var deleted int64
+	for key, stored := range repo.keys {
+		if !stored.ExpiresAt.After(now) {
+			delete(repo.keys, key)
+			deleted++
+		}
+	}
+
+	return deleted, nil
+}
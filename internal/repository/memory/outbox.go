@@ -0,0 +1,124 @@
+package memory
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/Aiszhio/Task/internal/domain"
+	"github.com/Aiszhio/Task/internal/repository/postgres"
+	"github.com/google/uuid"
+)
+
+var _ postgres.OutboxRepository = (*MemoryOutboxRepository)(nil)
+
+// MemoryOutboxRepository читает outbox, который MemorySubscriptionRepository пополняет
+// вместе с изменениями подписок
+type MemoryOutboxRepository struct {
+	subs *MemorySubscriptionRepository
+	// relaying — подписки, сообщения которых сейчас публикуются; охраняется subs.mu
+	relaying map[uuid.UUID]bool
+	logger   *slog.Logger
+}
+
+func NewOutboxRepository(subs *MemorySubscriptionRepository, logger *slog.Logger) *MemoryOutboxRepository {
+	return &MemoryOutboxRepository{
+		subs:     subs,
+		relaying: make(map[uuid.UUID]bool),
+		logger:   logger,
+	}
+}
+
+func (repo *MemoryOutboxRepository) EnqueueMessage(_ context.Context, event *domain.WebhookEvent) (bool, error) {
+	repo.subs.mu.Lock()
+	defer repo.subs.mu.Unlock()
+
+	return repo.subs.enqueue(event), nil
+}
+
+// RelayMessages публикует сообщения без блокировки подписок, чтобы медленный получатель
+// не задерживал запросы. Взятые сообщения пропускаются параллельными вызовами, как строки
+// под FOR UPDATE SKIP LOCKED, поэтому одно событие не публикуется дважды
+func (repo *MemoryOutboxRepository) RelayMessages(ctx context.Context, now time.Time, limit int,
+	retry domain.RetryPolicy, publish func(ctx context.Context, msg *domain.OutboxMessage) error) (int, error) {
+	messages := repo.due(now, limit)
+
+	for i := range messages {
+		msg := &messages[i]
+
+		var publishedAt *time.Time
+		if err := publish(ctx, msg); err != nil {
+			msg.Fail(time.Now().UTC(), err, retry)
+		} else {
+			at := time.Now().UTC()
+			msg.Attempts++
+			msg.LastError = ""
+			publishedAt = &at
+		}
+		msg.PublishedAt = publishedAt
+
+		repo.save(msg)
+	}
+
+	return len(messages), nil
+}
+
+// due берёт первые неопубликованные сообщения подписок, срок которых наступил, пропуская
+// подписки, которые уже публикует другой вызов
+func (repo *MemoryOutboxRepository) due(now time.Time, limit int) []domain.OutboxMessage {
+	repo.subs.mu.Lock()
+	defer repo.subs.mu.Unlock()
+
+	var messages []domain.OutboxMessage
+	waiting := make(map[uuid.UUID]bool)
+
+	for _, msg := range repo.subs.outbox {
+		if len(messages) == limit {
+			break
+		}
+		if msg.PublishedAt != nil || msg.FailedAt != nil || waiting[msg.SubscriptionId] {
+			continue
+		}
+
+		waiting[msg.SubscriptionId] = true
+		if !msg.NextAttemptAt.After(now) && !repo.relaying[msg.SubscriptionId] {
+			repo.relaying[msg.SubscriptionId] = true
+			messages = append(messages, msg)
+		}
+	}
+
+	return messages
+}
+
+func (repo *MemoryOutboxRepository) save(msg *domain.OutboxMessage) {
+	repo.subs.mu.Lock()
+	defer repo.subs.mu.Unlock()
+
+	delete(repo.relaying, msg.SubscriptionId)
+
+	for i := range repo.subs.outbox {
+		if repo.subs.outbox[i].Id == msg.Id {
+			repo.subs.outbox[i] = *msg
+			return
+		}
+	}
+}
+
+func (repo *MemoryOutboxRepository) DeletePublished(_ context.Context, olderThan time.Duration) (int64, error) {
+	repo.subs.mu.Lock()
+	defer repo.subs.mu.Unlock()
+
+	threshold := time.Now().UTC().Add(-olderThan)
+
+	kept := repo.subs.outbox[:0]
+	for _, msg := range repo.subs.outbox {
+		if msg.PublishedAt == nil || !msg.PublishedAt.Before(threshold) {
+			kept = append(kept, msg)
+		}
+	}
+
+	deleted := int64(len(repo.subs.outbox) - len(kept))
+	repo.subs.outbox = kept
+
+	return deleted, nil
+}
@@ -0,0 +1,62 @@
+package memory
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/Aiszhio/Task/internal/domain"
+	"github.com/Aiszhio/Task/internal/repository/postgres"
+)
+
+var _ postgres.RateLimitRepository = (*MemoryRateLimitRepository)(nil)
+
+// MemoryRateLimitRepository хранит вёдра в памяти процесса: у каждой реплики свой лимит
+type MemoryRateLimitRepository struct {
+	mu      sync.Mutex
+	buckets map[string]domain.RateBucket
+	logger  *slog.Logger
+}
+
+func NewRateLimitRepository(logger *slog.Logger) *MemoryRateLimitRepository {
+	return &MemoryRateLimitRepository{
+		buckets: make(map[string]domain.RateBucket),
+		logger:  logger,
+	}
+}
+
+func (repo *MemoryRateLimitRepository) TakeToken(_ context.Context, key string,
+	limit domain.RateLimit) (*domain.RateDecision, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	now := time.Now().UTC()
+
+	bucket, ok := repo.buckets[key]
+	if !ok {
+		bucket = domain.NewRateBucket(limit, now)
+	}
+
+	decision := bucket.Take(limit, now)
+	repo.buckets[key] = bucket
+
+	return &decision, nil
+}
+
+func (repo *MemoryRateLimitRepository) DeleteIdleBuckets(_ context.Context, idle time.Duration) (int64, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	threshold := time.Now().UTC().Add(-idle)
+
+	var deleted int64
+	for key, bucket := range repo.buckets {
+		if bucket.UpdatedAt.Before(threshold) {
+			delete(repo.buckets, key)
+			deleted++
+		}
+	}
+
+	return deleted, nil
+}
@@ -0,0 +1,155 @@
+package memory
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Aiszhio/Task/internal/domain"
+	errors_package "github.com/Aiszhio/Task/internal/errors"
+	"github.com/Aiszhio/Task/internal/repository/postgres"
+	"github.com/google/uuid"
+)
+
+var _ postgres.ReminderRepository = (*MemoryReminderRepository)(nil)
+
+// MemoryReminderRepository хранит напоминания и адреса пользователей в памяти процесса
+type MemoryReminderRepository struct {
+	mu        sync.Mutex
+	reminders map[uuid.UUID]domain.Reminder
+	contacts  map[uuid.UUID]domain.ReminderContact
+	logger    *slog.Logger
+}
+
+func NewReminderRepository(logger *slog.Logger) *MemoryReminderRepository {
+	return &MemoryReminderRepository{
+		reminders: make(map[uuid.UUID]domain.Reminder),
+		contacts:  make(map[uuid.UUID]domain.ReminderContact),
+		logger:    logger,
+	}
+}
+
+func (repo *MemoryReminderRepository) ClaimReminder(_ context.Context, reminder *domain.Reminder, now time.Time,
+	lease time.Duration) (bool, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	leased := now.Add(lease)
+
+	stored, ok := repo.reminders[reminder.Id]
+	if !ok {
+		stored = *reminder
+		stored.Status = domain.DeliveryPending
+		stored.Attempts = 0
+		stored.LastError = ""
+		stored.CreatedAt = time.Now().UTC()
+		stored.SentAt = nil
+	} else if stored.Status != domain.DeliveryPending || stored.NextAttemptAt.After(now) {
+		return false, nil
+	}
+
+	stored.NextAttemptAt = &leased
+	stored.Subscription, stored.Email = domain.Subscription{}, ""
+	repo.reminders[stored.Id] = stored
+
+	reminder.Status, reminder.Attempts, reminder.CreatedAt = stored.Status, stored.Attempts, stored.CreatedAt
+	reminder.NextAttemptAt = &leased
+
+	return true, nil
+}
+
+func (repo *MemoryReminderRepository) SaveReminder(_ context.Context, reminder *domain.Reminder) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	stored, ok := repo.reminders[reminder.Id]
+	if !ok {
+		// напоминание удалили очисткой, пока шла попытка
+		return nil
+	}
+
+	stored.Status, stored.Attempts = reminder.Status, reminder.Attempts
+	stored.NextAttemptAt, stored.LastError = reminder.NextAttemptAt, reminder.LastError
+	stored.SentAt = reminder.SentAt
+	repo.reminders[reminder.Id] = stored
+
+	return nil
+}
+
+func (repo *MemoryReminderRepository) ListReminders(_ context.Context, userId uuid.UUID,
+	limit int) ([]domain.Reminder, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	reminders := []domain.Reminder{}
+	for _, reminder := range repo.reminders {
+		if userId == uuid.Nil || reminder.UserId == userId {
+			reminders = append(reminders, reminder)
+		}
+	}
+
+	slices.SortFunc(reminders, func(a, b domain.Reminder) int {
+		if c := b.CreatedAt.Compare(a.CreatedAt); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Id.String(), b.Id.String())
+	})
+
+	return reminders[:min(limit, len(reminders))], nil
+}
+
+func (repo *MemoryReminderRepository) DeleteReminders(_ context.Context, olderThan time.Duration) (int64, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	cutoff := time.Now().Add(-olderThan)
+
+	var deleted int64
+	for id, reminder := range repo.reminders {
+		if reminder.DueAt.Before(cutoff) {
+			delete(repo.reminders, id)
+			deleted++
+		}
+	}
+
+	return deleted, nil
+}
+
+func (repo *MemoryReminderRepository) SaveContact(_ context.Context, contact *domain.ReminderContact) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	contact.UpdatedAt = time.Now().UTC()
+	repo.contacts[contact.UserId] = *contact
+
+	return nil
+}
+
+func (repo *MemoryReminderRepository) ReadContact(_ context.Context,
+	userId uuid.UUID) (*domain.ReminderContact, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	contact, ok := repo.contacts[userId]
+	if !ok {
+		return nil, errors_package.NotFound("reminder contact of user %s not found", userId)
+	}
+
+	return &contact, nil
+}
+
+func (repo *MemoryReminderRepository) DeleteContact(_ context.Context, userId uuid.UUID) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	if _, ok := repo.contacts[userId]; !ok {
+		return errors_package.NotFound("reminder contact of user %s not found", userId)
+	}
+
+	delete(repo.contacts, userId)
+
+	return nil
+}
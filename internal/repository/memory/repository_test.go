@@ -0,0 +1,26 @@
+package memory_test
+
+import (
+	"log/slog"
+	"testing"
+
+	"github.com/Aiszhio/Task/internal/repository/memory"
+	"github.com/Aiszhio/Task/internal/repository/repotest"
+)
+
+func TestRepositories(t *testing.T) {
+	repotest.Run(t, func(t *testing.T) repotest.Stores {
+		logger := slog.New(slog.DiscardHandler)
+		subs := memory.NewRepository(logger)
+
+		return repotest.Stores{
+			Subscriptions: subs,
+			Outbox:        memory.NewOutboxRepository(subs, logger),
+			Idempotency:   memory.NewIdempotencyRepository(logger),
+			APIKeys:       memory.NewAPIKeyRepository(logger),
+			RateLimits:    memory.NewRateLimitRepository(logger),
+			Webhooks:      memory.NewWebhookRepository(logger),
+			Reminders:     memory.NewReminderRepository(logger),
+		}
+	})
+}
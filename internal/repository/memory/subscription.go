@@ -0,0 +1,518 @@
+package memory
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Aiszhio/Task/internal/domain"
+	errors_package "github.com/Aiszhio/Task/internal/errors"
+	"github.com/Aiszhio/Task/internal/repository/postgres"
+	"github.com/Aiszhio/Task/internal/requestmeta"
+	"github.com/google/uuid"
+)
+
+var _ postgres.SubscriptionRepository = (*MemorySubscriptionRepository)(nil)
+
+// MemorySubscriptionRepository хранит подписки в памяти процесса
+// и повторяет поведение PGSubscriptionRepository; предназначен для тестов и демо
+type MemorySubscriptionRepository struct {
+	mu            sync.RWMutex
+	subscriptions map[uuid.UUID]domain.Subscription
+	events        []domain.SubscriptionEvent
+	outbox        []domain.OutboxMessage
+	outboxSeq     int64
+	logger        *slog.Logger
+}
+
+func NewRepository(logger *slog.Logger) *MemorySubscriptionRepository {
+	return &MemorySubscriptionRepository{
+		subscriptions: make(map[uuid.UUID]domain.Subscription),
+		logger:        logger,
+	}
+}
+
+func (repo *MemorySubscriptionRepository) CreateSubscription(ctx context.Context, sub *domain.Subscription) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	if err := repo.insert(ctx, sub); err != nil {
+		return err
+	}
+
+	repo.logger.Info("successfully created subscription")
+	return nil
+}
+
+func (repo *MemorySubscriptionRepository) CreateSubscriptions(ctx context.Context, subs []*domain.Subscription,
+	dryRun bool) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	recorded, queued := len(repo.events), len(repo.outbox)
+	var (
+		inserted []uuid.UUID
+		rowErrs  errors_package.RowErrors
+	)
+	for i, sub := range subs {
+		if err := repo.insert(ctx, sub); err != nil {
+			rowErrs = append(rowErrs, &errors_package.RowError{Index: i, Err: err})
+			continue
+		}
+		inserted = append(inserted, sub.Id)
+	}
+
+	if len(rowErrs) > 0 || dryRun {
+		// откат: пакет сохраняется целиком или не сохраняется вовсе
+		for _, id := range inserted {
+			delete(repo.subscriptions, id)
+		}
+		repo.events = repo.events[:recorded]
+		repo.outbox = repo.outbox[:queued]
+	}
+	if len(rowErrs) > 0 {
+		return rowErrs
+	}
+	if dryRun {
+		repo.logger.Info("subscriptions can be created", "count", len(subs))
+		return nil
+	}
+
+	repo.logger.Info("successfully created subscriptions", "count", len(subs))
+	return nil
+}
+
+// insert добавляет подписку, если её место свободно; вызывается под repo.mu
+func (repo *MemorySubscriptionRepository) insert(ctx context.Context, sub *domain.Subscription) error {
+	for _, existing := range repo.subscriptions {
+		if existing.DeletedAt == nil && sameSlot(&existing, sub) {
+			repo.logger.Info("subscription already exists",
+				"user_id", sub.UserId,
+				"service", sub.ServiceName,
+				"start_date", sub.StartDate,
+			)
+			return errors_package.Conflict("subscription already exists for user %s service %s at %s",
+				sub.UserId, sub.ServiceName, sub.StartDate.Format("2006-01-02"),
+			)
+		}
+	}
+
+	if _, ok := repo.subscriptions[sub.Id]; ok {
+		return errors_package.Conflict("subscription %s already exists", sub.Id)
+	}
+
+	now := time.Now().UTC()
+	stored := *sub
+	stored.CreatedAt = now
+	stored.UpdatedAt = now
+	stored.Version = 1
+	repo.subscriptions[sub.Id] = stored
+	repo.record(ctx, domain.EventCreated, sub.Id, nil, &stored)
+
+	sub.CreatedAt, sub.UpdatedAt, sub.Version = stored.CreatedAt, stored.UpdatedAt, stored.Version
+
+	return nil
+}
+
+func (repo *MemorySubscriptionRepository) ReadSubscription(_ context.Context,
+	id uuid.UUID) (*domain.Subscription, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	sub, ok := repo.subscriptions[id]
+	if !ok || sub.DeletedAt != nil {
+		return nil, errors_package.NotFound("subscription %s not found", id)
+	}
+
+	repo.logger.Info("successfully read subscription")
+	return &sub, nil
+}
+
+func (repo *MemorySubscriptionRepository) DeleteSubscription(ctx context.Context, id uuid.UUID) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	stored, ok := repo.subscriptions[id]
+	if !ok || stored.DeletedAt != nil {
+		return errors_package.NotFound("subscription %s not found", id)
+	}
+
+	before := stored
+	now := time.Now().UTC()
+	stored.DeletedAt = &now
+	stored.UpdatedAt = now
+	stored.Version++
+	repo.subscriptions[id] = stored
+	repo.record(ctx, domain.EventDeleted, id, &before, &stored)
+
+	repo.logger.Info("successfully deleted subscription", "id", id)
+	return nil
+}
+
+func (repo *MemorySubscriptionRepository) ReadDeletedSubscription(_ context.Context,
+	id uuid.UUID) (*domain.Subscription, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	sub, ok := repo.subscriptions[id]
+	if !ok || sub.DeletedAt == nil {
+		return nil, errors_package.NotFound("subscription %s not found in trash", id)
+	}
+
+	return clone(&sub), nil
+}
+
+func (repo *MemorySubscriptionRepository) RestoreSubscription(ctx context.Context,
+	id uuid.UUID) (*domain.Subscription, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	stored, ok := repo.subscriptions[id]
+	if !ok || stored.DeletedAt == nil {
+		return nil, errors_package.NotFound("subscription %s not found in trash", id)
+	}
+
+	for _, existing := range repo.subscriptions {
+		if existing.DeletedAt == nil && sameSlot(&existing, &stored) {
+			return nil, errors_package.Conflict(
+				"subscription %s cannot be restored: an active duplicate exists", id)
+		}
+	}
+
+	before := stored
+	stored.DeletedAt = nil
+	stored.UpdatedAt = time.Now().UTC()
+	stored.Version++
+	repo.subscriptions[id] = stored
+	repo.record(ctx, domain.EventRestored, id, &before, &stored)
+
+	repo.logger.Info("successfully restored subscription", "id", id)
+	return &stored, nil
+}
+
+func (repo *MemorySubscriptionRepository) PurgeSubscriptions(ctx context.Context,
+	retention time.Duration) (int64, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	cutoff := time.Now().UTC().Add(-retention)
+
+	var purged int64
+	for id, sub := range repo.subscriptions {
+		if sub.DeletedAt != nil && sub.DeletedAt.Before(cutoff) {
+			delete(repo.subscriptions, id)
+			repo.record(ctx, domain.EventPurged, id, &sub, nil)
+			purged++
+		}
+	}
+
+	repo.logger.Info("successfully purged subscriptions", "count", purged)
+	return purged, nil
+}
+
+func (repo *MemorySubscriptionRepository) UpdateSubscription(ctx context.Context, sub *domain.Subscription) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	stored, ok := repo.subscriptions[sub.Id]
+	if !ok || stored.DeletedAt != nil {
+		return errors_package.NotFound("subscription %s not found", sub.Id)
+	}
+
+	if sub.Version != 0 && sub.Version != stored.Version {
+		repo.logger.Info("subscription version mismatch",
+			"id", sub.Id, "expected", sub.Version, "actual", stored.Version)
+		return errors_package.PreconditionFailed("subscription %s has version %d, expected %d",
+			sub.Id, stored.Version, sub.Version)
+	}
+
+	before := stored
+	stored.ServiceName = sub.ServiceName
+	stored.Price = sub.Price
+	stored.BillingInterval = sub.BillingInterval
+	stored.IntervalCount = sub.IntervalCount
+	stored.StartDate = sub.StartDate
+	stored.EndDate = sub.EndDate
+
+	for _, existing := range repo.subscriptions {
+		if existing.Id != sub.Id && existing.DeletedAt == nil && sameSlot(&existing, &stored) {
+			return errors_package.Conflict("subscription already exists for user %s service %s at %s",
+				stored.UserId, stored.ServiceName, stored.StartDate.Format("2006-01-02"))
+		}
+	}
+
+	stored.UpdatedAt = time.Now().UTC()
+	stored.Version++
+	repo.subscriptions[sub.Id] = stored
+	repo.record(ctx, domain.EventUpdated, sub.Id, &before, &stored)
+
+	sub.UpdatedAt = stored.UpdatedAt
+	sub.Version = stored.Version
+
+	repo.logger.Info("successfully updated subscription", "id", sub.Id, "version", sub.Version)
+	return nil
+}
+
+func (repo *MemorySubscriptionRepository) ListSubscriptions(_ context.Context,
+	startDate, endDate time.Time,
+	userId uuid.UUID,
+	serviceName string) ([]domain.Subscription, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	var subscriptions []domain.Subscription
+	for _, sub := range repo.subscriptions {
+		if sub.DeletedAt != nil || sub.UserId != userId || sub.ServiceName != serviceName {
+			continue
+		}
+
+		if sub.StartDate.After(endDate) || (!sub.EndDate.IsZero() && sub.EndDate.Before(startDate)) {
+			continue
+		}
+
+		subscriptions = append(subscriptions, sub)
+	}
+
+	slices.SortFunc(subscriptions, func(a, b domain.Subscription) int {
+		return cmp.Or(a.StartDate.Compare(b.StartDate), bytes.Compare(a.Id[:], b.Id[:]))
+	})
+
+	repo.logger.Info("successfully list subscriptions")
+	return subscriptions, nil
+}
+
+func (repo *MemorySubscriptionRepository) FindSubscriptions(_ context.Context,
+	filter *domain.SubscriptionFilter) (*domain.SubscriptionPage, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	matched := repo.sorted(filter)
+
+	items := make([]domain.Subscription, 0, filter.Limit)
+	if filter.Offset < len(matched) {
+		end := min(filter.Offset+filter.Limit, len(matched))
+		items = append(items, matched[filter.Offset:end]...)
+	}
+
+	repo.logger.Info("successfully found subscriptions", "count", len(items), "total", len(matched))
+	return &domain.SubscriptionPage{
+		Items: items,
+		Total: uint64(len(matched)),
+	}, nil
+}
+
+// StreamSubscriptions передаёт в fn снимок подписок по фильтру без учёта Limit и Offset
+func (repo *MemorySubscriptionRepository) StreamSubscriptions(_ context.Context, filter *domain.SubscriptionFilter,
+	fn func(sub *domain.Subscription) error) error {
+	repo.mu.RLock()
+	matched := repo.sorted(filter)
+	repo.mu.RUnlock()
+
+	for i := range matched {
+		if err := fn(&matched[i]); err != nil {
+			return err
+		}
+	}
+
+	repo.logger.Info("successfully streamed subscriptions", "count", len(matched))
+	return nil
+}
+
+// sorted отбирает подписки по фильтру в порядке его сортировки; вызывается под repo.mu
+func (repo *MemorySubscriptionRepository) sorted(filter *domain.SubscriptionFilter) []domain.Subscription {
+	var matched []domain.Subscription
+	for _, sub := range repo.subscriptions {
+		if matches(&sub, filter) {
+			matched = append(matched, sub)
+		}
+	}
+
+	slices.SortFunc(matched, func(a, b domain.Subscription) int {
+		order := compareBy(&a, &b, filter.SortBy)
+		if filter.SortDesc {
+			order = -order
+		}
+
+		return cmp.Or(order, bytes.Compare(a.Id[:], b.Id[:]))
+	})
+
+	return matched
+}
+
+func (repo *MemorySubscriptionRepository) ListSubscriptionEvents(_ context.Context,
+	id uuid.UUID) ([]domain.SubscriptionEvent, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	var events []domain.SubscriptionEvent
+	for _, event := range repo.events {
+		if event.SubscriptionId == id {
+			events = append(events, event)
+		}
+	}
+
+	if len(events) == 0 {
+		return nil, errors_package.NotFound("subscription %s has no history", id)
+	}
+
+	repo.logger.Info("successfully listed subscription events", "id", id, "count", len(events))
+	return events, nil
+}
+
+// record добавляет запись в журнал изменений; вызывается под repo.mu
+func (repo *MemorySubscriptionRepository) record(ctx context.Context, eventType domain.EventType,
+	id uuid.UUID, before, after *domain.Subscription) {
+	meta := requestmeta.From(ctx)
+
+	repo.events = append(repo.events, domain.SubscriptionEvent{
+		Id:             int64(len(repo.events) + 1),
+		SubscriptionId: id,
+		Type:           eventType,
+		Actor:          meta.Actor,
+		RequestId:      meta.RequestID,
+		Before:         clone(before),
+		After:          clone(after),
+		CreatedAt:      time.Now().UTC(),
+	})
+
+	if event, ok := domain.OutboxEvent(eventType, before, after, time.Now().UTC()); ok {
+		repo.enqueue(event)
+	}
+}
+
+// enqueue добавляет событие в outbox, если его там ещё нет; вызывается под repo.mu
+func (repo *MemorySubscriptionRepository) enqueue(event *domain.WebhookEvent) bool {
+	for i := range repo.outbox {
+		if repo.outbox[i].Event.Id == event.Id {
+			return false
+		}
+	}
+
+	repo.outboxSeq++
+	repo.outbox = append(repo.outbox, domain.OutboxMessage{
+		Id:             repo.outboxSeq,
+		SubscriptionId: event.Subscription.Id,
+		Event:          *event,
+		NextAttemptAt:  event.OccurredAt,
+		CreatedAt:      event.OccurredAt,
+	})
+
+	return true
+}
+
+// clone копирует снимок подписки, чтобы последующие изменения не затрагивали журнал
+func clone(sub *domain.Subscription) *domain.Subscription {
+	if sub == nil {
+		return nil
+	}
+
+	copied := *sub
+	if sub.DeletedAt != nil {
+		deletedAt := *sub.DeletedAt
+		copied.DeletedAt = &deletedAt
+	}
+
+	return &copied
+}
+
+// sameSlot сообщает, что подписки совпадают по пользователю, сервису и дате начала
+func sameSlot(a, b *domain.Subscription) bool {
+	return a.UserId == b.UserId && a.ServiceName == b.ServiceName && a.StartDate.Equal(b.StartDate)
+}
+
+func matches(sub *domain.Subscription, filter *domain.SubscriptionFilter) bool {
+	if (sub.DeletedAt != nil) != filter.Deleted {
+		return false
+	}
+
+	if filter.UserID != uuid.Nil && sub.UserId != filter.UserID {
+		return false
+	}
+
+	if filter.ServiceName != "" && sub.ServiceName != filter.ServiceName {
+		return false
+	}
+
+	if filter.ActiveAt != nil {
+		if !sub.StartDate.Before(filter.ActiveAt.AddDate(0, 1, 0)) {
+			return false
+		}
+
+		if !sub.EndDate.IsZero() && sub.EndDate.Before(*filter.ActiveAt) {
+			return false
+		}
+	}
+
+	if filter.MinPrice != nil && sub.Price < *filter.MinPrice {
+		return false
+	}
+
+	if filter.MaxPrice != nil && sub.Price > *filter.MaxPrice {
+		return false
+	}
+
+	return true
+}
+
+func compareBy(a, b *domain.Subscription, field string) int {
+	switch field {
+	case "id":
+		return bytes.Compare(a.Id[:], b.Id[:])
+	case "service_name":
+		return strings.Compare(a.ServiceName, b.ServiceName)
+	case "price":
+		return cmp.Compare(a.Price, b.Price)
+	case "billing_interval":
+		return strings.Compare(string(a.BillingInterval), string(b.BillingInterval))
+	case "interval_count":
+		return cmp.Compare(a.IntervalCount, b.IntervalCount)
+	case "user_id":
+		return bytes.Compare(a.UserId[:], b.UserId[:])
+	case "start_date":
+		return a.StartDate.Compare(b.StartDate)
+	case "end_date":
+		return compareEnd(a.EndDate, b.EndDate)
+	case "updated_at":
+		return a.UpdatedAt.Compare(b.UpdatedAt)
+	case "version":
+		return cmp.Compare(a.Version, b.Version)
+	case "deleted_at":
+		return compareDeleted(a.DeletedAt, b.DeletedAt)
+	default:
+		return a.CreatedAt.Compare(b.CreatedAt)
+	}
+}
+
+// compareEnd упорядочивает даты окончания так же, как PostgreSQL: бессрочная подписка хранится
+// с NULL и идёт после любой даты
+func compareEnd(a, b time.Time) int {
+	switch {
+	case a.IsZero() && b.IsZero():
+		return 0
+	case a.IsZero():
+		return 1
+	case b.IsZero():
+		return -1
+	default:
+		return a.Compare(b)
+	}
+}
+
+// compareDeleted упорядочивает время удаления так же, как PostgreSQL: NULL больше любого значения
+func compareDeleted(a, b *time.Time) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return 1
+	case b == nil:
+		return -1
+	default:
+		return a.Compare(*b)
+	}
+}
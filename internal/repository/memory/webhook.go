@@ -0,0 +1,217 @@
+package memory
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Aiszhio/Task/internal/domain"
+	errors_package "github.com/Aiszhio/Task/internal/errors"
+	"github.com/Aiszhio/Task/internal/repository/postgres"
+	"github.com/google/uuid"
+)
+
+var _ postgres.WebhookRepository = (*MemoryWebhookRepository)(nil)
+
+// MemoryWebhookRepository хранит вебхуки и журнал доставок в памяти процесса
+type MemoryWebhookRepository struct {
+	mu         sync.Mutex
+	webhooks   map[uuid.UUID]domain.Webhook
+	deliveries map[uuid.UUID]domain.WebhookDelivery
+	logger     *slog.Logger
+}
+
+func NewWebhookRepository(logger *slog.Logger) *MemoryWebhookRepository {
+	return &MemoryWebhookRepository{
+		webhooks:   make(map[uuid.UUID]domain.Webhook),
+		deliveries: make(map[uuid.UUID]domain.WebhookDelivery),
+		logger:     logger,
+	}
+}
+
+func (repo *MemoryWebhookRepository) CreateWebhook(_ context.Context, webhook *domain.Webhook) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	if _, ok := repo.webhooks[webhook.Id]; ok {
+		return errors_package.Conflict("webhook %s already exists", webhook.Id)
+	}
+
+	webhook.CreatedAt = time.Now().UTC()
+	webhook.EventTypes = slices.Clone(webhook.EventTypes)
+	repo.webhooks[webhook.Id] = *webhook
+
+	return nil
+}
+
+func (repo *MemoryWebhookRepository) ListWebhooks(_ context.Context) ([]domain.Webhook, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	webhooks := make([]domain.Webhook, 0, len(repo.webhooks))
+	for _, webhook := range repo.webhooks {
+		webhook.EventTypes = slices.Clone(webhook.EventTypes)
+		webhooks = append(webhooks, webhook)
+	}
+
+	slices.SortFunc(webhooks, func(a, b domain.Webhook) int {
+		if c := a.CreatedAt.Compare(b.CreatedAt); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Id.String(), b.Id.String())
+	})
+
+	return webhooks, nil
+}
+
+func (repo *MemoryWebhookRepository) ReadWebhook(_ context.Context, id uuid.UUID) (*domain.Webhook, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	webhook, ok := repo.webhooks[id]
+	if !ok {
+		return nil, errors_package.NotFound("webhook %s not found", id)
+	}
+
+	webhook.EventTypes = slices.Clone(webhook.EventTypes)
+
+	return &webhook, nil
+}
+
+func (repo *MemoryWebhookRepository) DeleteWebhook(_ context.Context, id uuid.UUID) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	if _, ok := repo.webhooks[id]; !ok {
+		return errors_package.NotFound("webhook %s not found", id)
+	}
+
+	delete(repo.webhooks, id)
+	for deliveryId, delivery := range repo.deliveries {
+		if delivery.WebhookId == id {
+			delete(repo.deliveries, deliveryId)
+		}
+	}
+
+	return nil
+}
+
+func (repo *MemoryWebhookRepository) EnqueueDeliveries(_ context.Context, event *domain.WebhookEvent,
+	payload []byte) (int64, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	var enqueued int64
+	for _, webhook := range repo.webhooks {
+		if !webhook.Wants(event.Type) || repo.enqueued(webhook.Id, event.Id) {
+			continue
+		}
+
+		next := event.OccurredAt
+		delivery := domain.WebhookDelivery{
+			Id:            uuid.New(),
+			WebhookId:     webhook.Id,
+			EventId:       event.Id,
+			EventType:     event.Type,
+			Payload:       bytes.Clone(payload),
+			Status:        domain.DeliveryPending,
+			NextAttemptAt: &next,
+			CreatedAt:     time.Now().UTC(),
+		}
+		repo.deliveries[delivery.Id] = delivery
+		enqueued++
+	}
+
+	return enqueued, nil
+}
+
+func (repo *MemoryWebhookRepository) enqueued(webhookId, eventId uuid.UUID) bool {
+	for _, delivery := range repo.deliveries {
+		if delivery.WebhookId == webhookId && delivery.EventId == eventId {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (repo *MemoryWebhookRepository) ClaimDeliveries(_ context.Context, now time.Time, lease time.Duration,
+	limit int) ([]domain.WebhookJob, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	due := make([]domain.WebhookDelivery, 0)
+	for _, delivery := range repo.deliveries {
+		if delivery.Status == domain.DeliveryPending && delivery.NextAttemptAt != nil &&
+			!delivery.NextAttemptAt.After(now) {
+			due = append(due, delivery)
+		}
+	}
+
+	slices.SortFunc(due, func(a, b domain.WebhookDelivery) int {
+		return a.NextAttemptAt.Compare(*b.NextAttemptAt)
+	})
+
+	leased := now.Add(lease)
+	jobs := []domain.WebhookJob{}
+	for _, delivery := range due[:min(limit, len(due))] {
+		delivery.NextAttemptAt = &leased
+		repo.deliveries[delivery.Id] = delivery
+
+		webhook := repo.webhooks[delivery.WebhookId]
+		jobs = append(jobs, domain.WebhookJob{Delivery: cloneDelivery(delivery), URL: webhook.URL,
+			Secret: webhook.Secret})
+	}
+
+	return jobs, nil
+}
+
+func (repo *MemoryWebhookRepository) SaveDelivery(_ context.Context, delivery *domain.WebhookDelivery) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	stored, ok := repo.deliveries[delivery.Id]
+	if !ok {
+		// вебхук удалили, пока шла попытка
+		return nil
+	}
+
+	stored.Status, stored.Attempts = delivery.Status, delivery.Attempts
+	stored.NextAttemptAt, stored.LastAttemptAt = delivery.NextAttemptAt, delivery.LastAttemptAt
+	stored.ResponseStatus, stored.LastError = delivery.ResponseStatus, delivery.LastError
+	stored.DeliveredAt = delivery.DeliveredAt
+	repo.deliveries[delivery.Id] = stored
+
+	return nil
+}
+
+func (repo *MemoryWebhookRepository) ListDeliveries(_ context.Context, webhookId uuid.UUID,
+	status domain.DeliveryStatus, limit int) ([]domain.WebhookDelivery, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	deliveries := []domain.WebhookDelivery{}
+	for _, delivery := range repo.deliveries {
+		if delivery.WebhookId == webhookId && (status == "" || delivery.Status == status) {
+			deliveries = append(deliveries, cloneDelivery(delivery))
+		}
+	}
+
+	slices.SortFunc(deliveries, func(a, b domain.WebhookDelivery) int {
+		if c := b.CreatedAt.Compare(a.CreatedAt); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Id.String(), b.Id.String())
+	})
+
+	return deliveries[:min(limit, len(deliveries))], nil
+}
+
+func cloneDelivery(delivery domain.WebhookDelivery) domain.WebhookDelivery {
+	delivery.Payload = bytes.Clone(delivery.Payload)
+	return delivery
+}
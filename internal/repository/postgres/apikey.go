@@ -0,0 +1,188 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/Aiszhio/Task/internal/db"
+	"github.com/Aiszhio/Task/internal/domain"
+	errors_package "github.com/Aiszhio/Task/internal/errors"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// apiKeyColumns — порядок столбцов, который ожидает scanAPIKey
+const apiKeyColumns = `id, name, prefix, key_hash, scopes, created_at, last_used_at, revoked_at`
+
+const (
+	insertAPIKeyQuery = `INSERT INTO api_keys (id, name, prefix, key_hash, scopes)
+						VALUES ($1, $2, $3, $4, $5)
+						RETURNING ` + apiKeyColumns + `;`
+
+	selectAPIKeysQuery = `SELECT ` + apiKeyColumns + `
+						FROM api_keys
+						ORDER BY created_at, id;`
+
+	selectAPIKeyByHashQuery = `SELECT ` + apiKeyColumns + `
+						FROM api_keys
+						WHERE key_hash = $1;`
+
+	lockAPIKeyQuery = `SELECT ` + apiKeyColumns + `
+						FROM api_keys
+						WHERE id = $1
+						  AND revoked_at IS NULL
+						FOR UPDATE;`
+
+	// revokeAPIKeyQuery не меняет время отзыва уже отозванного ключа
+	revokeAPIKeyQuery = `UPDATE api_keys
+						SET revoked_at = COALESCE(revoked_at, NOW())
+						WHERE id = $1
+						RETURNING ` + apiKeyColumns + `;`
+
+	// touchAPIKeyQuery обновляет время использования не чаще, чем раз в $3 секунд
+	touchAPIKeyQuery = `UPDATE api_keys
+						SET last_used_at = $2
+						WHERE id = $1
+						  AND (last_used_at IS NULL OR last_used_at < $2 - $3 * INTERVAL '1 second');`
+)
+
+type APIKeyRepository interface {
+	CreateAPIKey(ctx context.Context, key *domain.APIKey) error
+	ListAPIKeys(ctx context.Context) ([]domain.APIKey, error)
+	// FindAPIKey ищет ключ, в том числе отозванный, по хешу
+	FindAPIKey(ctx context.Context, hash string) (*domain.APIKey, error)
+	RevokeAPIKey(ctx context.Context, id uuid.UUID) (*domain.APIKey, error)
+	// RotateAPIKey отзывает действующий ключ id и создаёт replacement с тем же именем и правами
+	RotateAPIKey(ctx context.Context, id uuid.UUID, replacement *domain.APIKey) error
+	// TouchAPIKey записывает время использования ключа, если предыдущая запись старше precision
+	TouchAPIKey(ctx context.Context, id uuid.UUID, at time.Time, precision time.Duration) error
+}
+
+type PGAPIKeyRepository struct {
+	db     *db.Pool
+	logger *slog.Logger
+}
+
+func NewAPIKeyRepository(pool *db.Pool, logger *slog.Logger) *PGAPIKeyRepository {
+	return &PGAPIKeyRepository{
+		db:     pool,
+		logger: logger,
+	}
+}
+
+func (repo *PGAPIKeyRepository) CreateAPIKey(ctx context.Context, key *domain.APIKey) error {
+	return pgx.BeginFunc(ctx, repo.db.Client, func(tx pgx.Tx) error {
+		return repo.insert(ctx, tx, key)
+	})
+}
+
+func (repo *PGAPIKeyRepository) insert(ctx context.Context, tx pgx.Tx, key *domain.APIKey) error {
+	err := scanAPIKey(tx.QueryRow(ctx, insertAPIKeyQuery, key.Id, key.Name, key.Prefix, key.Hash, key.Scopes), key)
+	if isUniqueViolation(err) {
+		return errors_package.Conflict("api key %s already exists", key.Id)
+	}
+	if err != nil {
+		repo.logger.ErrorContext(ctx, "failed to create api key", "err", err)
+		return errors_package.Internal(err)
+	}
+
+	return nil
+}
+
+func (repo *PGAPIKeyRepository) ListAPIKeys(ctx context.Context) ([]domain.APIKey, error) {
+	rows, err := repo.db.Client.Query(ctx, selectAPIKeysQuery)
+	if err != nil {
+		repo.logger.ErrorContext(ctx, "failed to list api keys", "err", err)
+		return nil, errors_package.Internal(err)
+	}
+	defer rows.Close()
+
+	keys := []domain.APIKey{}
+	for rows.Next() {
+		var key domain.APIKey
+		if err = scanAPIKey(rows, &key); err != nil {
+			repo.logger.ErrorContext(ctx, "failed to scan api key", "err", err)
+			return nil, errors_package.Internal(err)
+		}
+		keys = append(keys, key)
+	}
+
+	if err = rows.Err(); err != nil {
+		repo.logger.ErrorContext(ctx, "failed to list api keys", "err", err)
+		return nil, errors_package.Internal(err)
+	}
+
+	return keys, nil
+}
+
+func (repo *PGAPIKeyRepository) FindAPIKey(ctx context.Context, hash string) (*domain.APIKey, error) {
+	key := &domain.APIKey{}
+
+	err := scanAPIKey(repo.db.Client.QueryRow(ctx, selectAPIKeyByHashQuery, hash), key)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, errors_package.NotFound("api key not found")
+	}
+	if err != nil {
+		repo.logger.ErrorContext(ctx, "failed to find api key", "err", err)
+		return nil, errors_package.Internal(err)
+	}
+
+	return key, nil
+}
+
+func (repo *PGAPIKeyRepository) RevokeAPIKey(ctx context.Context, id uuid.UUID) (*domain.APIKey, error) {
+	key := &domain.APIKey{}
+
+	err := scanAPIKey(repo.db.Client.QueryRow(ctx, revokeAPIKeyQuery, id), key)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, errors_package.NotFound("api key %s not found", id)
+	}
+	if err != nil {
+		repo.logger.ErrorContext(ctx, "failed to revoke api key", "err", err)
+		return nil, errors_package.Internal(err)
+	}
+
+	return key, nil
+}
+
+func (repo *PGAPIKeyRepository) RotateAPIKey(ctx context.Context, id uuid.UUID, replacement *domain.APIKey) error {
+	return pgx.BeginFunc(ctx, repo.db.Client, func(tx pgx.Tx) error {
+		current := &domain.APIKey{}
+
+		err := scanAPIKey(tx.QueryRow(ctx, lockAPIKeyQuery, id), current)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return errors_package.NotFound("active api key %s not found", id)
+		}
+		if err != nil {
+			repo.logger.ErrorContext(ctx, "failed to lock api key", "err", err)
+			return errors_package.Internal(err)
+		}
+
+		if _, err = tx.Exec(ctx, revokeAPIKeyQuery, id); err != nil {
+			repo.logger.ErrorContext(ctx, "failed to revoke api key", "err", err)
+			return errors_package.Internal(err)
+		}
+
+		replacement.Name, replacement.Scopes = current.Name, current.Scopes
+
+		return repo.insert(ctx, tx, replacement)
+	})
+}
+
+func (repo *PGAPIKeyRepository) TouchAPIKey(ctx context.Context, id uuid.UUID, at time.Time,
+	precision time.Duration) error {
+	_, err := repo.db.Client.Exec(ctx, touchAPIKeyQuery, id, at, precision.Seconds())
+	if err != nil {
+		repo.logger.ErrorContext(ctx, "failed to record api key usage", "err", err)
+		return errors_package.Internal(err)
+	}
+
+	return nil
+}
+
+func scanAPIKey(row pgx.Row, key *domain.APIKey) error {
+	return row.Scan(&key.Id, &key.Name, &key.Prefix, &key.Hash, &key.Scopes, &key.CreatedAt,
+		&key.LastUsedAt, &key.RevokedAt)
+}
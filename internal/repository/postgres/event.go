@@ -0,0 +1,138 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/Aiszhio/Task/internal/domain"
+	errors_package "github.com/Aiszhio/Task/internal/errors"
+	"github.com/Aiszhio/Task/internal/requestmeta"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	insertEventQuery = `INSERT INTO subscription_events
+							(subscription_id, event_type, actor, request_id, before, after)
+						VALUES ($1, $2, $3, NULLIF($4, ''), $5, $6);`
+
+	selectEventsQuery = `SELECT id, subscription_id, event_type, actor, COALESCE(request_id, ''),
+       						before, after, created_at
+						FROM subscription_events
+						WHERE subscription_id = $1
+						ORDER BY id;`
+)
+
+// insertEvent записывает изменение подписки в журнал в рамках транзакции tx;
+// исполнитель и идентификатор запроса берутся из ctx
+func (repo *PGSubscriptionRepository) insertEvent(ctx context.Context, tx pgx.Tx, eventType domain.EventType,
+	id uuid.UUID, before, after *domain.Subscription) error {
+	beforeJSON, err := snapshot(before)
+	if err != nil {
+		return errors_package.Internal(err)
+	}
+
+	afterJSON, err := snapshot(after)
+	if err != nil {
+		return errors_package.Internal(err)
+	}
+
+	meta := requestmeta.From(ctx)
+
+	_, err = tx.Exec(ctx, insertEventQuery, id, eventType, meta.Actor, meta.RequestID, beforeJSON, afterJSON)
+	if err != nil {
+		repo.logger.ErrorContext(ctx, "failed to record subscription event", "id", id, "type", eventType, "err", err)
+		return errors_package.Internal(err)
+	}
+
+	return repo.insertOutbox(ctx, tx, eventType, before, after)
+}
+
+// insertOutbox публикует изменение подписки через outbox в рамках той же транзакции tx:
+// событие уйдёт подписчикам, только если изменение зафиксировано
+func (repo *PGSubscriptionRepository) insertOutbox(ctx context.Context, tx pgx.Tx, eventType domain.EventType,
+	before, after *domain.Subscription) error {
+	event, ok := domain.OutboxEvent(eventType, before, after, time.Now().UTC())
+	if !ok {
+		return nil
+	}
+
+	if _, err := insertOutbox(ctx, tx, event); err != nil {
+		repo.logger.ErrorContext(ctx, "failed to write outbox message", "type", event.Type, "err", err)
+		return errors_package.Internal(err)
+	}
+
+	return nil
+}
+
+// ListSubscriptionEvents возвращает журнал изменений подписки от старых записей к новым,
+// в том числе для подписок, уже удалённых из корзины
+func (repo *PGSubscriptionRepository) ListSubscriptionEvents(ctx context.Context,
+	id uuid.UUID) ([]domain.SubscriptionEvent, error) {
+	rows, err := repo.db.Client.Query(ctx, selectEventsQuery, id)
+	if err != nil {
+		repo.logger.ErrorContext(ctx, "failed to list subscription events", "err", err)
+		return nil, errors_package.Internal(err)
+	}
+	defer rows.Close()
+
+	var events []domain.SubscriptionEvent
+	for rows.Next() {
+		var (
+			event         domain.SubscriptionEvent
+			before, after []byte
+		)
+
+		err = rows.Scan(&event.Id, &event.SubscriptionId, &event.Type, &event.Actor, &event.RequestId,
+			&before, &after, &event.CreatedAt)
+		if err != nil {
+			repo.logger.ErrorContext(ctx, "failed to scan subscription event", "err", err)
+			return nil, errors_package.Internal(err)
+		}
+
+		if event.Before, err = restoreSnapshot(before); err != nil {
+			return nil, errors_package.Internal(err)
+		}
+
+		if event.After, err = restoreSnapshot(after); err != nil {
+			return nil, errors_package.Internal(err)
+		}
+
+		events = append(events, event)
+	}
+
+	if err = rows.Err(); err != nil {
+		repo.logger.ErrorContext(ctx, "failed to iterate subscription events", "err", err)
+		return nil, errors_package.Internal(err)
+	}
+
+	if len(events) == 0 {
+		return nil, errors_package.NotFound("subscription %s has no history", id)
+	}
+
+	repo.logger.InfoContext(ctx, "successfully listed subscription events", "id", id, "count", len(events))
+	return events, nil
+}
+
+// snapshot кодирует состояние подписки для столбцов before/after; nil даёт NULL
+func snapshot(sub *domain.Subscription) ([]byte, error) {
+	if sub == nil {
+		return nil, nil
+	}
+
+	return json.Marshal(sub)
+}
+
+func restoreSnapshot(data []byte) (*domain.Subscription, error) {
+	if data == nil {
+		return nil, nil
+	}
+
+	sub := &domain.Subscription{}
+	if err := json.Unmarshal(data, sub); err != nil {
+		return nil, err
+	}
+
+	return sub, nil
+}
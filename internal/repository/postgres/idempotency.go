@@ -0,0 +1,141 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/Aiszhio/Task/internal/db"
+	"github.com/Aiszhio/Task/internal/domain"
+	errors_package "github.com/Aiszhio/Task/internal/errors"
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	// claimKeyQuery занимает свободный или просроченный ключ, а также брошенный ключ того же запроса;
+	// строка не возвращается, если ключ занят
+	claimKeyQuery = `INSERT INTO idempotency_keys (key, request_hash, locked_until, expires_at)
+					VALUES ($1, $2, NOW() + $4 * INTERVAL '1 second', NOW() + $3 * INTERVAL '1 second')
+					ON CONFLICT (key) DO UPDATE
+						SET request_hash = EXCLUDED.request_hash,
+							status_code  = NULL,
+							content_type = NULL,
+							body         = NULL,
+							created_at   = NOW(),
+							locked_until = EXCLUDED.locked_until,
+							expires_at   = EXCLUDED.expires_at
+						WHERE idempotency_keys.expires_at <= NOW()
+						   OR (idempotency_keys.status_code IS NULL
+								AND idempotency_keys.locked_until <= NOW()
+								AND idempotency_keys.request_hash = EXCLUDED.request_hash)
+					RETURNING locked_until, expires_at;`
+
+	selectKeyQuery = `SELECT request_hash, COALESCE(status_code, 0), COALESCE(content_type, ''), body,
+							locked_until, expires_at
+						FROM idempotency_keys
+						WHERE key = $1;`
+
+	// saveResponseQuery и releaseKeyQuery меняют ключ, только пока он занят этим же запросом
+	saveResponseQuery = `UPDATE idempotency_keys
+						SET status_code  = $2,
+							content_type = $3,
+							body         = $4
+						WHERE key = $1
+						  AND status_code IS NULL
+						  AND locked_until = $5;`
+
+	releaseKeyQuery = `DELETE FROM idempotency_keys
+						WHERE key = $1
+						  AND status_code IS NULL
+						  AND locked_until = $2;`
+
+	deleteExpiredKeysQuery = `DELETE FROM idempotency_keys WHERE expires_at <= NOW();`
+)
+
+type IdempotencyRepository interface {
+	// ClaimKey занимает ключ на ttl, а выполнение запроса — на lease: если ответ не сохранён за lease,
+	// ключ может занять повтор того же запроса. Если ключ уже занят, возвращает сохранённый запрос и false.
+	ClaimKey(ctx context.Context, key, requestHash string,
+		ttl, lease time.Duration) (*domain.IdempotentRequest, bool, error)
+	// SaveResponse сохраняет ответ; если ключ за это время занял повтор, ответ не сохраняется
+	SaveResponse(ctx context.Context, req *domain.IdempotentRequest) error
+	// ReleaseKey освобождает ключ, ответ на который не сохранён, чтобы запрос можно было повторить
+	ReleaseKey(ctx context.Context, req *domain.IdempotentRequest) error
+	DeleteExpiredKeys(ctx context.Context) (int64, error)
+}
+
+type PGIdempotencyRepository struct {
+	db     *db.Pool
+	logger *slog.Logger
+}
+
+func NewIdempotencyRepository(pool *db.Pool, logger *slog.Logger) *PGIdempotencyRepository {
+	return &PGIdempotencyRepository{
+		db:     pool,
+		logger: logger,
+	}
+}
+
+func (repo *PGIdempotencyRepository) ClaimKey(ctx context.Context, key, requestHash string,
+	ttl, lease time.Duration) (*domain.IdempotentRequest, bool, error) {
+	req := &domain.IdempotentRequest{Key: key, RequestHash: requestHash}
+
+	err := repo.db.Client.QueryRow(ctx, claimKeyQuery, key, requestHash, ttl.Seconds(), lease.Seconds()).
+		Scan(&req.LockedUntil, &req.ExpiresAt)
+	if err == nil {
+		return req, true, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		repo.logger.ErrorContext(ctx, "failed to claim idempotency key", "err", err)
+		return nil, false, errors_package.Internal(err)
+	}
+
+	err = repo.db.Client.QueryRow(ctx, selectKeyQuery, key).Scan(&req.RequestHash, &req.StatusCode,
+		&req.ContentType, &req.Body, &req.LockedUntil, &req.ExpiresAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		// ключ освобождён между попытками занять и прочитать его
+		return nil, false, errors_package.Conflict("request with idempotency key %q is being retried", key)
+	}
+	if err != nil {
+		repo.logger.ErrorContext(ctx, "failed to read idempotency key", "err", err)
+		return nil, false, errors_package.Internal(err)
+	}
+
+	return req, false, nil
+}
+
+func (repo *PGIdempotencyRepository) SaveResponse(ctx context.Context, req *domain.IdempotentRequest) error {
+	cmd, err := repo.db.Client.Exec(ctx, saveResponseQuery, req.Key, req.StatusCode, req.ContentType, req.Body,
+		req.LockedUntil)
+	if err != nil {
+		repo.logger.ErrorContext(ctx, "failed to save idempotent response", "err", err)
+		return errors_package.Internal(err)
+	}
+
+	if cmd.RowsAffected() == 0 {
+		repo.logger.WarnContext(ctx, "idempotency key was taken over before the response was saved", "key", req.Key)
+	}
+
+	return nil
+}
+
+func (repo *PGIdempotencyRepository) ReleaseKey(ctx context.Context, req *domain.IdempotentRequest) error {
+	_, err := repo.db.Client.Exec(ctx, releaseKeyQuery, req.Key, req.LockedUntil)
+	if err != nil {
+		repo.logger.ErrorContext(ctx, "failed to release idempotency key", "err", err)
+		return errors_package.Internal(err)
+	}
+
+	return nil
+}
+
+func (repo *PGIdempotencyRepository) DeleteExpiredKeys(ctx context.Context) (int64, error) {
+	cmd, err := repo.db.Client.Exec(ctx, deleteExpiredKeysQuery)
+	if err != nil {
+		repo.logger.ErrorContext(ctx, "failed to delete expired idempotency keys", "err", err)
+		return 0, errors_package.Internal(err)
+	}
+
+	return cmd.RowsAffected(), nil
+}
@@ -0,0 +1,177 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"time"
+
+	"github.com/Aiszhio/Task/internal/db"
+	"github.com/Aiszhio/Task/internal/domain"
+	errors_package "github.com/Aiszhio/Task/internal/errors"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+const (
+	// insertOutboxQuery не добавляет событие, которое уже есть в outbox
+	insertOutboxQuery = `INSERT INTO outbox (subscription_id, event_id, event_type, payload)
+						VALUES ($1, $2, $3, $4)
+						ON CONFLICT (event_id) DO NOTHING;`
+
+	// lockOutboxQuery берёт только первое неопубликованное сообщение каждой подписки: следующее
+	// станет доступно, когда это будет опубликовано или исчерпает попытки, поэтому события
+	// подписки выходят по порядку даже при нескольких репликах
+	lockOutboxQuery = `SELECT o.id, o.subscription_id, o.payload, o.attempts, o.next_attempt_at,
+							COALESCE(o.last_error, ''), o.created_at
+						FROM outbox o
+						WHERE o.published_at IS NULL
+						  AND o.failed_at IS NULL
+						  AND o.next_attempt_at <= $1
+						  AND NOT EXISTS (
+							SELECT 1
+							FROM outbox e
+							WHERE e.subscription_id = o.subscription_id
+							  AND e.published_at IS NULL
+							  AND e.failed_at IS NULL
+							  AND e.id < o.id
+						  )
+						ORDER BY o.id
+						LIMIT $2
+						FOR UPDATE SKIP LOCKED;`
+
+	markOutboxPublishedQuery = `UPDATE outbox
+						SET published_at = $2,
+							attempts     = attempts + 1,
+							last_error   = NULL
+						WHERE id = $1;`
+
+	markOutboxFailedQuery = `UPDATE outbox
+						SET attempts        = $2,
+							next_attempt_at = $3,
+							last_error      = $4,
+							failed_at       = $5
+						WHERE id = $1;`
+
+	deletePublishedOutboxQuery = `DELETE FROM outbox
+						WHERE published_at < NOW() - $1 * INTERVAL '1 second';`
+)
+
+type OutboxRepository interface {
+	// EnqueueMessage добавляет событие, не связанное с изменением подписки; событие с тем же Id
+	// добавляется один раз, и тогда возвращается true
+	EnqueueMessage(ctx context.Context, event *domain.WebhookEvent) (bool, error)
+	// RelayMessages берёт до limit сообщений, срок которых наступил к now, по одному на подписку,
+	// и передаёт их в publish по порядку. Опубликованные сообщения отмечаются в той же транзакции,
+	// а при ошибке publish сообщение откладывается по retry или, когда попытки исчерпаны, отмечается
+	// неудавшимся. Возвращает число обработанных сообщений
+	RelayMessages(ctx context.Context, now time.Time, limit int, retry domain.RetryPolicy,
+		publish func(ctx context.Context, msg *domain.OutboxMessage) error) (int, error)
+	// DeletePublished удаляет сообщения, опубликованные раньше, чем olderThan назад
+	DeletePublished(ctx context.Context, olderThan time.Duration) (int64, error)
+}
+
+// PGOutboxRepository читает outbox, который PGSubscriptionRepository пополняет в транзакциях изменений
+type PGOutboxRepository struct {
+	db     *db.Pool
+	logger *slog.Logger
+}
+
+func NewOutboxRepository(pool *db.Pool, logger *slog.Logger) *PGOutboxRepository {
+	return &PGOutboxRepository{
+		db:     pool,
+		logger: logger,
+	}
+}
+
+type execer interface {
+	Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+}
+
+// insertOutbox записывает событие в outbox через conn — транзакцию изменения или пул
+func insertOutbox(ctx context.Context, conn execer, event *domain.WebhookEvent) (bool, error) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return false, err
+	}
+
+	tag, err := conn.Exec(ctx, insertOutboxQuery, event.Subscription.Id, event.Id, string(event.Type), payload)
+	if err != nil {
+		return false, err
+	}
+
+	return tag.RowsAffected() == 1, nil
+}
+
+func (repo *PGOutboxRepository) EnqueueMessage(ctx context.Context, event *domain.WebhookEvent) (bool, error) {
+	added, err := insertOutbox(ctx, repo.db.Client, event)
+	if err != nil {
+		repo.logger.ErrorContext(ctx, "failed to enqueue outbox message", "event", event.Type, "err", err)
+		return false, errors_package.Internal(err)
+	}
+
+	return added, nil
+}
+
+func (repo *PGOutboxRepository) RelayMessages(ctx context.Context, now time.Time, limit int,
+	retry domain.RetryPolicy, publish func(ctx context.Context, msg *domain.OutboxMessage) error) (int, error) {
+	var relayed int
+
+	err := pgx.BeginFunc(ctx, repo.db.Client, func(tx pgx.Tx) error {
+		rows, err := tx.Query(ctx, lockOutboxQuery, now, limit)
+		if err != nil {
+			return err
+		}
+
+		messages, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (domain.OutboxMessage, error) {
+			var (
+				msg     domain.OutboxMessage
+				payload []byte
+			)
+			err := row.Scan(&msg.Id, &msg.SubscriptionId, &payload, &msg.Attempts, &msg.NextAttemptAt,
+				&msg.LastError, &msg.CreatedAt)
+			if err != nil {
+				return msg, err
+			}
+
+			return msg, json.Unmarshal(payload, &msg.Event)
+		})
+		if err != nil {
+			return err
+		}
+
+		for i := range messages {
+			msg := &messages[i]
+
+			if publishErr := publish(ctx, msg); publishErr != nil {
+				msg.Fail(time.Now().UTC(), publishErr, retry)
+				_, err = tx.Exec(ctx, markOutboxFailedQuery, msg.Id, msg.Attempts, msg.NextAttemptAt, msg.LastError,
+					msg.FailedAt)
+			} else {
+				_, err = tx.Exec(ctx, markOutboxPublishedQuery, msg.Id, time.Now().UTC())
+			}
+			if err != nil {
+				return err
+			}
+		}
+
+		relayed = len(messages)
+		return nil
+	})
+	if err != nil {
+		repo.logger.ErrorContext(ctx, "failed to relay outbox messages", "err", err)
+		return 0, errors_package.Internal(err)
+	}
+
+	return relayed, nil
+}
+
+func (repo *PGOutboxRepository) DeletePublished(ctx context.Context, olderThan time.Duration) (int64, error) {
+	tag, err := repo.db.Client.Exec(ctx, deletePublishedOutboxQuery, olderThan.Seconds())
+	if err != nil {
+		repo.logger.ErrorContext(ctx, "failed to delete published outbox messages", "err", err)
+		return 0, errors_package.Internal(err)
+	}
+
+	return tag.RowsAffected(), nil
+}
@@ -0,0 +1,92 @@
+package postgres
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/Aiszhio/Task/internal/db"
+	"github.com/Aiszhio/Task/internal/domain"
+	errors_package "github.com/Aiszhio/Task/internal/errors"
+	"github.com/jackc/pgx/v5"
+)
+
+// Время вёдер берётся из часов Postgres, чтобы реплики с расходящимися часами считали одинаково
+const (
+	ensureBucketQuery = `INSERT INTO rate_limit_buckets (key, tokens, updated_at)
+						VALUES ($1, $2, NOW())
+						ON CONFLICT (key) DO NOTHING;`
+
+	lockBucketQuery = `SELECT tokens, updated_at, NOW()
+						FROM rate_limit_buckets
+						WHERE key = $1
+						FOR UPDATE;`
+
+	updateBucketQuery = `UPDATE rate_limit_buckets
+						SET tokens     = $2,
+							updated_at = $3
+						WHERE key = $1;`
+
+	deleteIdleBucketsQuery = `DELETE FROM rate_limit_buckets
+							WHERE updated_at < NOW() - $1 * INTERVAL '1 second';`
+)
+
+type RateLimitRepository interface {
+	// TakeToken забирает токен из ведра key; при первом обращении ведро создаётся полным
+	TakeToken(ctx context.Context, key string, limit domain.RateLimit) (*domain.RateDecision, error)
+	// DeleteIdleBuckets удаляет вёдра, к которым не обращались дольше idle
+	DeleteIdleBuckets(ctx context.Context, idle time.Duration) (int64, error)
+}
+
+// PGRateLimitRepository хранит вёдра в Postgres, чтобы лимит был общим для всех реплик сервиса
+type PGRateLimitRepository struct {
+	db     *db.Pool
+	logger *slog.Logger
+}
+
+func NewRateLimitRepository(pool *db.Pool, logger *slog.Logger) *PGRateLimitRepository {
+	return &PGRateLimitRepository{
+		db:     pool,
+		logger: logger,
+	}
+}
+
+func (repo *PGRateLimitRepository) TakeToken(ctx context.Context, key string,
+	limit domain.RateLimit) (*domain.RateDecision, error) {
+	var decision domain.RateDecision
+
+	err := pgx.BeginFunc(ctx, repo.db.Client, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, ensureBucketQuery, key, float64(limit.Requests)); err != nil {
+			return err
+		}
+
+		var (
+			bucket domain.RateBucket
+			now    time.Time
+		)
+		if err := tx.QueryRow(ctx, lockBucketQuery, key).Scan(&bucket.Tokens, &bucket.UpdatedAt, &now); err != nil {
+			return err
+		}
+
+		decision = bucket.Take(limit, now)
+
+		_, err := tx.Exec(ctx, updateBucketQuery, key, bucket.Tokens, bucket.UpdatedAt)
+		return err
+	})
+	if err != nil {
+		repo.logger.ErrorContext(ctx, "failed to take rate limit token", "err", err)
+		return nil, errors_package.Internal(err)
+	}
+
+	return &decision, nil
+}
+
+func (repo *PGRateLimitRepository) DeleteIdleBuckets(ctx context.Context, idle time.Duration) (int64, error) {
+	cmd, err := repo.db.Client.Exec(ctx, deleteIdleBucketsQuery, idle.Seconds())
+	if err != nil {
+		repo.logger.ErrorContext(ctx, "failed to delete idle rate limit buckets", "err", err)
+		return 0, errors_package.Internal(err)
+	}
+
+	return cmd.RowsAffected(), nil
+}
@@ -0,0 +1,216 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/Aiszhio/Task/internal/db"
+	"github.com/Aiszhio/Task/internal/domain"
+	errors_package "github.com/Aiszhio/Task/internal/errors"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// reminderColumns — порядок столбцов, который ожидает scanReminder
+const reminderColumns = `id, subscription_id, user_id, kind, due_at, window_seconds, channel, status, attempts,
+						next_attempt_at, COALESCE(last_error, ''), created_at, sent_at`
+
+const (
+	// claimReminderQuery заводит напоминание или снова берёт то, что ждёт повтора и чей срок наступил;
+	// отправленное, проваленное или взятое другой репликой не возвращается
+	claimReminderQuery = `INSERT INTO reminders (id, subscription_id, user_id, kind, due_at, window_seconds, channel,
+							next_attempt_at)
+						VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+						ON CONFLICT (id) DO UPDATE
+						SET next_attempt_at = EXCLUDED.next_attempt_at
+						WHERE reminders.status = 'pending'
+						  AND reminders.next_attempt_at <= $9
+						RETURNING attempts, created_at;`
+
+	updateReminderQuery = `UPDATE reminders
+						SET status          = $2,
+							attempts        = $3,
+							next_attempt_at = $4,
+							last_error      = NULLIF($5, ''),
+							sent_at         = $6
+						WHERE id = $1;`
+
+	selectRemindersQuery = `SELECT ` + reminderColumns + `
+						FROM reminders
+						WHERE ($1::uuid IS NULL OR user_id = $1)
+						ORDER BY created_at DESC, id
+						LIMIT $2;`
+
+	deleteRemindersQuery = `DELETE FROM reminders
+						WHERE due_at < NOW() - $1 * INTERVAL '1 second';`
+
+	upsertContactQuery = `INSERT INTO reminder_contacts (user_id, email)
+						VALUES ($1, $2)
+						ON CONFLICT (user_id) DO UPDATE
+						SET email      = EXCLUDED.email,
+							updated_at = NOW()
+						RETURNING updated_at;`
+
+	selectContactQuery = `SELECT user_id, email, updated_at
+						FROM reminder_contacts
+						WHERE user_id = $1;`
+
+	deleteContactQuery = `DELETE FROM reminder_contacts
+						WHERE user_id = $1;`
+)
+
+type ReminderRepository interface {
+	// ClaimReminder заводит напоминание и берёт его в работу на lease. Уже заведённое берётся, только
+	// если оно ждёт повтора и срок наступил к now; иначе возвращается false и отправлять его не нужно
+	ClaimReminder(ctx context.Context, reminder *domain.Reminder, now time.Time, lease time.Duration) (bool, error)
+	// SaveReminder записывает итог попытки отправки
+	SaveReminder(ctx context.Context, reminder *domain.Reminder) error
+	// ListReminders возвращает напоминания пользователя, начиная с новых; uuid.Nil — всех пользователей
+	ListReminders(ctx context.Context, userId uuid.UUID, limit int) ([]domain.Reminder, error)
+	// DeleteReminders удаляет напоминания о продлениях и окончаниях, прошедших раньше, чем olderThan назад
+	DeleteReminders(ctx context.Context, olderThan time.Duration) (int64, error)
+	// SaveContact задаёт адрес пользователя для напоминаний по почте, заменяя прежний
+	SaveContact(ctx context.Context, contact *domain.ReminderContact) error
+	ReadContact(ctx context.Context, userId uuid.UUID) (*domain.ReminderContact, error)
+	DeleteContact(ctx context.Context, userId uuid.UUID) error
+}
+
+type PGReminderRepository struct {
+	db     *db.Pool
+	logger *slog.Logger
+}
+
+func NewReminderRepository(pool *db.Pool, logger *slog.Logger) *PGReminderRepository {
+	return &PGReminderRepository{
+		db:     pool,
+		logger: logger,
+	}
+}
+
+func (repo *PGReminderRepository) ClaimReminder(ctx context.Context, reminder *domain.Reminder, now time.Time,
+	lease time.Duration) (bool, error) {
+	leased := now.Add(lease)
+
+	err := repo.db.Client.QueryRow(ctx, claimReminderQuery, reminder.Id, reminder.SubscriptionId, reminder.UserId,
+		string(reminder.Kind), reminder.DueAt, int64(time.Duration(reminder.Window).Seconds()), reminder.Channel,
+		leased, now).Scan(&reminder.Attempts, &reminder.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		repo.logger.ErrorContext(ctx, "failed to claim reminder", "reminder", reminder.Id, "err", err)
+		return false, errors_package.Internal(err)
+	}
+
+	reminder.Status = domain.DeliveryPending
+	reminder.NextAttemptAt = &leased
+
+	return true, nil
+}
+
+func (repo *PGReminderRepository) SaveReminder(ctx context.Context, reminder *domain.Reminder) error {
+	_, err := repo.db.Client.Exec(ctx, updateReminderQuery, reminder.Id, string(reminder.Status), reminder.Attempts,
+		reminder.NextAttemptAt, reminder.LastError, reminder.SentAt)
+	if err != nil {
+		repo.logger.ErrorContext(ctx, "failed to save reminder", "reminder", reminder.Id, "err", err)
+		return errors_package.Internal(err)
+	}
+
+	return nil
+}
+
+func (repo *PGReminderRepository) ListReminders(ctx context.Context, userId uuid.UUID,
+	limit int) ([]domain.Reminder, error) {
+	var user *uuid.UUID
+	if userId != uuid.Nil {
+		user = &userId
+	}
+
+	rows, err := repo.db.Client.Query(ctx, selectRemindersQuery, user, limit)
+	if err != nil {
+		repo.logger.ErrorContext(ctx, "failed to list reminders", "err", err)
+		return nil, errors_package.Internal(err)
+	}
+	defer rows.Close()
+
+	reminders := []domain.Reminder{}
+	for rows.Next() {
+		var reminder domain.Reminder
+		if err = scanReminder(rows, &reminder); err != nil {
+			repo.logger.ErrorContext(ctx, "failed to scan reminder", "err", err)
+			return nil, errors_package.Internal(err)
+		}
+		reminders = append(reminders, reminder)
+	}
+
+	if err = rows.Err(); err != nil {
+		repo.logger.ErrorContext(ctx, "failed to list reminders", "err", err)
+		return nil, errors_package.Internal(err)
+	}
+
+	return reminders, nil
+}
+
+func (repo *PGReminderRepository) DeleteReminders(ctx context.Context, olderThan time.Duration) (int64, error) {
+	tag, err := repo.db.Client.Exec(ctx, deleteRemindersQuery, olderThan.Seconds())
+	if err != nil {
+		repo.logger.ErrorContext(ctx, "failed to delete reminders", "err", err)
+		return 0, errors_package.Internal(err)
+	}
+
+	return tag.RowsAffected(), nil
+}
+
+func (repo *PGReminderRepository) SaveContact(ctx context.Context, contact *domain.ReminderContact) error {
+	err := repo.db.Client.QueryRow(ctx, upsertContactQuery, contact.UserId, contact.Email).Scan(&contact.UpdatedAt)
+	if err != nil {
+		repo.logger.ErrorContext(ctx, "failed to save reminder contact", "err", err)
+		return errors_package.Internal(err)
+	}
+
+	return nil
+}
+
+func (repo *PGReminderRepository) ReadContact(ctx context.Context,
+	userId uuid.UUID) (*domain.ReminderContact, error) {
+	contact := &domain.ReminderContact{}
+
+	err := repo.db.Client.QueryRow(ctx, selectContactQuery, userId).Scan(&contact.UserId, &contact.Email,
+		&contact.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, errors_package.NotFound("reminder contact of user %s not found", userId)
+	}
+	if err != nil {
+		repo.logger.ErrorContext(ctx, "failed to read reminder contact", "err", err)
+		return nil, errors_package.Internal(err)
+	}
+
+	return contact, nil
+}
+
+func (repo *PGReminderRepository) DeleteContact(ctx context.Context, userId uuid.UUID) error {
+	tag, err := repo.db.Client.Exec(ctx, deleteContactQuery, userId)
+	if err != nil {
+		repo.logger.ErrorContext(ctx, "failed to delete reminder contact", "err", err)
+		return errors_package.Internal(err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return errors_package.NotFound("reminder contact of user %s not found", userId)
+	}
+
+	return nil
+}
+
+func scanReminder(row pgx.Row, reminder *domain.Reminder) error {
+	var windowSeconds int64
+
+	err := row.Scan(&reminder.Id, &reminder.SubscriptionId, &reminder.UserId, &reminder.Kind, &reminder.DueAt,
+		&windowSeconds, &reminder.Channel, &reminder.Status, &reminder.Attempts, &reminder.NextAttemptAt,
+		&reminder.LastError, &reminder.CreatedAt, &reminder.SentAt)
+	reminder.Window = domain.ReminderWindow(time.Duration(windowSeconds) * time.Second)
+
+	return err
+}
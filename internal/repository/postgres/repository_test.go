@@ -0,0 +1,26 @@
+package postgres_test
+
+import (
+	"log/slog"
+	"testing"
+
+	"github.com/Aiszhio/Task/internal/repository/postgres"
+	"github.com/Aiszhio/Task/internal/repository/repotest"
+)
+
+func TestRepositories(t *testing.T) {
+	repotest.Run(t, func(t *testing.T) repotest.Stores {
+		logger := slog.New(slog.DiscardHandler)
+		pool := repotest.Postgres(t)
+
+		return repotest.Stores{
+			Subscriptions: postgres.NewRepository(pool, logger),
+			Outbox:        postgres.NewOutboxRepository(pool, logger),
+			Idempotency:   postgres.NewIdempotencyRepository(pool, logger),
+			APIKeys:       postgres.NewAPIKeyRepository(pool, logger),
+			RateLimits:    postgres.NewRateLimitRepository(pool, logger),
+			Webhooks:      postgres.NewWebhookRepository(pool, logger),
+			Reminders:     postgres.NewReminderRepository(pool, logger),
+		}
+	})
+}
@@ -1,55 +1,148 @@
 package postgres
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
 	"log/slog"
+	"strings"
 	"time"
 
 	"github.com/Aiszhio/Task/internal/db"
 	"github.com/Aiszhio/Task/internal/domain"
-	"github.com/gin-gonic/gin"
+	errors_package "github.com/Aiszhio/Task/internal/errors"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 )
 
+// subscriptionColumns — порядок столбцов, который ожидает scanSubscription
+const subscriptionColumns = `id, service_name, price, billing_interval, interval_count, user_id,
+						start_date, end_date, created_at, updated_at, version, deleted_at`
+
 const (
 	insertQuery = `INSERT INTO subscriptions
-    				(id, service_name, price, user_id, start_date, end_date)
-					VALUES ($1, $2, $3, $4, $5, $6);`
+    				(id, service_name, price, billing_interval, interval_count, user_id, start_date, end_date)
+					VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+					RETURNING ` + subscriptionColumns + `;`
+
+	selectUserQuery = `SELECT ` + subscriptionColumns + `
+						FROM subscriptions
+						WHERE id = $1
+						  AND deleted_at IS NULL;`
 
-	selectUserQuery = `SELECT id, service_name, price, user_id, start_date, end_date
+	selectDeletedQuery = `SELECT ` + subscriptionColumns + `
 						FROM subscriptions
-						WHERE id = $1;`
+						WHERE id = $1
+						  AND deleted_at IS NOT NULL;`
 
-	deleteQuery = `DELETE FROM subscriptions WHERE id = $1;`
+	lockActiveQuery = `SELECT ` + subscriptionColumns + `
+						FROM subscriptions
+						WHERE id = $1
+						  AND deleted_at IS NULL
+						FOR UPDATE;`
+
+	lockDeletedQuery = `SELECT ` + subscriptionColumns + `
+						FROM subscriptions
+						WHERE id = $1
+						  AND deleted_at IS NOT NULL
+						FOR UPDATE;`
+
+	deleteQuery = `UPDATE subscriptions
+					SET deleted_at = NOW(),
+						updated_at = NOW(),
+						version    = version + 1
+					WHERE id = $1
+					RETURNING ` + subscriptionColumns + `;`
+
+	restoreQuery = `UPDATE subscriptions s
+					SET deleted_at = NULL,
+						updated_at = NOW(),
+						version    = s.version + 1
+					WHERE s.id = $1
+					RETURNING ` + subscriptionColumns + `;`
+
+	purgeQuery = `DELETE FROM subscriptions
+					WHERE deleted_at IS NOT NULL
+					  AND deleted_at < NOW() - $1 * INTERVAL '1 second'
+					RETURNING ` + subscriptionColumns + `;`
 
 	updateQuery = `UPDATE subscriptions
-					SET service_name = $1,
-						price        = $2,
-						start_date   = $3,
-						end_date     = $4
-					WHERE user_id = $5;`
+					SET service_name     = $1,
+						price            = $2,
+						billing_interval = $3,
+						interval_count   = $4,
+						start_date       = $5,
+						end_date         = $6,
+						updated_at       = NOW(),
+						version          = version + 1
+					WHERE id = $7
+					RETURNING ` + subscriptionColumns + `;`
 
-	selectFilterQuery = `SELECT COALESCE(SUM(price), 0) AS total_cost
+	selectFilterQuery = `SELECT ` + subscriptionColumns + `
 						FROM   subscriptions
 						WHERE  user_id = $1
 						  AND  service_name = $2
+						  AND  deleted_at IS NULL
 						  AND  start_date  <= $4
 						  AND  (end_date IS NULL
-								OR end_date >= $3);`
+								OR end_date >= $3)
+						ORDER BY start_date, id;`
 
-	uniqueFilterQuery = `SELECT COUNT(*) FROM subscriptions
-							WHERE user_id = $1 and service_name = $2 and start_date = $3`
+	selectPageQuery = `SELECT ` + subscriptionColumns + `
+						FROM subscriptions`
+
+	countPageQuery = `SELECT COUNT(*) FROM subscriptions`
 )
 
+const uniqueViolationCode = "23505"
+
+// slotIndex не даёт завести две действующие подписки пользователя на сервис с одной датой начала
+const slotIndex = "idx_subscriptions_user_service_start"
+
+// errDryRun откатывает транзакцию пробного создания подписок
+var errDryRun = errors.New("dry run")
+
+const (
+	streamCursor    = "stream_subscriptions"
+	streamBatchSize = 500
+)
+
+var sortColumns = map[string]string{
+	"id":               "id",
+	"service_name":     "service_name",
+	"price":            "price",
+	"billing_interval": "billing_interval",
+	"interval_count":   "interval_count",
+	"user_id":          "user_id",
+	"start_date":       "start_date",
+	"end_date":         "end_date",
+	"created_at":       "created_at",
+	"updated_at":       "updated_at",
+	"version":          "version",
+	"deleted_at":       "deleted_at",
+}
+
 type SubscriptionRepository interface {
-	CreateSubscription(ctx *gin.Context, sub *domain.Subscription) error
-	ReadSubscription(ctx *gin.Context, id uuid.UUID) (*domain.Subscription, error)
-	DeleteSubscription(ctx *gin.Context, id uuid.UUID) error
-	UpdateSubscription(ctx *gin.Context, sub *domain.Subscription) error
-	ListSubscriptions(ctx *gin.Context, startDate, endDate time.Time, userId uuid.UUID, serviceName string) (uint64, error)
+	CreateSubscription(ctx context.Context, sub *domain.Subscription) error
+	CreateSubscriptions(ctx context.Context, subs []*domain.Subscription, dryRun bool) error
+	ReadSubscription(ctx context.Context, id uuid.UUID) (*domain.Subscription, error)
+	DeleteSubscription(ctx context.Context, id uuid.UUID) error
+	UpdateSubscription(ctx context.Context, sub *domain.Subscription) error
+	ListSubscriptions(ctx context.Context, startDate, endDate time.Time, userId uuid.UUID,
+		serviceName string) ([]domain.Subscription, error)
+	FindSubscriptions(ctx context.Context, filter *domain.SubscriptionFilter) (*domain.SubscriptionPage, error)
+	ReadDeletedSubscription(ctx context.Context, id uuid.UUID) (*domain.Subscription, error)
+	RestoreSubscription(ctx context.Context, id uuid.UUID) (*domain.Subscription, error)
+	PurgeSubscriptions(ctx context.Context, retention time.Duration) (int64, error)
+	ListSubscriptionEvents(ctx context.Context, id uuid.UUID) ([]domain.SubscriptionEvent, error)
+	StreamSubscriptions(ctx context.Context, filter *domain.SubscriptionFilter,
+		fn func(sub *domain.Subscription) error) error
 }
 
+// PGSubscriptionRepository хранит подписки в PostgreSQL; каждое изменение подписки
+// записывается в subscription_events в той же транзакции
 type PGSubscriptionRepository struct {
 	db     *db.Pool
 	logger *slog.Logger
@@ -62,96 +155,515 @@ func NewRepository(pool *db.Pool, logger *slog.Logger) *PGSubscriptionRepository
 	}
 }
 
-func (repo *PGSubscriptionRepository) CreateSubscription(ctx *gin.Context, sub *domain.Subscription) error {
-	var count int
-	err := repo.db.Client.QueryRow(
-		ctx, uniqueFilterQuery,
-		sub.UserId, sub.ServiceName, sub.StartDate,
-	).Scan(&count)
+func (repo *PGSubscriptionRepository) CreateSubscription(ctx context.Context, sub *domain.Subscription) error {
+	err := pgx.BeginFunc(ctx, repo.db.Client, func(tx pgx.Tx) error {
+		return repo.insert(ctx, tx, sub)
+	})
 	if err != nil {
-		repo.logger.Error("failed to check uniqueness", "err", err)
 		return err
 	}
 
-	if count > 0 {
-		repo.logger.Info("subscription already exists",
+	repo.logger.InfoContext(ctx, "successfully created subscription")
+	return nil
+}
+
+// CreateSubscriptions создаёт подписки в одной транзакции: при ошибке не сохраняется ни одна.
+// Конфликты проверяются для каждой подписки, каждая в своей точке сохранения, и возвращаются
+// все сразу как errors_package.RowErrors с номерами подписок в subs. При dryRun транзакция
+// откатывается и после успешной проверки
+func (repo *PGSubscriptionRepository) CreateSubscriptions(ctx context.Context, subs []*domain.Subscription,
+	dryRun bool) error {
+	err := pgx.BeginFunc(ctx, repo.db.Client, func(tx pgx.Tx) error {
+		var rowErrs errors_package.RowErrors
+		for i, sub := range subs {
+			err := pgx.BeginFunc(ctx, tx, func(savepoint pgx.Tx) error {
+				return repo.insert(ctx, savepoint, sub)
+			})
+			if errors.Is(err, errors_package.ErrConflict) {
+				rowErrs = append(rowErrs, &errors_package.RowError{Index: i, Err: err})
+				continue
+			}
+			if err != nil {
+				return &errors_package.RowError{Index: i, Err: err}
+			}
+		}
+
+		if len(rowErrs) > 0 {
+			return rowErrs
+		}
+		if dryRun {
+			return errDryRun
+		}
+
+		return nil
+	})
+	if errors.Is(err, errDryRun) {
+		repo.logger.InfoContext(ctx, "subscriptions can be created", "count", len(subs))
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	repo.logger.InfoContext(ctx, "successfully created subscriptions", "count", len(subs))
+	return nil
+}
+
+// insert добавляет подписку вместе с записью журнала в рамках транзакции tx; действующую подписку
+// пользователя на сервис с той же датой начала не даёт создать уникальный индекс slotIndex
+func (repo *PGSubscriptionRepository) insert(ctx context.Context, tx pgx.Tx, sub *domain.Subscription) error {
+	created := &domain.Subscription{}
+	err := scanSubscription(tx.QueryRow(ctx, insertQuery, sub.Id, sub.ServiceName, sub.Price,
+		sub.BillingInterval, sub.IntervalCount, sub.UserId, sub.StartDate, nullDate(sub.EndDate)), created)
+	if isSlotTaken(err) {
+		repo.logger.InfoContext(ctx, "subscription already exists",
 			"user_id", sub.UserId,
 			"service", sub.ServiceName,
 			"start_date", sub.StartDate,
 		)
-		return fmt.Errorf("subscription already exists for user %s service %s at %s",
-			sub.UserId, sub.ServiceName, sub.StartDate.Format("2006-01-02"),
-		)
+		return slotConflict(sub.UserId, sub.ServiceName, sub.StartDate)
 	}
-
-	_, err = repo.db.Client.Exec(ctx, insertQuery, sub.Id,
-		sub.ServiceName, sub.Price, sub.UserId, sub.StartDate, sub.EndDate)
 	if err != nil {
 		log.Printf("error creating subscription: %v", err)
-		return err
+		if isUniqueViolation(err) {
+			return errors_package.Conflict("subscription %s already exists", sub.Id)
+		}
+		return errors_package.Internal(err)
 	}
 
-	repo.logger.Info("successfully created subscription")
-	return nil
+	sub.CreatedAt, sub.UpdatedAt, sub.Version = created.CreatedAt, created.UpdatedAt, created.Version
+
+	return repo.insertEvent(ctx, tx, domain.EventCreated, sub.Id, nil, created)
 }
 
-func (repo *PGSubscriptionRepository) ReadSubscription(ctx *gin.Context, id uuid.UUID) (*domain.Subscription, error) {
+func (repo *PGSubscriptionRepository) ReadSubscription(ctx context.Context,
+	id uuid.UUID) (*domain.Subscription, error) {
 	subscr := &domain.Subscription{}
 
-	err := repo.db.Client.QueryRow(ctx, selectUserQuery, id).Scan(&subscr.Id, &subscr.ServiceName, &subscr.Price,
-		&subscr.UserId, &subscr.StartDate, &subscr.EndDate)
+	err := scanSubscription(repo.db.Client.QueryRow(ctx, selectUserQuery, id), subscr)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, errors_package.NotFound("subscription %s not found", id)
+	}
 	if err != nil {
 		log.Printf("error reading subscription: %v", err)
-		return nil, err
+		return nil, errors_package.Internal(err)
+	}
+
+	repo.logger.InfoContext(ctx, "successfully read subscription")
+	return subscr, nil
+}
+
+// ReadDeletedSubscription читает подписку из корзины
+func (repo *PGSubscriptionRepository) ReadDeletedSubscription(ctx context.Context,
+	id uuid.UUID) (*domain.Subscription, error) {
+	subscr := &domain.Subscription{}
+
+	err := scanSubscription(repo.db.Client.QueryRow(ctx, selectDeletedQuery, id), subscr)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, errors_package.NotFound("subscription %s not found in trash", id)
+	}
+	if err != nil {
+		repo.logger.ErrorContext(ctx, "failed to read deleted subscription", "err", err)
+		return nil, errors_package.Internal(err)
 	}
 
-	repo.logger.Info("successfully read subscription")
 	return subscr, nil
 }
 
-func (repo *PGSubscriptionRepository) DeleteSubscription(ctx *gin.Context, id uuid.UUID) error {
-	cmd, err := repo.db.Client.Exec(ctx, deleteQuery, id)
+// DeleteSubscription переносит подписку в корзину; окончательно её удаляет PurgeSubscriptions
+func (repo *PGSubscriptionRepository) DeleteSubscription(ctx context.Context, id uuid.UUID) error {
+	err := pgx.BeginFunc(ctx, repo.db.Client, func(tx pgx.Tx) error {
+		before, err := repo.lock(ctx, tx, lockActiveQuery, id)
+		if err != nil {
+			return err
+		}
+
+		after := &domain.Subscription{}
+		err = scanSubscription(tx.QueryRow(ctx, deleteQuery, id), after)
+		if err != nil {
+			repo.logger.ErrorContext(ctx, "delete failed", "err", err)
+			return errors_package.Internal(err)
+		}
+
+		return repo.insertEvent(ctx, tx, domain.EventDeleted, id, before, after)
+	})
 	if err != nil {
-		repo.logger.Error("delete failed", "err", err)
 		return err
 	}
 
-	if cmd.RowsAffected() == 0 {
-		return fmt.Errorf("subscription %s not found", id)
+	repo.logger.InfoContext(ctx, "successfully deleted subscription", "id", id)
+	return nil
+
+}
+
+// RestoreSubscription возвращает подписку из корзины, если за это время
+// не создана действующая подписка того же пользователя, сервиса и даты начала
+func (repo *PGSubscriptionRepository) RestoreSubscription(ctx context.Context,
+	id uuid.UUID) (*domain.Subscription, error) {
+	after := &domain.Subscription{}
+
+	err := pgx.BeginFunc(ctx, repo.db.Client, func(tx pgx.Tx) error {
+		before, err := repo.lock(ctx, tx, lockDeletedQuery, id)
+		if errors.Is(err, errors_package.ErrNotFound) {
+			return errors_package.NotFound("subscription %s not found in trash", id)
+		}
+		if err != nil {
+			return err
+		}
+
+		err = scanSubscription(tx.QueryRow(ctx, restoreQuery, id), after)
+		if isSlotTaken(err) {
+			return errors_package.Conflict("subscription %s cannot be restored: an active duplicate exists", id)
+		}
+		if err != nil {
+			repo.logger.ErrorContext(ctx, "restore failed", "err", err)
+			return errors_package.Internal(err)
+		}
+
+		return repo.insertEvent(ctx, tx, domain.EventRestored, id, before, after)
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	repo.logger.Info("successfully deleted subscription", "id", id)
-	return nil
+	repo.logger.InfoContext(ctx, "successfully restored subscription", "id", id)
+	return after, nil
+}
+
+// PurgeSubscriptions окончательно удаляет подписки, пролежавшие в корзине дольше retention;
+// журнал изменений удалённых подписок сохраняется
+func (repo *PGSubscriptionRepository) PurgeSubscriptions(ctx context.Context, retention time.Duration) (int64, error) {
+	var purged int64
+
+	err := pgx.BeginFunc(ctx, repo.db.Client, func(tx pgx.Tx) error {
+		rows, err := tx.Query(ctx, purgeQuery, retention.Seconds())
+		if err != nil {
+			repo.logger.ErrorContext(ctx, "purge failed", "err", err)
+			return errors_package.Internal(err)
+		}
 
+		removed, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (domain.Subscription, error) {
+			var sub domain.Subscription
+			err := scanSubscription(row, &sub)
+			return sub, err
+		})
+		if err != nil {
+			repo.logger.ErrorContext(ctx, "purge failed", "err", err)
+			return errors_package.Internal(err)
+		}
+
+		for i := range removed {
+			err = repo.insertEvent(ctx, tx, domain.EventPurged, removed[i].Id, &removed[i], nil)
+			if err != nil {
+				return err
+			}
+		}
+
+		purged = int64(len(removed))
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	repo.logger.InfoContext(ctx, "successfully purged subscriptions", "count", purged)
+	return purged, nil
 }
 
-func (repo *PGSubscriptionRepository) UpdateSubscription(ctx *gin.Context, sub *domain.Subscription) error {
-	_, err := repo.db.Client.Exec(ctx, updateQuery, sub.ServiceName, sub.Price, sub.StartDate, sub.EndDate, sub.UserId)
+// UpdateSubscription перезаписывает подписку, если её версия совпадает с sub.Version
+// (нулевая версия — без проверки), и сохраняет в sub новую версию
+func (repo *PGSubscriptionRepository) UpdateSubscription(ctx context.Context, sub *domain.Subscription) error {
+	err := pgx.BeginFunc(ctx, repo.db.Client, func(tx pgx.Tx) error {
+		before, err := repo.lock(ctx, tx, lockActiveQuery, sub.Id)
+		if err != nil {
+			return err
+		}
+
+		if sub.Version != 0 && sub.Version != before.Version {
+			repo.logger.InfoContext(ctx, "subscription version mismatch",
+				"id", sub.Id, "expected", sub.Version, "actual", before.Version)
+			return errors_package.PreconditionFailed("subscription %s has version %d, expected %d",
+				sub.Id, before.Version, sub.Version)
+		}
+
+		after := &domain.Subscription{}
+		err = scanSubscription(tx.QueryRow(ctx, updateQuery, sub.ServiceName, sub.Price,
+			sub.BillingInterval, sub.IntervalCount, sub.StartDate, nullDate(sub.EndDate), sub.Id), after)
+		if isSlotTaken(err) {
+			return slotConflict(before.UserId, sub.ServiceName, sub.StartDate)
+		}
+		if err != nil {
+			log.Printf("error updating subscription: %v", err)
+			return errors_package.Internal(err)
+		}
+
+		sub.UpdatedAt, sub.Version = after.UpdatedAt, after.Version
+
+		return repo.insertEvent(ctx, tx, domain.EventUpdated, sub.Id, before, after)
+	})
 	if err != nil {
-		log.Printf("error updating subscription: %v", err)
 		return err
 	}
 
-	repo.logger.Info("successfully updated subscription")
+	repo.logger.InfoContext(ctx, "successfully updated subscription", "id", sub.Id, "version", sub.Version)
 	return nil
 }
 
-func (repo *PGSubscriptionRepository) ListSubscriptions(ctx *gin.Context,
+// lock читает подписку и блокирует её строку до конца транзакции
+func (repo *PGSubscriptionRepository) lock(ctx context.Context, tx pgx.Tx, query string,
+	id uuid.UUID) (*domain.Subscription, error) {
+	sub := &domain.Subscription{}
+
+	err := scanSubscription(tx.QueryRow(ctx, query, id), sub)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, errors_package.NotFound("subscription %s not found", id)
+	}
+	if err != nil {
+		repo.logger.ErrorContext(ctx, "failed to lock subscription", "id", id, "err", err)
+		return nil, errors_package.Internal(err)
+	}
+
+	return sub, nil
+}
+
+func (repo *PGSubscriptionRepository) ListSubscriptions(ctx context.Context,
 	startDate, endDate time.Time,
 	userId uuid.UUID,
-	serviceName string) (uint64, error) {
+	serviceName string) ([]domain.Subscription, error) {
 
-	var total uint64
-	err := repo.db.Client.QueryRow(
+	rows, err := repo.db.Client.Query(
 		ctx, selectFilterQuery,
 		userId, serviceName, startDate, endDate,
-	).Scan(&total)
+	)
+	if err != nil {
+		log.Printf("error listing subscriptions for period: %v", err)
+		return nil, errors_package.Internal(err)
+	}
+	defer rows.Close()
 
+	var subscriptions []domain.Subscription
+	for rows.Next() {
+		var sub domain.Subscription
+		err = scanSubscription(rows, &sub)
+		if err != nil {
+			log.Printf("error scanning subscription: %v", err)
+			return nil, errors_package.Internal(err)
+		}
+
+		subscriptions = append(subscriptions, sub)
+	}
+
+	if err = rows.Err(); err != nil {
+		log.Printf("error listing subscriptions for period: %v", err)
+		return nil, errors_package.Internal(err)
+	}
+
+	repo.logger.InfoContext(ctx, "successfully list subscriptions")
+	return subscriptions, nil
+}
+
+func (repo *PGSubscriptionRepository) FindSubscriptions(ctx context.Context,
+	filter *domain.SubscriptionFilter) (*domain.SubscriptionPage, error) {
+
+	where, args := buildFilter(filter)
+
+	var total uint64
+	err := repo.db.Client.QueryRow(ctx, countPageQuery+where, args...).Scan(&total)
 	if err != nil {
-		log.Printf("error calculating total cost: %v", err)
-		return 0, err
+		repo.logger.ErrorContext(ctx, "failed to count subscriptions", "err", err)
+		return nil, errors_package.Internal(err)
+	}
+
+	query := fmt.Sprintf("%s%s%s LIMIT $%d OFFSET $%d",
+		selectPageQuery, where, orderBy(filter), len(args)+1, len(args)+2)
+	args = append(args, filter.Limit, filter.Offset)
+
+	rows, err := repo.db.Client.Query(ctx, query, args...)
+	if err != nil {
+		repo.logger.ErrorContext(ctx, "failed to find subscriptions", "err", err)
+		return nil, errors_package.Internal(err)
+	}
+	defer rows.Close()
+
+	items := make([]domain.Subscription, 0, filter.Limit)
+	for rows.Next() {
+		var sub domain.Subscription
+		err = scanSubscription(rows, &sub)
+		if err != nil {
+			repo.logger.ErrorContext(ctx, "failed to scan subscription", "err", err)
+			return nil, errors_package.Internal(err)
+		}
+
+		items = append(items, sub)
+	}
+
+	if err = rows.Err(); err != nil {
+		repo.logger.ErrorContext(ctx, "failed to iterate subscriptions", "err", err)
+		return nil, errors_package.Internal(err)
+	}
+
+	repo.logger.InfoContext(ctx, "successfully found subscriptions", "count", len(items), "total", total)
+	return &domain.SubscriptionPage{
+		Items: items,
+		Total: total,
+	}, nil
+}
+
+// StreamSubscriptions передаёт в fn все подписки по фильтру (Limit и Offset не учитываются),
+// читая их серверным курсором порциями по streamBatchSize строк; ошибка fn прерывает чтение
+func (repo *PGSubscriptionRepository) StreamSubscriptions(ctx context.Context, filter *domain.SubscriptionFilter,
+	fn func(sub *domain.Subscription) error) error {
+	where, args := buildFilter(filter)
+	declare := fmt.Sprintf("DECLARE %s NO SCROLL CURSOR FOR %s%s%s",
+		streamCursor, selectPageQuery, where, orderBy(filter))
+
+	var streamed int
+	err := pgx.BeginTxFunc(ctx, repo.db.Client, pgx.TxOptions{AccessMode: pgx.ReadOnly}, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, declare, args...); err != nil {
+			repo.logger.ErrorContext(ctx, "failed to declare subscriptions cursor", "err", err)
+			return errors_package.Internal(err)
+		}
+
+		for {
+			fetched, err := repo.fetch(ctx, tx, fn)
+			streamed += fetched
+			if err != nil || fetched < streamBatchSize {
+				return err
+			}
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	repo.logger.InfoContext(ctx, "successfully streamed subscriptions", "count", streamed)
+	return nil
+}
+
+// fetch читает из курсора очередную порцию подписок и возвращает число прочитанных строк
+func (repo *PGSubscriptionRepository) fetch(ctx context.Context, tx pgx.Tx,
+	fn func(sub *domain.Subscription) error) (int, error) {
+	rows, err := tx.Query(ctx, fmt.Sprintf("FETCH %d FROM %s", streamBatchSize, streamCursor))
+	if err != nil {
+		repo.logger.ErrorContext(ctx, "failed to fetch subscriptions", "err", err)
+		return 0, errors_package.Internal(err)
+	}
+	defer rows.Close()
+
+	var fetched int
+	for rows.Next() {
+		var sub domain.Subscription
+		if err = scanSubscription(rows, &sub); err != nil {
+			repo.logger.ErrorContext(ctx, "failed to scan subscription", "err", err)
+			return fetched, errors_package.Internal(err)
+		}
+
+		fetched++
+		if err = fn(&sub); err != nil {
+			return fetched, err
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		repo.logger.ErrorContext(ctx, "failed to fetch subscriptions", "err", err)
+		return fetched, errors_package.Internal(err)
+	}
+
+	return fetched, nil
+}
+
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == uniqueViolationCode
+}
+
+// isSlotTaken сообщает, что запись нарушила уникальность slotIndex
+func isSlotTaken(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == uniqueViolationCode && pgErr.ConstraintName == slotIndex
+}
+
+func slotConflict(userId uuid.UUID, serviceName string, startDate time.Time) error {
+	return errors_package.Conflict("subscription already exists for user %s service %s at %s",
+		userId, serviceName, startDate.Format("2006-01-02"))
+}
+
+func scanSubscription(row pgx.Row, sub *domain.Subscription) error {
+	var end *time.Time
+
+	err := row.Scan(&sub.Id, &sub.ServiceName, &sub.Price, &sub.BillingInterval, &sub.IntervalCount,
+		&sub.UserId, &sub.StartDate, &end, &sub.CreatedAt, &sub.UpdatedAt, &sub.Version, &sub.DeletedAt)
+	if err != nil {
+		return err
+	}
+
+	sub.EndDate = time.Time{}
+	if end != nil {
+		sub.EndDate = *end
+	}
+
+	return nil
+}
+
+// nullDate записывает дату окончания бессрочной подписки как NULL
+func nullDate(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+
+	return &t
+}
+
+// orderBy строит ORDER BY по полю сортировки фильтра с id для однозначного порядка
+func orderBy(filter *domain.SubscriptionFilter) string {
+	column, ok := sortColumns[filter.SortBy]
+	if !ok {
+		column = "created_at"
+	}
+
+	direction := "ASC"
+	if filter.SortDesc {
+		direction = "DESC"
+	}
+
+	return fmt.Sprintf(" ORDER BY %s %s, id ASC", column, direction)
+}
+
+func buildFilter(filter *domain.SubscriptionFilter) (string, []any) {
+	var (
+		conditions = []string{"deleted_at IS NULL"}
+		args       []any
+	)
+
+	if filter.Deleted {
+		conditions[0] = "deleted_at IS NOT NULL"
+	}
+
+	add := func(condition string, value any) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if filter.UserID != uuid.Nil {
+		add("user_id = $%d", filter.UserID)
+	}
+
+	if filter.ServiceName != "" {
+		add("service_name = $%d", filter.ServiceName)
+	}
+
+	if filter.ActiveAt != nil {
+		add("start_date < $%d", filter.ActiveAt.AddDate(0, 1, 0))
+		add("(end_date IS NULL OR end_date >= $%d)", *filter.ActiveAt)
+	}
+
+	if filter.MinPrice != nil {
+		add("price >= $%d", *filter.MinPrice)
+	}
+
+	if filter.MaxPrice != nil {
+		add("price <= $%d", *filter.MaxPrice)
 	}
 
-	repo.logger.Info("successfully list subscriptions")
-	return total, nil
+	return " WHERE " + strings.Join(conditions, " AND "), args
 }
@@ -0,0 +1,276 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/Aiszhio/Task/internal/db"
+	"github.com/Aiszhio/Task/internal/domain"
+	errors_package "github.com/Aiszhio/Task/internal/errors"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// webhookColumns и deliveryColumns — порядок столбцов, который ожидают scanWebhook и scanDelivery
+const (
+	webhookColumns = `id, url, event_types, secret, created_at`
+
+	deliveryColumns = `d.id, d.webhook_id, d.event_id, d.event_type, d.payload, d.status, d.attempts,
+						d.next_attempt_at, d.last_attempt_at, COALESCE(d.response_status, 0),
+						COALESCE(d.last_error, ''), d.created_at, d.delivered_at`
+)
+
+const (
+	insertWebhookQuery = `INSERT INTO webhooks (id, url, event_types, secret)
+						VALUES ($1, $2, $3, $4)
+						RETURNING ` + webhookColumns + `;`
+
+	selectWebhooksQuery = `SELECT ` + webhookColumns + `
+						FROM webhooks
+						ORDER BY created_at, id;`
+
+	selectWebhookQuery = `SELECT ` + webhookColumns + `
+						FROM webhooks
+						WHERE id = $1;`
+
+	deleteWebhookQuery = `DELETE FROM webhooks
+						WHERE id = $1;`
+
+	// enqueueDeliveriesQuery заводит доставку на каждый вебхук, подписанный на событие;
+	// повторная публикация того же события ничего не добавляет
+	enqueueDeliveriesQuery = `INSERT INTO webhook_deliveries (id, webhook_id, event_id, event_type, payload,
+							next_attempt_at)
+						SELECT gen_random_uuid(), id, $1, $2, $3, $4
+						FROM webhooks
+						WHERE $2 = ANY (event_types)
+						ON CONFLICT (webhook_id, event_id) DO NOTHING;`
+
+	// claimDeliveriesQuery откладывает наступившие доставки на время аренды, чтобы
+	// другие реплики их не взяли, пока эта отправляет запросы
+	claimDeliveriesQuery = `WITH due AS (
+							SELECT id
+							FROM webhook_deliveries
+							WHERE status = 'pending'
+							  AND next_attempt_at <= $1
+							ORDER BY next_attempt_at
+							LIMIT $3
+							FOR UPDATE SKIP LOCKED
+						)
+						UPDATE webhook_deliveries d
+						SET next_attempt_at = $2
+						FROM due, webhooks w
+						WHERE d.id = due.id
+						  AND w.id = d.webhook_id
+						RETURNING ` + deliveryColumns + `, w.url, w.secret;`
+
+	updateDeliveryQuery = `UPDATE webhook_deliveries
+						SET status          = $2,
+							attempts        = $3,
+							next_attempt_at = $4,
+							last_attempt_at = $5,
+							response_status = NULLIF($6, 0),
+							last_error      = NULLIF($7, ''),
+							delivered_at    = $8
+						WHERE id = $1;`
+
+	selectDeliveriesQuery = `SELECT ` + deliveryColumns + `
+						FROM webhook_deliveries d
+						WHERE d.webhook_id = $1
+						  AND ($2 = '' OR d.status = $2)
+						ORDER BY d.created_at DESC, d.id
+						LIMIT $3;`
+)
+
+type WebhookRepository interface {
+	CreateWebhook(ctx context.Context, webhook *domain.Webhook) error
+	ListWebhooks(ctx context.Context) ([]domain.Webhook, error)
+	ReadWebhook(ctx context.Context, id uuid.UUID) (*domain.Webhook, error)
+	// DeleteWebhook удаляет вебхук вместе с журналом его доставок
+	DeleteWebhook(ctx context.Context, id uuid.UUID) error
+	// EnqueueDeliveries заводит доставки события на подписанные вебхуки и возвращает число новых
+	EnqueueDeliveries(ctx context.Context, event *domain.WebhookEvent, payload []byte) (int64, error)
+	// ClaimDeliveries берёт в работу до limit доставок, срок которых наступил к now, и откладывает
+	// их на lease: если попытка не будет записана, доставка вернётся в очередь после аренды
+	ClaimDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]domain.WebhookJob, error)
+	// SaveDelivery записывает итог попытки доставки
+	SaveDelivery(ctx context.Context, delivery *domain.WebhookDelivery) error
+	// ListDeliveries возвращает журнал доставок вебхука, начиная с новых; пустой status — все
+	ListDeliveries(ctx context.Context, webhookId uuid.UUID, status domain.DeliveryStatus,
+		limit int) ([]domain.WebhookDelivery, error)
+}
+
+type PGWebhookRepository struct {
+	db     *db.Pool
+	logger *slog.Logger
+}
+
+func NewWebhookRepository(pool *db.Pool, logger *slog.Logger) *PGWebhookRepository {
+	return &PGWebhookRepository{
+		db:     pool,
+		logger: logger,
+	}
+}
+
+func (repo *PGWebhookRepository) CreateWebhook(ctx context.Context, webhook *domain.Webhook) error {
+	row := repo.db.Client.QueryRow(ctx, insertWebhookQuery, webhook.Id, webhook.URL, webhook.EventTypes,
+		webhook.Secret)
+
+	err := scanWebhook(row, webhook)
+	if isUniqueViolation(err) {
+		return errors_package.Conflict("webhook %s already exists", webhook.Id)
+	}
+	if err != nil {
+		repo.logger.ErrorContext(ctx, "failed to create webhook", "err", err)
+		return errors_package.Internal(err)
+	}
+
+	return nil
+}
+
+func (repo *PGWebhookRepository) ListWebhooks(ctx context.Context) ([]domain.Webhook, error) {
+	rows, err := repo.db.Client.Query(ctx, selectWebhooksQuery)
+	if err != nil {
+		repo.logger.ErrorContext(ctx, "failed to list webhooks", "err", err)
+		return nil, errors_package.Internal(err)
+	}
+	defer rows.Close()
+
+	webhooks := []domain.Webhook{}
+	for rows.Next() {
+		var webhook domain.Webhook
+		if err = scanWebhook(rows, &webhook); err != nil {
+			repo.logger.ErrorContext(ctx, "failed to scan webhook", "err", err)
+			return nil, errors_package.Internal(err)
+		}
+		webhooks = append(webhooks, webhook)
+	}
+
+	if err = rows.Err(); err != nil {
+		repo.logger.ErrorContext(ctx, "failed to list webhooks", "err", err)
+		return nil, errors_package.Internal(err)
+	}
+
+	return webhooks, nil
+}
+
+func (repo *PGWebhookRepository) ReadWebhook(ctx context.Context, id uuid.UUID) (*domain.Webhook, error) {
+	webhook := &domain.Webhook{}
+
+	err := scanWebhook(repo.db.Client.QueryRow(ctx, selectWebhookQuery, id), webhook)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, errors_package.NotFound("webhook %s not found", id)
+	}
+	if err != nil {
+		repo.logger.ErrorContext(ctx, "failed to read webhook", "err", err)
+		return nil, errors_package.Internal(err)
+	}
+
+	return webhook, nil
+}
+
+func (repo *PGWebhookRepository) DeleteWebhook(ctx context.Context, id uuid.UUID) error {
+	tag, err := repo.db.Client.Exec(ctx, deleteWebhookQuery, id)
+	if err != nil {
+		repo.logger.ErrorContext(ctx, "failed to delete webhook", "err", err)
+		return errors_package.Internal(err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return errors_package.NotFound("webhook %s not found", id)
+	}
+
+	return nil
+}
+
+func (repo *PGWebhookRepository) EnqueueDeliveries(ctx context.Context, event *domain.WebhookEvent,
+	payload []byte) (int64, error) {
+	tag, err := repo.db.Client.Exec(ctx, enqueueDeliveriesQuery, event.Id, string(event.Type), payload,
+		event.OccurredAt)
+	if err != nil {
+		repo.logger.ErrorContext(ctx, "failed to enqueue webhook deliveries", "event", event.Type, "err", err)
+		return 0, errors_package.Internal(err)
+	}
+
+	return tag.RowsAffected(), nil
+}
+
+func (repo *PGWebhookRepository) ClaimDeliveries(ctx context.Context, now time.Time, lease time.Duration,
+	limit int) ([]domain.WebhookJob, error) {
+	rows, err := repo.db.Client.Query(ctx, claimDeliveriesQuery, now, now.Add(lease), limit)
+	if err != nil {
+		repo.logger.ErrorContext(ctx, "failed to claim webhook deliveries", "err", err)
+		return nil, errors_package.Internal(err)
+	}
+	defer rows.Close()
+
+	jobs := []domain.WebhookJob{}
+	for rows.Next() {
+		var job domain.WebhookJob
+		if err = scanDelivery(rows, &job.Delivery, &job.URL, &job.Secret); err != nil {
+			repo.logger.ErrorContext(ctx, "failed to scan webhook delivery", "err", err)
+			return nil, errors_package.Internal(err)
+		}
+		jobs = append(jobs, job)
+	}
+
+	if err = rows.Err(); err != nil {
+		repo.logger.ErrorContext(ctx, "failed to claim webhook deliveries", "err", err)
+		return nil, errors_package.Internal(err)
+	}
+
+	return jobs, nil
+}
+
+func (repo *PGWebhookRepository) SaveDelivery(ctx context.Context, delivery *domain.WebhookDelivery) error {
+	_, err := repo.db.Client.Exec(ctx, updateDeliveryQuery, delivery.Id, string(delivery.Status), delivery.Attempts,
+		delivery.NextAttemptAt, delivery.LastAttemptAt, delivery.ResponseStatus, delivery.LastError,
+		delivery.DeliveredAt)
+	if err != nil {
+		repo.logger.ErrorContext(ctx, "failed to save webhook delivery", "delivery", delivery.Id, "err", err)
+		return errors_package.Internal(err)
+	}
+
+	return nil
+}
+
+func (repo *PGWebhookRepository) ListDeliveries(ctx context.Context, webhookId uuid.UUID,
+	status domain.DeliveryStatus, limit int) ([]domain.WebhookDelivery, error) {
+	rows, err := repo.db.Client.Query(ctx, selectDeliveriesQuery, webhookId, string(status), limit)
+	if err != nil {
+		repo.logger.ErrorContext(ctx, "failed to list webhook deliveries", "err", err)
+		return nil, errors_package.Internal(err)
+	}
+	defer rows.Close()
+
+	deliveries := []domain.WebhookDelivery{}
+	for rows.Next() {
+		var delivery domain.WebhookDelivery
+		if err = scanDelivery(rows, &delivery); err != nil {
+			repo.logger.ErrorContext(ctx, "failed to scan webhook delivery", "err", err)
+			return nil, errors_package.Internal(err)
+		}
+		deliveries = append(deliveries, delivery)
+	}
+
+	if err = rows.Err(); err != nil {
+		repo.logger.ErrorContext(ctx, "failed to list webhook deliveries", "err", err)
+		return nil, errors_package.Internal(err)
+	}
+
+	return deliveries, nil
+}
+
+func scanWebhook(row pgx.Row, webhook *domain.Webhook) error {
+	return row.Scan(&webhook.Id, &webhook.URL, &webhook.EventTypes, &webhook.Secret, &webhook.CreatedAt)
+}
+
+// scanDelivery читает столбцы deliveryColumns и, если переданы, следующие за ними столбцы extra
+func scanDelivery(row pgx.Row, delivery *domain.WebhookDelivery, extra ...any) error {
+	dest := []any{&delivery.Id, &delivery.WebhookId, &delivery.EventId, &delivery.EventType, &delivery.Payload,
+		&delivery.Status, &delivery.Attempts, &delivery.NextAttemptAt, &delivery.LastAttemptAt,
+		&delivery.ResponseStatus, &delivery.LastError, &delivery.CreatedAt, &delivery.DeliveredAt}
+
+	return row.Scan(append(dest, extra...)...)
+}
@@ -0,0 +1,175 @@
+package repotest
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/Aiszhio/Task/internal/domain"
+	errors_package "github.com/Aiszhio/Task/internal/errors"
+	"github.com/Aiszhio/Task/internal/repository/postgres"
+	"github.com/google/uuid"
+)
+
+func runAPIKeys(t *testing.T, newStores Factory) {
+	t.Run("CreateAndFind", func(t *testing.T) { testAPIKeyCreateAndFind(t, newStores(t).APIKeys) })
+	t.Run("Revoke", func(t *testing.T) { testAPIKeyRevoke(t, newStores(t).APIKeys) })
+	t.Run("Rotate", func(t *testing.T) { testAPIKeyRotate(t, newStores(t).APIKeys) })
+	t.Run("Touch", func(t *testing.T) { testAPIKeyTouch(t, newStores(t).APIKeys) })
+}
+
+func newAPIKey(hash string, scopes ...string) *domain.APIKey {
+	return &domain.APIKey{
+		Id:     uuid.New(),
+		Name:   "billing",
+		Prefix: "sk_" + hash,
+		Hash:   hash,
+		Scopes: scopes,
+	}
+}
+
+func mustCreateKey(t *testing.T, repo postgres.APIKeyRepository, key *domain.APIKey) {
+	t.Helper()
+
+	if err := repo.CreateAPIKey(context.Background(), key); err != nil {
+		t.Fatalf("CreateAPIKey: %v", err)
+	}
+}
+
+func testAPIKeyCreateAndFind(t *testing.T, repo postgres.APIKeyRepository) {
+	ctx := context.Background()
+
+	key := newAPIKey("hash-1", "subscriptions:read", "reports:read")
+	mustCreateKey(t, repo, key)
+
+	if key.CreatedAt.IsZero() {
+		t.Errorf("CreateAPIKey did not set CreatedAt")
+	}
+
+	found, err := repo.FindAPIKey(ctx, "hash-1")
+	if err != nil {
+		t.Fatalf("FindAPIKey: %v", err)
+	}
+
+	if found.Id != key.Id || found.Name != key.Name || !slices.Equal(found.Scopes, key.Scopes) || !found.Active() {
+		t.Errorf("FindAPIKey = %+v, want %+v", found, key)
+	}
+
+	if _, err = repo.FindAPIKey(ctx, "hash-2"); !errors.Is(err, errors_package.ErrNotFound) {
+		t.Errorf("FindAPIKey of an unknown hash = %v, want ErrNotFound", err)
+	}
+
+	if err = repo.CreateAPIKey(ctx, newAPIKey("hash-1")); !errors.Is(err, errors_package.ErrConflict) {
+		t.Errorf("CreateAPIKey with a duplicate hash = %v, want ErrConflict", err)
+	}
+
+	mustCreateKey(t, repo, newAPIKey("hash-2"))
+
+	keys, err := repo.ListAPIKeys(ctx)
+	if err != nil {
+		t.Fatalf("ListAPIKeys: %v", err)
+	}
+
+	if len(keys) != 2 || keys[0].Id != key.Id {
+		t.Errorf("ListAPIKeys = %+v, want 2 keys starting with %s", keys, key.Id)
+	}
+}
+
+func testAPIKeyRevoke(t *testing.T, repo postgres.APIKeyRepository) {
+	ctx := context.Background()
+
+	key := newAPIKey("hash-1", "subscriptions:read")
+	mustCreateKey(t, repo, key)
+
+	revoked, err := repo.RevokeAPIKey(ctx, key.Id)
+	if err != nil {
+		t.Fatalf("RevokeAPIKey: %v", err)
+	}
+
+	if revoked.Active() {
+		t.Errorf("RevokeAPIKey = %+v, want a revoked key", revoked)
+	}
+
+	again, err := repo.RevokeAPIKey(ctx, key.Id)
+	if err != nil || !again.RevokedAt.Equal(*revoked.RevokedAt) {
+		t.Errorf("second RevokeAPIKey = %+v, %v, want the same revocation time", again, err)
+	}
+
+	found, err := repo.FindAPIKey(ctx, "hash-1")
+	if err != nil || found.Active() {
+		t.Errorf("FindAPIKey of a revoked key = %+v, %v, want the revoked key", found, err)
+	}
+
+	if _, err = repo.RevokeAPIKey(ctx, uuid.New()); !errors.Is(err, errors_package.ErrNotFound) {
+		t.Errorf("RevokeAPIKey of a missing key = %v, want ErrNotFound", err)
+	}
+}
+
+func testAPIKeyRotate(t *testing.T, repo postgres.APIKeyRepository) {
+	ctx := context.Background()
+
+	key := newAPIKey("hash-1", "subscriptions:write")
+	mustCreateKey(t, repo, key)
+
+	replacement := &domain.APIKey{Id: uuid.New(), Prefix: "sk_hash-2", Hash: "hash-2"}
+	if err := repo.RotateAPIKey(ctx, key.Id, replacement); err != nil {
+		t.Fatalf("RotateAPIKey: %v", err)
+	}
+
+	if replacement.Name != key.Name || !slices.Equal(replacement.Scopes, key.Scopes) {
+		t.Errorf("RotateAPIKey replacement = %+v, want the name and scopes of %+v", replacement, key)
+	}
+
+	old, err := repo.FindAPIKey(ctx, "hash-1")
+	if err != nil || old.Active() {
+		t.Errorf("FindAPIKey of the rotated key = %+v, %v, want a revoked key", old, err)
+	}
+
+	fresh, err := repo.FindAPIKey(ctx, "hash-2")
+	if err != nil || !fresh.Active() || fresh.Id != replacement.Id {
+		t.Errorf("FindAPIKey of the replacement = %+v, %v, want an active key %s", fresh, err, replacement.Id)
+	}
+
+	err = repo.RotateAPIKey(ctx, key.Id, &domain.APIKey{Id: uuid.New(), Hash: "hash-3"})
+	if !errors.Is(err, errors_package.ErrNotFound) {
+		t.Errorf("RotateAPIKey of a revoked key = %v, want ErrNotFound", err)
+	}
+}
+
+func testAPIKeyTouch(t *testing.T, repo postgres.APIKeyRepository) {
+	ctx := context.Background()
+
+	key := newAPIKey("hash-1")
+	mustCreateKey(t, repo, key)
+
+	first := time.Now().UTC().Truncate(time.Second)
+	if err := repo.TouchAPIKey(ctx, key.Id, first, time.Minute); err != nil {
+		t.Fatalf("TouchAPIKey: %v", err)
+	}
+
+	// повторное использование в пределах точности не перезаписывает время
+	if err := repo.TouchAPIKey(ctx, key.Id, first.Add(time.Second), time.Minute); err != nil {
+		t.Fatalf("TouchAPIKey: %v", err)
+	}
+
+	found, err := repo.FindAPIKey(ctx, "hash-1")
+	if err != nil {
+		t.Fatalf("FindAPIKey: %v", err)
+	}
+
+	if found.LastUsedAt == nil || !found.LastUsedAt.Equal(first) {
+		t.Errorf("LastUsedAt = %v, want %v", found.LastUsedAt, first)
+	}
+
+	later := first.Add(2 * time.Minute)
+	if err = repo.TouchAPIKey(ctx, key.Id, later, time.Minute); err != nil {
+		t.Fatalf("TouchAPIKey: %v", err)
+	}
+
+	found, err = repo.FindAPIKey(ctx, "hash-1")
+	if err != nil || found.LastUsedAt == nil || !found.LastUsedAt.Equal(later) {
+		t.Errorf("LastUsedAt after %v = %+v, %v, want %v", later, found, err, later)
+	}
+}
@@ -0,0 +1,154 @@
+package repotest
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Aiszhio/Task/internal/repository/postgres"
+)
+
+func runIdempotency(t *testing.T, newStores Factory) {
+	t.Run("ClaimAndReplay", func(t *testing.T) { testClaimAndReplay(t, newStores(t).Idempotency) })
+	t.Run("Release", func(t *testing.T) { testRelease(t, newStores(t).Idempotency) })
+	t.Run("Expire", func(t *testing.T) { testExpire(t, newStores(t).Idempotency) })
+	t.Run("Abandoned", func(t *testing.T) { testAbandoned(t, newStores(t).Idempotency) })
+}
+
+// keyLease — срок выполнения запроса в проверках, где он не истекает
+const keyLease = time.Minute
+
+func mustClaim(t *testing.T, repo postgres.IdempotencyRepository, key, hash string, ttl time.Duration) bool {
+	t.Helper()
+
+	_, claimed, err := repo.ClaimKey(context.Background(), key, hash, ttl, min(ttl, keyLease))
+	if err != nil {
+		t.Fatalf("ClaimKey(%q): %v", key, err)
+	}
+
+	return claimed
+}
+
+func testClaimAndReplay(t *testing.T, repo postgres.IdempotencyRepository) {
+	ctx := context.Background()
+
+	req, claimed, err := repo.ClaimKey(ctx, "key-1", "hash-1", time.Hour, keyLease)
+	if err != nil || !claimed {
+		t.Fatalf("ClaimKey of a new key = %v, %v, want claimed", claimed, err)
+	}
+
+	pending, claimed, err := repo.ClaimKey(ctx, "key-1", "hash-1", time.Hour, keyLease)
+	if err != nil || claimed || pending.Completed() {
+		t.Fatalf("ClaimKey of a pending key = %+v, %v, %v, want the pending request", pending, claimed, err)
+	}
+
+	req.StatusCode = http.StatusCreated
+	req.ContentType = "application/json"
+	req.Body = []byte(`{"ok":true}`)
+	if err = repo.SaveResponse(ctx, req); err != nil {
+		t.Fatalf("SaveResponse: %v", err)
+	}
+
+	stored, claimed, err := repo.ClaimKey(ctx, "key-1", "hash-2", time.Hour, keyLease)
+	if err != nil || claimed {
+		t.Fatalf("ClaimKey of a completed key = %v, %v, want the stored request", claimed, err)
+	}
+
+	if stored.RequestHash != "hash-1" || stored.StatusCode != http.StatusCreated ||
+		stored.ContentType != "application/json" || string(stored.Body) != `{"ok":true}` {
+		t.Errorf("ClaimKey of a completed key = %+v, want the saved response of hash-1", stored)
+	}
+
+	if !mustClaim(t, repo, "key-2", "hash-1", time.Hour) {
+		t.Errorf("ClaimKey of another key was not claimed")
+	}
+}
+
+func testRelease(t *testing.T, repo postgres.IdempotencyRepository) {
+	ctx := context.Background()
+
+	req, claimed, err := repo.ClaimKey(ctx, "key-1", "hash-1", time.Hour, keyLease)
+	if err != nil || !claimed {
+		t.Fatalf("ClaimKey = %v, %v, want claimed", claimed, err)
+	}
+
+	if err = repo.ReleaseKey(ctx, req); err != nil {
+		t.Fatalf("ReleaseKey: %v", err)
+	}
+
+	if !mustClaim(t, repo, "key-1", "hash-1", time.Hour) {
+		t.Fatalf("ClaimKey after release was not claimed")
+	}
+}
+
+func testExpire(t *testing.T, repo postgres.IdempotencyRepository) {
+	ctx := context.Background()
+	mustClaim(t, repo, "expired", "hash-1", time.Millisecond)
+	mustClaim(t, repo, "alive", "hash-1", time.Hour)
+
+	time.Sleep(10 * time.Millisecond)
+
+	deleted, err := repo.DeleteExpiredKeys(ctx)
+	if err != nil {
+		t.Fatalf("DeleteExpiredKeys: %v", err)
+	}
+
+	if deleted != 1 {
+		t.Errorf("DeleteExpiredKeys = %d, want 1", deleted)
+	}
+
+	if mustClaim(t, repo, "alive", "hash-1", time.Hour) {
+		t.Errorf("ClaimKey of a live key was claimed again")
+	}
+
+	mustClaim(t, repo, "reclaimed", "hash-1", time.Millisecond)
+	time.Sleep(10 * time.Millisecond)
+
+	if !mustClaim(t, repo, "reclaimed", "hash-2", time.Hour) {
+		t.Errorf("ClaimKey of an expired key was not claimed")
+	}
+}
+
+func testAbandoned(t *testing.T, repo postgres.IdempotencyRepository) {
+	ctx := context.Background()
+
+	first, claimed, err := repo.ClaimKey(ctx, "key-1", "hash-1", time.Hour, time.Millisecond)
+	if err != nil || !claimed {
+		t.Fatalf("ClaimKey = %v, %v, want claimed", claimed, err)
+	}
+
+	time.Sleep(10 * time.Millisecond)
+
+	if mustClaim(t, repo, "key-1", "hash-2", time.Hour) {
+		t.Errorf("ClaimKey of an abandoned key with another request was claimed")
+	}
+
+	retry, claimed, err := repo.ClaimKey(ctx, "key-1", "hash-1", time.Hour, keyLease)
+	if err != nil || !claimed {
+		t.Fatalf("ClaimKey of an abandoned key = %v, %v, want claimed", claimed, err)
+	}
+
+	// опоздавший первый запрос не освобождает ключ и не записывает ответ поверх повтора
+	if err = repo.ReleaseKey(ctx, first); err != nil {
+		t.Fatalf("ReleaseKey: %v", err)
+	}
+	first.StatusCode = http.StatusInternalServerError
+	if err = repo.SaveResponse(ctx, first); err != nil {
+		t.Fatalf("SaveResponse: %v", err)
+	}
+
+	if mustClaim(t, repo, "key-1", "hash-1", time.Hour) {
+		t.Fatalf("ClaimKey during the retry was claimed")
+	}
+
+	retry.StatusCode = http.StatusCreated
+	if err = repo.SaveResponse(ctx, retry); err != nil {
+		t.Fatalf("SaveResponse: %v", err)
+	}
+
+	stored, claimed, err := repo.ClaimKey(ctx, "key-1", "hash-1", time.Hour, keyLease)
+	if err != nil || claimed || stored.StatusCode != http.StatusCreated {
+		t.Errorf("ClaimKey after the retry = %+v, %v, %v, want the response of the retry", stored, claimed, err)
+	}
+}
@@ -0,0 +1,306 @@
+package repotest
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Aiszhio/Task/internal/domain"
+	"github.com/Aiszhio/Task/internal/repository/postgres"
+	"github.com/google/uuid"
+)
+
+func runOutbox(t *testing.T, newStores Factory) {
+	t.Run("Changes", func(t *testing.T) { testOutboxChanges(t, newStores(t)) })
+	t.Run("Ordering", func(t *testing.T) { testOutboxOrdering(t, newStores(t)) })
+	t.Run("GiveUp", func(t *testing.T) { testOutboxGiveUp(t, newStores(t)) })
+	t.Run("Enqueue", func(t *testing.T) { testOutboxEnqueue(t, newStores(t)) })
+	t.Run("DeletePublished", func(t *testing.T) { testOutboxDeletePublished(t, newStores(t)) })
+	t.Run("ConcurrentRelays", func(t *testing.T) { testOutboxConcurrentRelays(t, newStores(t)) })
+}
+
+var outboxRetry = domain.RetryPolicy{Base: time.Minute, Max: time.Hour}
+
+// relayAll публикует всё, что готово к now, и возвращает события в порядке публикации
+func relayAll(t *testing.T, outbox postgres.OutboxRepository, now time.Time) []domain.WebhookEvent {
+	t.Helper()
+
+	var published []domain.WebhookEvent
+	for {
+		relayed, err := outbox.RelayMessages(context.Background(), now, 10, outboxRetry,
+			func(_ context.Context, msg *domain.OutboxMessage) error {
+				published = append(published, msg.Event)
+				return nil
+			})
+		if err != nil {
+			t.Fatalf("RelayMessages: %v", err)
+		}
+		if relayed == 0 {
+			return published
+		}
+	}
+}
+
+func eventTypes(events []domain.WebhookEvent) []domain.WebhookEventType {
+	types := make([]domain.WebhookEventType, 0, len(events))
+	for _, event := range events {
+		types = append(types, event.Type)
+	}
+
+	return types
+}
+
+func testOutboxChanges(t *testing.T, stores Stores) {
+	ctx := context.Background()
+	repo, outbox := stores.Subscriptions, stores.Outbox
+
+	sub := newSubscription(uuid.New(), "Okko", 300, month(time.July, 2025), month(time.July, 2025))
+	mustCreate(t, repo, sub)
+
+	sub.Price = 350
+	if err := repo.UpdateSubscription(ctx, sub); err != nil {
+		t.Fatalf("UpdateSubscription: %v", err)
+	}
+	if err := repo.DeleteSubscription(ctx, sub.Id); err != nil {
+		t.Fatalf("DeleteSubscription: %v", err)
+	}
+	if _, err := repo.RestoreSubscription(ctx, sub.Id); err != nil {
+		t.Fatalf("RestoreSubscription: %v", err)
+	}
+
+	published := relayAll(t, outbox, time.Now().UTC().Add(time.Second))
+
+	want := []domain.WebhookEventType{domain.WebhookSubscriptionCreated, domain.WebhookSubscriptionUpdated,
+		domain.WebhookSubscriptionDeleted, domain.WebhookSubscriptionUpdated}
+	if got := eventTypes(published); !slices.Equal(got, want) {
+		t.Fatalf("published %v, want %v", got, want)
+	}
+
+	if updated := published[1].Subscription; updated.Id != sub.Id || updated.Price != 350 {
+		t.Errorf("updated event carries %+v, want the subscription after the change", updated)
+	}
+	if deleted := published[2].Subscription; deleted.DeletedAt == nil {
+		t.Errorf("deleted event carries %+v, want the subscription in trash", deleted)
+	}
+
+	if again := relayAll(t, outbox, time.Now().UTC().Add(time.Second)); len(again) != 0 {
+		t.Errorf("second relay published %v, want nothing", eventTypes(again))
+	}
+
+	if err := repo.DeleteSubscription(ctx, sub.Id); err != nil {
+		t.Fatalf("DeleteSubscription: %v", err)
+	}
+	time.Sleep(10 * time.Millisecond)
+	if _, err := repo.PurgeSubscriptions(ctx, 0); err != nil {
+		t.Fatalf("PurgeSubscriptions: %v", err)
+	}
+
+	want = []domain.WebhookEventType{domain.WebhookSubscriptionDeleted}
+	if got := eventTypes(relayAll(t, outbox, time.Now().UTC().Add(time.Second))); !slices.Equal(got, want) {
+		t.Errorf("published after purge %v, want %v: purge is not published", got, want)
+	}
+}
+
+func testOutboxOrdering(t *testing.T, stores Stores) {
+	ctx := context.Background()
+	repo, outbox := stores.Subscriptions, stores.Outbox
+
+	failing := newSubscription(uuid.New(), "Okko", 300, month(time.July, 2025), month(time.July, 2025))
+	other := newSubscription(uuid.New(), "Ivi", 200, month(time.July, 2025), month(time.July, 2025))
+	mustCreate(t, repo, failing)
+	mustCreate(t, repo, other)
+
+	failing.Price = 350
+	if err := repo.UpdateSubscription(ctx, failing); err != nil {
+		t.Fatalf("UpdateSubscription: %v", err)
+	}
+
+	now := time.Now().UTC().Add(time.Second)
+
+	var published []domain.WebhookEvent
+	relayed, err := outbox.RelayMessages(ctx, now, 10, outboxRetry,
+		func(_ context.Context, msg *domain.OutboxMessage) error {
+			if msg.SubscriptionId == failing.Id {
+				return errors.New("sink is down")
+			}
+			published = append(published, msg.Event)
+			return nil
+		})
+	if err != nil || relayed != 2 {
+		t.Fatalf("RelayMessages = %d, %v, want the first message of each subscription", relayed, err)
+	}
+	if len(published) != 1 || published[0].Subscription.Id != other.Id {
+		t.Fatalf("published %+v, want only the other subscription", published)
+	}
+
+	if blocked := relayAll(t, outbox, now); len(blocked) != 0 {
+		t.Fatalf("published %v before the retry, want the failed subscription to wait", eventTypes(blocked))
+	}
+
+	retried := relayAll(t, outbox, now.Add(outboxRetry.Base))
+
+	want := []domain.WebhookEventType{domain.WebhookSubscriptionCreated, domain.WebhookSubscriptionUpdated}
+	if got := eventTypes(retried); !slices.Equal(got, want) {
+		t.Errorf("published after the retry %v, want %v in order", got, want)
+	}
+}
+
+func testOutboxGiveUp(t *testing.T, stores Stores) {
+	ctx := context.Background()
+	repo, outbox := stores.Subscriptions, stores.Outbox
+
+	sub := newSubscription(uuid.New(), "Okko", 300, month(time.July, 2025), month(time.July, 2025))
+	mustCreate(t, repo, sub)
+
+	sub.Price = 350
+	if err := repo.UpdateSubscription(ctx, sub); err != nil {
+		t.Fatalf("UpdateSubscription: %v", err)
+	}
+
+	retry := domain.RetryPolicy{MaxAttempts: 1, Base: time.Minute, Max: time.Hour}
+	now := time.Now().UTC().Add(time.Second)
+
+	relayed, err := outbox.RelayMessages(ctx, now, 10, retry,
+		func(context.Context, *domain.OutboxMessage) error { return errors.New("poison message") })
+	if err != nil || relayed != 1 {
+		t.Fatalf("RelayMessages = %d, %v, want the created event to fail", relayed, err)
+	}
+
+	want := []domain.WebhookEventType{domain.WebhookSubscriptionUpdated}
+	if got := eventTypes(relayAll(t, outbox, now)); !slices.Equal(got, want) {
+		t.Errorf("published after giving up %v, want %v without waiting for the failed message", got, want)
+	}
+
+	if again := relayAll(t, outbox, now.Add(retry.Max)); len(again) != 0 {
+		t.Errorf("published %v later, want the failed message never retried", eventTypes(again))
+	}
+}
+
+func testOutboxEnqueue(t *testing.T, stores Stores) {
+	ctx := context.Background()
+	repo, outbox := stores.Subscriptions, stores.Outbox
+
+	sub := newSubscription(uuid.New(), "Okko", 300, month(time.July, 2025), month(time.July, 2025))
+	mustCreate(t, repo, sub)
+	relayAll(t, outbox, time.Now().UTC().Add(time.Second))
+
+	endsAt, _ := sub.EndsAt()
+	event := domain.EndingSoonEvent(*sub, endsAt, time.Now().UTC())
+
+	for i, want := range []bool{true, false} {
+		added, err := outbox.EnqueueMessage(ctx, event)
+		if err != nil || added != want {
+			t.Fatalf("EnqueueMessage #%d = %v, %v, want %v", i+1, added, err, want)
+		}
+	}
+
+	published := relayAll(t, outbox, time.Now().UTC().Add(time.Second))
+	if len(published) != 1 || published[0].Id != event.Id {
+		t.Errorf("published %+v, want the enqueued event once", published)
+	}
+}
+
+func testOutboxDeletePublished(t *testing.T, stores Stores) {
+	ctx := context.Background()
+	repo, outbox := stores.Subscriptions, stores.Outbox
+
+	mustCreate(t, repo, newSubscription(uuid.New(), "Okko", 300, month(time.July, 2025), month(time.July, 2025)))
+	mustCreate(t, repo, newSubscription(uuid.New(), "Ivi", 200, month(time.July, 2025), month(time.July, 2025)))
+
+	_, err := outbox.RelayMessages(ctx, time.Now().UTC().Add(time.Second), 1, outboxRetry,
+		func(context.Context, *domain.OutboxMessage) error { return nil })
+	if err != nil {
+		t.Fatalf("RelayMessages: %v", err)
+	}
+
+	time.Sleep(10 * time.Millisecond)
+
+	deleted, err := outbox.DeletePublished(ctx, time.Millisecond)
+	if err != nil || deleted != 1 {
+		t.Fatalf("DeletePublished = %d, %v, want the one published message", deleted, err)
+	}
+
+	if rest := relayAll(t, outbox, time.Now().UTC().Add(time.Second)); len(rest) != 1 {
+		t.Errorf("published %d after cleanup, want the unpublished message kept", len(rest))
+	}
+}
+
+// testOutboxConcurrentRelays проверяет, что реплики, разбирающие outbox одновременно,
+// не публикуют одно событие дважды и не нарушают порядок событий подписки
+func testOutboxConcurrentRelays(t *testing.T, stores Stores) {
+	ctx := context.Background()
+	repo, outbox := stores.Subscriptions, stores.Outbox
+
+	const subscriptions = 12
+
+	for i := range subscriptions {
+		sub := newSubscription(uuid.New(), "Okko", 300+i, month(time.July, 2025), month(time.July, 2025))
+		mustCreate(t, repo, sub)
+
+		sub.Price++
+		if err := repo.UpdateSubscription(ctx, sub); err != nil {
+			t.Fatalf("UpdateSubscription: %v", err)
+		}
+	}
+
+	var (
+		mu        sync.Mutex
+		published = make(map[uuid.UUID]int)
+		order     = make(map[uuid.UUID][]domain.WebhookEventType)
+		wg        sync.WaitGroup
+	)
+
+	publish := func(_ context.Context, msg *domain.OutboxMessage) error {
+		// медленный получатель, чтобы разборы пересекались
+		time.Sleep(5 * time.Millisecond)
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		published[msg.Event.Id]++
+		order[msg.SubscriptionId] = append(order[msg.SubscriptionId], msg.Event.Type)
+		return nil
+	}
+
+	now := time.Now().UTC().Add(time.Second)
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				relayed, err := outbox.RelayMessages(ctx, now, 3, outboxRetry, publish)
+				if err != nil {
+					t.Errorf("RelayMessages: %v", err)
+					return
+				}
+				if relayed == 0 {
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if rest := relayAll(t, outbox, now); len(rest) != 0 {
+		t.Errorf("published %v after the relays stopped, want everything relayed", eventTypes(rest))
+	}
+
+	if len(published) != 2*subscriptions {
+		t.Errorf("published %d events, want %d", len(published), 2*subscriptions)
+	}
+	for id, times := range published {
+		if times != 1 {
+			t.Errorf("event %s published %d times, want once", id, times)
+		}
+	}
+
+	want := []domain.WebhookEventType{domain.WebhookSubscriptionCreated, domain.WebhookSubscriptionUpdated}
+	for sub, got := range order {
+		if !slices.Equal(got, want) {
+			t.Errorf("subscription %s published %v, want %v", sub, got, want)
+		}
+	}
+}
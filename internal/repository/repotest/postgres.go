@@ -0,0 +1,77 @@
+package repotest
+
+import (
+	"context"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Aiszhio/Task/internal/db"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// DSNEnv — переменная окружения с DSN тестовой базы; без неё тесты PostgreSQL пропускаются
+const DSNEnv = "TEST_DATABASE_DSN"
+
+// Postgres создаёт в тестовой базе отдельную схему, применяет к ней миграции и удаляет её
+// по завершении теста, поэтому каждый тест начинает с пустого хранилища
+func Postgres(t *testing.T) *db.Pool {
+	t.Helper()
+
+	dsn := os.Getenv(DSNEnv)
+	if dsn == "" {
+		t.Skipf("%s is not set", DSNEnv)
+	}
+
+	ctx := context.Background()
+	schema := "test_" + strings.ReplaceAll(uuid.NewString(), "-", "")
+
+	admin, err := pgx.Connect(ctx, dsn)
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	if _, err = admin.Exec(ctx, "CREATE SCHEMA "+schema); err != nil {
+		t.Fatalf("create schema: %v", err)
+	}
+	t.Cleanup(func() {
+		_, _ = admin.Exec(ctx, "DROP SCHEMA "+schema+" CASCADE")
+		_ = admin.Close(ctx)
+	})
+
+	pool, err := db.NewPool(ctx, db.Config{
+		DSN:            withSearchPath(dsn, schema),
+		MaxConns:       4,
+		ConnectTimeout: 5 * time.Second,
+	})
+	if err != nil {
+		t.Fatalf("NewPool: %v", err)
+	}
+	t.Cleanup(pool.Close)
+
+	migrator, err := db.NewMigrator(pool)
+	if err != nil {
+		t.Fatalf("NewMigrator: %v", err)
+	}
+	defer migrator.Close()
+
+	if _, err = migrator.Up(ctx); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	return pool
+}
+
+// withSearchPath направляет все соединения пула в схему schema; DSN бывает URL или списком key=value
+func withSearchPath(dsn, schema string) string {
+	if u, err := url.Parse(dsn); err == nil && u.Scheme != "" {
+		query := u.Query()
+		query.Set("search_path", schema)
+		u.RawQuery = query.Encode()
+		return u.String()
+	}
+
+	return dsn + " search_path=" + schema
+}
@@ -0,0 +1,70 @@
+package repotest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Aiszhio/Task/internal/domain"
+	"github.com/Aiszhio/Task/internal/repository/postgres"
+)
+
+func runRateLimits(t *testing.T, newStores Factory) {
+	t.Run("Burst", func(t *testing.T) { testRateLimitBurst(t, newStores(t).RateLimits) })
+	t.Run("DeleteIdle", func(t *testing.T) { testRateLimitDeleteIdle(t, newStores(t).RateLimits) })
+}
+
+var hourlyLimit = domain.RateLimit{Requests: 3, Per: time.Hour}
+
+func mustTake(t *testing.T, repo postgres.RateLimitRepository, key string) *domain.RateDecision {
+	t.Helper()
+
+	decision, err := repo.TakeToken(context.Background(), key, hourlyLimit)
+	if err != nil {
+		t.Fatalf("TakeToken(%q): %v", key, err)
+	}
+
+	return decision
+}
+
+func testRateLimitBurst(t *testing.T, repo postgres.RateLimitRepository) {
+	for i := range hourlyLimit.Requests {
+		decision := mustTake(t, repo, "client-1")
+		if !decision.Allowed || decision.Remaining != hourlyLimit.Requests-i-1 {
+			t.Fatalf("request %d = %+v, want allowed with %d remaining", i+1, decision, hourlyLimit.Requests-i-1)
+		}
+	}
+
+	denied := mustTake(t, repo, "client-1")
+	if denied.Allowed || denied.Remaining != 0 || denied.RetryAfter <= 0 || denied.Reset < denied.RetryAfter {
+		t.Errorf("request over the limit = %+v, want denied with a retry delay", denied)
+	}
+
+	if other := mustTake(t, repo, "client-2"); !other.Allowed {
+		t.Errorf("first request of another client = %+v, want allowed", other)
+	}
+}
+
+func testRateLimitDeleteIdle(t *testing.T, repo postgres.RateLimitRepository) {
+	ctx := context.Background()
+
+	for range hourlyLimit.Requests {
+		mustTake(t, repo, "client-1")
+	}
+
+	deleted, err := repo.DeleteIdleBuckets(ctx, time.Hour)
+	if err != nil || deleted != 0 {
+		t.Fatalf("DeleteIdleBuckets(1h) = %d, %v, want nothing deleted", deleted, err)
+	}
+
+	time.Sleep(10 * time.Millisecond)
+
+	deleted, err = repo.DeleteIdleBuckets(ctx, time.Millisecond)
+	if err != nil || deleted != 1 {
+		t.Fatalf("DeleteIdleBuckets(1ms) = %d, %v, want 1", deleted, err)
+	}
+
+	if decision := mustTake(t, repo, "client-1"); !decision.Allowed || decision.Remaining != hourlyLimit.Requests-1 {
+		t.Errorf("request after the bucket was deleted = %+v, want a full bucket", decision)
+	}
+}
@@ -0,0 +1,175 @@
+package repotest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Aiszhio/Task/internal/domain"
+	errors_package "github.com/Aiszhio/Task/internal/errors"
+	"github.com/Aiszhio/Task/internal/repository/postgres"
+	"github.com/google/uuid"
+)
+
+func runReminders(t *testing.T, newStores Factory) {
+	t.Run("Claim", func(t *testing.T) { testReminderClaim(t, newStores(t).Reminders) })
+	t.Run("List", func(t *testing.T) { testReminderList(t, newStores(t).Reminders) })
+	t.Run("Delete", func(t *testing.T) { testReminderDelete(t, newStores(t).Reminders) })
+	t.Run("Contacts", func(t *testing.T) { testReminderContacts(t, newStores(t).Reminders) })
+}
+
+var reminderRetry = domain.RetryPolicy{MaxAttempts: 2, Base: time.Minute, Max: time.Hour}
+
+func newReminder(userId uuid.UUID, dueAt time.Time) *domain.Reminder {
+	sub := domain.Subscription{Id: uuid.New(), UserId: userId}
+	notice := domain.ReminderNotice{
+		Kind:   domain.ReminderExpiry,
+		DueAt:  dueAt,
+		Window: domain.ReminderWindow(7 * 24 * time.Hour),
+	}
+
+	return domain.NewReminder(sub, notice, domain.ReminderChannelLog)
+}
+
+func mustClaimReminder(t *testing.T, repo postgres.ReminderRepository, reminder *domain.Reminder, now time.Time) bool {
+	t.Helper()
+
+	claimed, err := repo.ClaimReminder(context.Background(), reminder, now, time.Minute)
+	if err != nil {
+		t.Fatalf("ClaimReminder: %v", err)
+	}
+
+	return claimed
+}
+
+func testReminderClaim(t *testing.T, repo postgres.ReminderRepository) {
+	ctx := context.Background()
+	// Postgres хранит время с точностью до микросекунды
+	now := time.Now().UTC().Truncate(time.Microsecond)
+
+	reminder := newReminder(uuid.New(), now.Add(48*time.Hour))
+	if !mustClaimReminder(t, repo, reminder, now) {
+		t.Fatalf("ClaimReminder of a new reminder = false, want true")
+	}
+
+	if !mustClaimReminder(t, repo, newReminder(reminder.UserId, reminder.DueAt), now) {
+		t.Errorf("ClaimReminder of another subscription = false, want true")
+	}
+
+	if again := *reminder; mustClaimReminder(t, repo, &again, now) {
+		t.Errorf("ClaimReminder during the lease = true, want false")
+	}
+
+	reminder.Record(now, errors.New("smtp: connection refused"), reminderRetry)
+	if err := repo.SaveReminder(ctx, reminder); err != nil {
+		t.Fatalf("SaveReminder: %v", err)
+	}
+
+	if again := *reminder; mustClaimReminder(t, repo, &again, now.Add(time.Second)) {
+		t.Errorf("ClaimReminder before the backoff = true, want false")
+	}
+
+	retry := *reminder
+	if !mustClaimReminder(t, repo, &retry, now.Add(reminderRetry.Base)) || retry.Attempts != 1 {
+		t.Fatalf("ClaimReminder after the backoff = %+v, want the failed reminder with 1 attempt", retry)
+	}
+
+	retry.Record(now.Add(reminderRetry.Base), nil, reminderRetry)
+	if err := repo.SaveReminder(ctx, &retry); err != nil {
+		t.Fatalf("SaveReminder: %v", err)
+	}
+
+	if again := retry; mustClaimReminder(t, repo, &again, now.Add(time.Hour)) {
+		t.Errorf("ClaimReminder of a sent reminder = true, want false")
+	}
+
+	reminders, err := repo.ListReminders(ctx, reminder.UserId, 10)
+	if err != nil || len(reminders) != 2 {
+		t.Fatalf("ListReminders = %d, %v, want 2", len(reminders), err)
+	}
+
+	for _, got := range reminders {
+		if got.Id != reminder.Id {
+			continue
+		}
+		if got.Status != domain.DeliverySucceeded || got.Attempts != 2 || got.SentAt == nil || got.LastError != "" {
+			t.Errorf("sent reminder = %+v, want succeeded after 2 attempts", got)
+		}
+		if got.Window != reminder.Window || got.Kind != reminder.Kind || !got.DueAt.Equal(reminder.DueAt) {
+			t.Errorf("sent reminder = %+v, want the notice of %+v", got, reminder.ReminderNotice)
+		}
+	}
+}
+
+func testReminderList(t *testing.T, repo postgres.ReminderRepository) {
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	alice, bob := uuid.New(), uuid.New()
+	for _, userId := range []uuid.UUID{alice, alice, bob} {
+		mustClaimReminder(t, repo, newReminder(userId, now.Add(time.Hour)), now)
+	}
+
+	if reminders, err := repo.ListReminders(ctx, alice, 10); err != nil || len(reminders) != 2 {
+		t.Errorf("ListReminders of a user = %d, %v, want 2", len(reminders), err)
+	}
+
+	if reminders, err := repo.ListReminders(ctx, uuid.Nil, 10); err != nil || len(reminders) != 3 {
+		t.Errorf("ListReminders of all users = %d, %v, want 3", len(reminders), err)
+	}
+
+	if reminders, err := repo.ListReminders(ctx, uuid.Nil, 1); err != nil || len(reminders) != 1 {
+		t.Errorf("ListReminders with limit 1 = %d, %v, want 1", len(reminders), err)
+	}
+}
+
+func testReminderDelete(t *testing.T, repo postgres.ReminderRepository) {
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	userId := uuid.New()
+	mustClaimReminder(t, repo, newReminder(userId, now.Add(-48*time.Hour)), now)
+	mustClaimReminder(t, repo, newReminder(userId, now.Add(time.Hour)), now)
+
+	deleted, err := repo.DeleteReminders(ctx, 24*time.Hour)
+	if err != nil || deleted != 1 {
+		t.Fatalf("DeleteReminders = %d, %v, want 1", deleted, err)
+	}
+
+	if reminders, _ := repo.ListReminders(ctx, userId, 10); len(reminders) != 1 {
+		t.Errorf("ListReminders after DeleteReminders = %d, want 1", len(reminders))
+	}
+}
+
+func testReminderContacts(t *testing.T, repo postgres.ReminderRepository) {
+	ctx := context.Background()
+	userId := uuid.New()
+
+	if _, err := repo.ReadContact(ctx, userId); !errors.Is(err, errors_package.ErrNotFound) {
+		t.Errorf("ReadContact of an unknown user = %v, want ErrNotFound", err)
+	}
+
+	for _, email := range []string{"alice@example.com", "alice@example.org"} {
+		contact := &domain.ReminderContact{UserId: userId, Email: email}
+		if err := repo.SaveContact(ctx, contact); err != nil {
+			t.Fatalf("SaveContact: %v", err)
+		}
+		if contact.UpdatedAt.IsZero() {
+			t.Errorf("SaveContact did not set UpdatedAt")
+		}
+	}
+
+	contact, err := repo.ReadContact(ctx, userId)
+	if err != nil || contact.Email != "alice@example.org" {
+		t.Fatalf("ReadContact = %+v, %v, want the latest address", contact, err)
+	}
+
+	if err = repo.DeleteContact(ctx, userId); err != nil {
+		t.Fatalf("DeleteContact: %v", err)
+	}
+
+	if err = repo.DeleteContact(ctx, userId); !errors.Is(err, errors_package.ErrNotFound) {
+		t.Errorf("second DeleteContact = %v, want ErrNotFound", err)
+	}
+}
@@ -0,0 +1,34 @@
+// Package repotest содержит общий контрактный набор проверок для хранилищ из пакета postgres.
+// Каждая реализация вызывает Run из своих тестов, передавая фабрику пустых хранилищ.
+package repotest
+
+import (
+	"testing"
+
+	"github.com/Aiszhio/Task/internal/repository/postgres"
+)
+
+// Stores — хранилища одной реализации; Outbox пополняется изменениями Subscriptions
+type Stores struct {
+	Subscriptions postgres.SubscriptionRepository
+	Outbox        postgres.OutboxRepository
+	Idempotency   postgres.IdempotencyRepository
+	APIKeys       postgres.APIKeyRepository
+	RateLimits    postgres.RateLimitRepository
+	Webhooks      postgres.WebhookRepository
+	Reminders     postgres.ReminderRepository
+}
+
+// Factory возвращает пустые хранилища, изолированные от остальных подтестов
+type Factory func(t *testing.T) Stores
+
+// Run проверяет каждое хранилище из Stores
+func Run(t *testing.T, newStores Factory) {
+	t.Run("Subscriptions", func(t *testing.T) { runSubscriptions(t, newStores) })
+	t.Run("Idempotency", func(t *testing.T) { runIdempotency(t, newStores) })
+	t.Run("APIKeys", func(t *testing.T) { runAPIKeys(t, newStores) })
+	t.Run("RateLimits", func(t *testing.T) { runRateLimits(t, newStores) })
+	t.Run("Webhooks", func(t *testing.T) { runWebhooks(t, newStores) })
+	t.Run("Outbox", func(t *testing.T) { runOutbox(t, newStores) })
+	t.Run("Reminders", func(t *testing.T) { runReminders(t, newStores) })
+}
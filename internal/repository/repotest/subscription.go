@@ -0,0 +1,680 @@
+package repotest
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/Aiszhio/Task/internal/domain"
+	errors_package "github.com/Aiszhio/Task/internal/errors"
+	"github.com/Aiszhio/Task/internal/repository/postgres"
+	"github.com/Aiszhio/Task/internal/requestmeta"
+	"github.com/google/uuid"
+)
+
+func runSubscriptions(t *testing.T, newStores Factory) {
+	t.Run("CreateAndRead", func(t *testing.T) { testCreateAndRead(t, newStores(t).Subscriptions) })
+	t.Run("CreateDuplicate", func(t *testing.T) { testCreateDuplicate(t, newStores(t).Subscriptions) })
+	t.Run("CreateBatch", func(t *testing.T) { testCreateBatch(t, newStores(t).Subscriptions) })
+	t.Run("CreateBatchRollback", func(t *testing.T) { testCreateBatchRollback(t, newStores(t).Subscriptions) })
+	t.Run("CreateBatchDryRun", func(t *testing.T) { testCreateBatchDryRun(t, newStores(t).Subscriptions) })
+	t.Run("ReadMissing", func(t *testing.T) { testReadMissing(t, newStores(t).Subscriptions) })
+	t.Run("Update", func(t *testing.T) { testUpdate(t, newStores(t).Subscriptions) })
+	t.Run("UpdateMissing", func(t *testing.T) { testUpdateMissing(t, newStores(t).Subscriptions) })
+	t.Run("UpdateVersion", func(t *testing.T) { testUpdateVersion(t, newStores(t).Subscriptions) })
+	t.Run("UpdateDuplicate", func(t *testing.T) { testUpdateDuplicate(t, newStores(t).Subscriptions) })
+	t.Run("Delete", func(t *testing.T) { testDelete(t, newStores(t).Subscriptions) })
+	t.Run("DeleteMissing", func(t *testing.T) { testDeleteMissing(t, newStores(t).Subscriptions) })
+	t.Run("Restore", func(t *testing.T) { testRestore(t, newStores(t).Subscriptions) })
+	t.Run("RestoreConflict", func(t *testing.T) { testRestoreConflict(t, newStores(t).Subscriptions) })
+	t.Run("Purge", func(t *testing.T) { testPurge(t, newStores(t).Subscriptions) })
+	t.Run("ListForPeriod", func(t *testing.T) { testListForPeriod(t, newStores(t).Subscriptions) })
+	t.Run("FindWithFilter", func(t *testing.T) { testFindWithFilter(t, newStores(t).Subscriptions) })
+	t.Run("FindDeleted", func(t *testing.T) { testFindDeleted(t, newStores(t).Subscriptions) })
+	t.Run("Stream", func(t *testing.T) { testStream(t, newStores(t).Subscriptions) })
+	t.Run("History", func(t *testing.T) { testHistory(t, newStores(t).Subscriptions) })
+}
+
+func month(m time.Month, year int) time.Time {
+	return time.Date(year, m, 1, 0, 0, 0, 0, time.UTC)
+}
+
+func newSubscription(userID uuid.UUID, service string, price int, start, end time.Time) *domain.Subscription {
+	return &domain.Subscription{
+		Id:              uuid.New(),
+		ServiceName:     service,
+		Price:           price,
+		BillingInterval: domain.BillingMonth,
+		IntervalCount:   1,
+		UserId:          userID,
+		StartDate:       start,
+		EndDate:         end,
+	}
+}
+
+func mustCreate(t *testing.T, repo postgres.SubscriptionRepository, sub *domain.Subscription) {
+	t.Helper()
+
+	if err := repo.CreateSubscription(context.Background(), sub); err != nil {
+		t.Fatalf("CreateSubscription(%s): %v", sub.Id, err)
+	}
+}
+
+func testCreateAndRead(t *testing.T, repo postgres.SubscriptionRepository) {
+	ctx := context.Background()
+	sub := newSubscription(uuid.New(), "Yandex Plus", 400, month(time.July, 2025), month(time.December, 2025))
+	sub.BillingInterval = domain.BillingYear
+	sub.IntervalCount = 2
+	mustCreate(t, repo, sub)
+
+	got, err := repo.ReadSubscription(ctx, sub.Id)
+	if err != nil {
+		t.Fatalf("ReadSubscription: %v", err)
+	}
+
+	if got.Id != sub.Id || got.ServiceName != sub.ServiceName || got.Price != sub.Price ||
+		got.UserId != sub.UserId || got.BillingInterval != sub.BillingInterval ||
+		got.IntervalCount != sub.IntervalCount {
+		t.Errorf("ReadSubscription = %+v, want %+v", got, sub)
+	}
+
+	if !got.StartDate.Equal(sub.StartDate) || !got.EndDate.Equal(sub.EndDate) {
+		t.Errorf("ReadSubscription dates = %s..%s, want %s..%s",
+			got.StartDate, got.EndDate, sub.StartDate, sub.EndDate)
+	}
+
+	if got.CreatedAt.IsZero() || got.UpdatedAt.IsZero() {
+		t.Errorf("ReadSubscription timestamps are not set: %+v", got)
+	}
+}
+
+func testCreateDuplicate(t *testing.T, repo postgres.SubscriptionRepository) {
+	userID := uuid.New()
+	mustCreate(t, repo, newSubscription(userID, "Okko", 300, month(time.July, 2025), month(time.July, 2025)))
+
+	duplicate := newSubscription(userID, "Okko", 500, month(time.July, 2025), month(time.August, 2025))
+	if err := repo.CreateSubscription(context.Background(), duplicate); !errors.Is(err, errors_package.ErrConflict) {
+		t.Fatalf("CreateSubscription of a duplicate = %v, want ErrConflict", err)
+	}
+
+	other := newSubscription(userID, "Okko", 300, month(time.August, 2025), month(time.August, 2025))
+	mustCreate(t, repo, other)
+}
+
+func testCreateBatch(t *testing.T, repo postgres.SubscriptionRepository) {
+	ctx := context.Background()
+	userID := uuid.New()
+	subs := []*domain.Subscription{
+		newSubscription(userID, "Okko", 300, month(time.July, 2025), month(time.July, 2025)),
+		newSubscription(userID, "Ivi", 200, month(time.July, 2025), month(time.July, 2025)),
+	}
+
+	if err := repo.CreateSubscriptions(ctx, subs, false); err != nil {
+		t.Fatalf("CreateSubscriptions: %v", err)
+	}
+
+	for _, sub := range subs {
+		if _, err := repo.ReadSubscription(ctx, sub.Id); err != nil {
+			t.Errorf("ReadSubscription(%s) after batch: %v", sub.Id, err)
+		}
+	}
+}
+
+func testCreateBatchRollback(t *testing.T, repo postgres.SubscriptionRepository) {
+	ctx := context.Background()
+	userID := uuid.New()
+	existing := newSubscription(userID, "Ivi", 200, month(time.June, 2025), month(time.June, 2025))
+	mustCreate(t, repo, existing)
+
+	subs := []*domain.Subscription{
+		newSubscription(userID, "Okko", 300, month(time.July, 2025), month(time.July, 2025)),
+		newSubscription(userID, "Ivi", 200, month(time.June, 2025), month(time.July, 2025)),
+		newSubscription(userID, "Wink", 200, month(time.July, 2025), month(time.July, 2025)),
+		newSubscription(userID, "Okko", 500, month(time.July, 2025), month(time.August, 2025)),
+	}
+
+	err := repo.CreateSubscriptions(ctx, subs, false)
+
+	var rowErrs errors_package.RowErrors
+	if !errors.As(err, &rowErrs) || !errors.Is(err, errors_package.ErrConflict) {
+		t.Fatalf("CreateSubscriptions with duplicates in the batch = %v, want RowErrors with conflicts", err)
+	}
+
+	var rows []int
+	for _, rowErr := range rowErrs {
+		rows = append(rows, rowErr.Index)
+	}
+	if !slices.Equal(rows, []int{1, 3}) {
+		t.Errorf("rejected rows = %v, want [1 3]", rows)
+	}
+
+	for _, sub := range subs {
+		if _, err = repo.ReadSubscription(ctx, sub.Id); !errors.Is(err, errors_package.ErrNotFound) {
+			t.Errorf("ReadSubscription(%s) after a failed batch = %v, want ErrNotFound", sub.Id, err)
+		}
+
+		if _, err = repo.ListSubscriptionEvents(ctx, sub.Id); !errors.Is(err, errors_package.ErrNotFound) {
+			t.Errorf("ListSubscriptionEvents(%s) after a failed batch = %v, want ErrNotFound", sub.Id, err)
+		}
+	}
+}
+
+func testCreateBatchDryRun(t *testing.T, repo postgres.SubscriptionRepository) {
+	ctx := context.Background()
+	userID := uuid.New()
+	subs := []*domain.Subscription{
+		newSubscription(userID, "Okko", 300, month(time.July, 2025), month(time.July, 2025)),
+		newSubscription(userID, "Ivi", 200, month(time.July, 2025), month(time.July, 2025)),
+	}
+
+	if err := repo.CreateSubscriptions(ctx, subs, true); err != nil {
+		t.Fatalf("CreateSubscriptions dry run: %v", err)
+	}
+
+	for _, sub := range subs {
+		if _, err := repo.ReadSubscription(ctx, sub.Id); !errors.Is(err, errors_package.ErrNotFound) {
+			t.Errorf("ReadSubscription(%s) after a dry run = %v, want ErrNotFound", sub.Id, err)
+		}
+	}
+
+	duplicate := newSubscription(userID, "Okko", 500, month(time.July, 2025), month(time.August, 2025))
+	mustCreate(t, repo, duplicate)
+
+	err := repo.CreateSubscriptions(ctx, subs, true)
+	if !errors.Is(err, errors_package.ErrConflict) {
+		t.Fatalf("CreateSubscriptions dry run over an existing subscription = %v, want ErrConflict", err)
+	}
+}
+
+func testReadMissing(t *testing.T, repo postgres.SubscriptionRepository) {
+	if _, err := repo.ReadSubscription(context.Background(), uuid.New()); !errors.Is(err, errors_package.ErrNotFound) {
+		t.Fatalf("ReadSubscription of a missing id = %v, want ErrNotFound", err)
+	}
+}
+
+func testUpdate(t *testing.T, repo postgres.SubscriptionRepository) {
+	ctx := context.Background()
+	userID := uuid.New()
+	sub := newSubscription(userID, "Kinopoisk", 300, month(time.July, 2025), month(time.July, 2025))
+	neighbour := newSubscription(userID, "Ivi", 200, month(time.July, 2025), month(time.July, 2025))
+	mustCreate(t, repo, sub)
+	mustCreate(t, repo, neighbour)
+
+	sub.ServiceName = "Kinopoisk HD"
+	sub.Price = 450
+	sub.BillingInterval = domain.BillingQuarter
+	sub.EndDate = month(time.September, 2025)
+	if err := repo.UpdateSubscription(ctx, sub); err != nil {
+		t.Fatalf("UpdateSubscription: %v", err)
+	}
+
+	got, err := repo.ReadSubscription(ctx, sub.Id)
+	if err != nil {
+		t.Fatalf("ReadSubscription: %v", err)
+	}
+
+	if got.ServiceName != "Kinopoisk HD" || got.Price != 450 ||
+		got.BillingInterval != domain.BillingQuarter || !got.EndDate.Equal(sub.EndDate) {
+		t.Errorf("ReadSubscription after update = %+v", got)
+	}
+
+	untouched, err := repo.ReadSubscription(ctx, neighbour.Id)
+	if err != nil {
+		t.Fatalf("ReadSubscription: %v", err)
+	}
+
+	if untouched.ServiceName != "Ivi" || untouched.Price != 200 {
+		t.Errorf("UpdateSubscription modified another subscription of the user: %+v", untouched)
+	}
+}
+
+func testUpdateMissing(t *testing.T, repo postgres.SubscriptionRepository) {
+	sub := newSubscription(uuid.New(), "Okko", 300, month(time.July, 2025), month(time.July, 2025))
+	if err := repo.UpdateSubscription(context.Background(), sub); !errors.Is(err, errors_package.ErrNotFound) {
+		t.Fatalf("UpdateSubscription of a missing id = %v, want ErrNotFound", err)
+	}
+}
+
+func testUpdateVersion(t *testing.T, repo postgres.SubscriptionRepository) {
+	ctx := context.Background()
+	sub := newSubscription(uuid.New(), "Okko", 300, month(time.July, 2025), month(time.July, 2025))
+	mustCreate(t, repo, sub)
+
+	created, err := repo.ReadSubscription(ctx, sub.Id)
+	if err != nil {
+		t.Fatalf("ReadSubscription: %v", err)
+	}
+
+	if created.Version != 1 {
+		t.Fatalf("version of a new subscription = %d, want 1", created.Version)
+	}
+
+	first := *created
+	first.Price = 350
+	if err = repo.UpdateSubscription(ctx, &first); err != nil {
+		t.Fatalf("UpdateSubscription with the current version: %v", err)
+	}
+
+	if first.Version != 2 {
+		t.Errorf("version after update = %d, want 2", first.Version)
+	}
+
+	stale := *created
+	stale.Price = 400
+	if err = repo.UpdateSubscription(ctx, &stale); !errors.Is(err, errors_package.ErrPreconditionFailed) {
+		t.Fatalf("UpdateSubscription with a stale version = %v, want ErrPreconditionFailed", err)
+	}
+
+	unconditional := *created
+	unconditional.Version = 0
+	unconditional.Price = 450
+	if err = repo.UpdateSubscription(ctx, &unconditional); err != nil {
+		t.Fatalf("UpdateSubscription without a version: %v", err)
+	}
+
+	got, err := repo.ReadSubscription(ctx, sub.Id)
+	if err != nil {
+		t.Fatalf("ReadSubscription: %v", err)
+	}
+
+	if got.Price != 450 || got.Version != 3 {
+		t.Errorf("ReadSubscription after updates = price %d version %d, want 450 and 3", got.Price, got.Version)
+	}
+}
+
+func testUpdateDuplicate(t *testing.T, repo postgres.SubscriptionRepository) {
+	ctx := context.Background()
+	userID := uuid.New()
+	sub := newSubscription(userID, "Okko", 300, month(time.July, 2025), month(time.July, 2025))
+	other := newSubscription(userID, "Okko", 300, month(time.August, 2025), month(time.August, 2025))
+	mustCreate(t, repo, sub)
+	mustCreate(t, repo, other)
+
+	moved := *other
+	moved.StartDate = sub.StartDate
+	if err := repo.UpdateSubscription(ctx, &moved); !errors.Is(err, errors_package.ErrConflict) {
+		t.Fatalf("UpdateSubscription onto an existing subscription = %v, want ErrConflict", err)
+	}
+
+	renamed := *other
+	renamed.ServiceName = "Ivi"
+	renamed.StartDate = sub.StartDate
+	if err := repo.UpdateSubscription(ctx, &renamed); err != nil {
+		t.Fatalf("UpdateSubscription onto a free slot: %v", err)
+	}
+}
+
+func testDelete(t *testing.T, repo postgres.SubscriptionRepository) {
+	ctx := context.Background()
+	sub := newSubscription(uuid.New(), "Okko", 300, month(time.July, 2025), month(time.July, 2025))
+	mustCreate(t, repo, sub)
+
+	if err := repo.DeleteSubscription(ctx, sub.Id); err != nil {
+		t.Fatalf("DeleteSubscription: %v", err)
+	}
+
+	if _, err := repo.ReadSubscription(ctx, sub.Id); !errors.Is(err, errors_package.ErrNotFound) {
+		t.Fatalf("ReadSubscription of a deleted subscription = %v, want ErrNotFound", err)
+	}
+
+	if err := repo.UpdateSubscription(ctx, sub); !errors.Is(err, errors_package.ErrNotFound) {
+		t.Fatalf("UpdateSubscription of a deleted subscription = %v, want ErrNotFound", err)
+	}
+
+	if err := repo.DeleteSubscription(ctx, sub.Id); !errors.Is(err, errors_package.ErrNotFound) {
+		t.Fatalf("DeleteSubscription of a deleted subscription = %v, want ErrNotFound", err)
+	}
+
+	// место удалённой подписки свободно для новой
+	mustCreate(t, repo, newSubscription(sub.UserId, sub.ServiceName, 500, sub.StartDate, sub.EndDate))
+}
+
+func testDeleteMissing(t *testing.T, repo postgres.SubscriptionRepository) {
+	if err := repo.DeleteSubscription(context.Background(), uuid.New()); !errors.Is(err, errors_package.ErrNotFound) {
+		t.Fatalf("DeleteSubscription of a missing id = %v, want ErrNotFound", err)
+	}
+}
+
+func testRestore(t *testing.T, repo postgres.SubscriptionRepository) {
+	ctx := context.Background()
+	sub := newSubscription(uuid.New(), "Okko", 300, month(time.July, 2025), month(time.July, 2025))
+	mustCreate(t, repo, sub)
+
+	if _, err := repo.RestoreSubscription(ctx, sub.Id); !errors.Is(err, errors_package.ErrNotFound) {
+		t.Fatalf("RestoreSubscription of an active subscription = %v, want ErrNotFound", err)
+	}
+
+	if _, err := repo.ReadDeletedSubscription(ctx, sub.Id); !errors.Is(err, errors_package.ErrNotFound) {
+		t.Fatalf("ReadDeletedSubscription of an active subscription = %v, want ErrNotFound", err)
+	}
+
+	if err := repo.DeleteSubscription(ctx, sub.Id); err != nil {
+		t.Fatalf("DeleteSubscription: %v", err)
+	}
+
+	deleted, err := repo.ReadDeletedSubscription(ctx, sub.Id)
+	if err != nil {
+		t.Fatalf("ReadDeletedSubscription: %v", err)
+	}
+
+	if deleted.UserId != sub.UserId || deleted.DeletedAt == nil {
+		t.Errorf("ReadDeletedSubscription = %+v, want the deleted subscription of %s", deleted, sub.UserId)
+	}
+
+	restored, err := repo.RestoreSubscription(ctx, sub.Id)
+	if err != nil {
+		t.Fatalf("RestoreSubscription: %v", err)
+	}
+
+	if restored.Id != sub.Id || restored.Price != sub.Price || restored.DeletedAt != nil || restored.Version != 3 {
+		t.Errorf("RestoreSubscription = %+v, want the active subscription with version 3", restored)
+	}
+
+	if _, err = repo.ReadSubscription(ctx, sub.Id); err != nil {
+		t.Fatalf("ReadSubscription of a restored subscription: %v", err)
+	}
+
+	if _, err = repo.RestoreSubscription(ctx, uuid.New()); !errors.Is(err, errors_package.ErrNotFound) {
+		t.Fatalf("RestoreSubscription of a missing id = %v, want ErrNotFound", err)
+	}
+}
+
+func testRestoreConflict(t *testing.T, repo postgres.SubscriptionRepository) {
+	ctx := context.Background()
+	sub := newSubscription(uuid.New(), "Okko", 300, month(time.July, 2025), month(time.July, 2025))
+	mustCreate(t, repo, sub)
+
+	if err := repo.DeleteSubscription(ctx, sub.Id); err != nil {
+		t.Fatalf("DeleteSubscription: %v", err)
+	}
+
+	mustCreate(t, repo, newSubscription(sub.UserId, sub.ServiceName, 500, sub.StartDate, sub.EndDate))
+
+	if _, err := repo.RestoreSubscription(ctx, sub.Id); !errors.Is(err, errors_package.ErrConflict) {
+		t.Fatalf("RestoreSubscription over an active duplicate = %v, want ErrConflict", err)
+	}
+}
+
+func testPurge(t *testing.T, repo postgres.SubscriptionRepository) {
+	ctx := context.Background()
+	userID := uuid.New()
+	active := newSubscription(userID, "Okko", 300, month(time.July, 2025), month(time.July, 2025))
+	deleted := newSubscription(userID, "Ivi", 200, month(time.July, 2025), month(time.July, 2025))
+	mustCreate(t, repo, active)
+	mustCreate(t, repo, deleted)
+
+	if err := repo.DeleteSubscription(ctx, deleted.Id); err != nil {
+		t.Fatalf("DeleteSubscription: %v", err)
+	}
+
+	purged, err := repo.PurgeSubscriptions(ctx, time.Hour)
+	if err != nil {
+		t.Fatalf("PurgeSubscriptions: %v", err)
+	}
+
+	if purged != 0 {
+		t.Fatalf("PurgeSubscriptions within retention removed %d subscriptions, want 0", purged)
+	}
+
+	// даём времени удаления гарантированно остаться в прошлом
+	time.Sleep(10 * time.Millisecond)
+
+	purged, err = repo.PurgeSubscriptions(ctx, 0)
+	if err != nil {
+		t.Fatalf("PurgeSubscriptions: %v", err)
+	}
+
+	if purged != 1 {
+		t.Errorf("PurgeSubscriptions after retention removed %d subscriptions, want 1", purged)
+	}
+
+	if _, err = repo.RestoreSubscription(ctx, deleted.Id); !errors.Is(err, errors_package.ErrNotFound) {
+		t.Fatalf("RestoreSubscription of a purged subscription = %v, want ErrNotFound", err)
+	}
+
+	if _, err = repo.ReadSubscription(ctx, active.Id); err != nil {
+		t.Fatalf("ReadSubscription of an active subscription after purge: %v", err)
+	}
+}
+
+func testListForPeriod(t *testing.T, repo postgres.SubscriptionRepository) {
+	userID := uuid.New()
+	before := newSubscription(userID, "Okko", 100, month(time.January, 2025), month(time.March, 2025))
+	overlapping := newSubscription(userID, "Okko", 200, month(time.May, 2025), month(time.August, 2025))
+	inside := newSubscription(userID, "Okko", 300, month(time.September, 2025), month(time.September, 2025))
+	after := newSubscription(userID, "Okko", 400, month(time.January, 2026), month(time.February, 2026))
+	otherService := newSubscription(userID, "Ivi", 500, month(time.July, 2025), month(time.July, 2025))
+	otherUser := newSubscription(uuid.New(), "Okko", 600, month(time.July, 2025), month(time.July, 2025))
+	deleted := newSubscription(userID, "Okko", 700, month(time.October, 2025), month(time.October, 2025))
+
+	for _, sub := range []*domain.Subscription{before, overlapping, inside, after, otherService, otherUser, deleted} {
+		mustCreate(t, repo, sub)
+	}
+
+	if err := repo.DeleteSubscription(context.Background(), deleted.Id); err != nil {
+		t.Fatalf("DeleteSubscription: %v", err)
+	}
+
+	got, err := repo.ListSubscriptions(context.Background(),
+		month(time.July, 2025), month(time.December, 2025), userID, "Okko")
+	if err != nil {
+		t.Fatalf("ListSubscriptions: %v", err)
+	}
+
+	if len(got) != 2 || got[0].Id != overlapping.Id || got[1].Id != inside.Id {
+		t.Fatalf("ListSubscriptions = %+v, want subscriptions %s and %s", got, overlapping.Id, inside.Id)
+	}
+}
+
+func testFindWithFilter(t *testing.T, repo postgres.SubscriptionRepository) {
+	ctx := context.Background()
+	userID := uuid.New()
+	prices := []int{100, 500, 300, 200, 400}
+	for i, price := range prices {
+		start := month(time.Month(i+1), 2025)
+		mustCreate(t, repo, newSubscription(userID, "Okko", price, start, start.AddDate(0, 6, 0)))
+	}
+	mustCreate(t, repo, newSubscription(uuid.New(), "Okko", 250, month(time.March, 2025), month(time.March, 2025)))
+
+	minPrice, maxPrice := 150, 450
+	page, err := repo.FindSubscriptions(ctx, &domain.SubscriptionFilter{
+		UserID:   userID,
+		MinPrice: &minPrice,
+		MaxPrice: &maxPrice,
+		SortBy:   "price",
+		SortDesc: true,
+		Limit:    2,
+		Offset:   0,
+	})
+	if err != nil {
+		t.Fatalf("FindSubscriptions: %v", err)
+	}
+
+	if page.Total != 3 {
+		t.Errorf("FindSubscriptions total = %d, want 3", page.Total)
+	}
+
+	if len(page.Items) != 2 || page.Items[0].Price != 400 || page.Items[1].Price != 300 {
+		t.Errorf("FindSubscriptions first page = %+v, want prices 400, 300", page.Items)
+	}
+
+	activeAt := month(time.January, 2025)
+	page, err = repo.FindSubscriptions(ctx, &domain.SubscriptionFilter{
+		ServiceName: "Okko",
+		ActiveAt:    &activeAt,
+		SortBy:      "start_date",
+		Limit:       10,
+	})
+	if err != nil {
+		t.Fatalf("FindSubscriptions: %v", err)
+	}
+
+	if page.Total != 1 || len(page.Items) != 1 || page.Items[0].Price != 100 {
+		t.Errorf("FindSubscriptions active in January = %+v, want the single 100 subscription", page.Items)
+	}
+
+	page, err = repo.FindSubscriptions(ctx, &domain.SubscriptionFilter{UserID: userID, Limit: 10, Offset: 10})
+	if err != nil {
+		t.Fatalf("FindSubscriptions: %v", err)
+	}
+
+	if page.Total != 5 || len(page.Items) != 0 {
+		t.Errorf("FindSubscriptions past the last page = %d items of %d, want 0 of 5", len(page.Items), page.Total)
+	}
+}
+
+func testFindDeleted(t *testing.T, repo postgres.SubscriptionRepository) {
+	ctx := context.Background()
+	userID := uuid.New()
+	subs := []*domain.Subscription{
+		newSubscription(userID, "Okko", 100, month(time.July, 2025), month(time.July, 2025)),
+		newSubscription(userID, "Ivi", 200, month(time.July, 2025), month(time.July, 2025)),
+		newSubscription(userID, "Wink", 300, month(time.July, 2025), month(time.July, 2025)),
+	}
+	for _, sub := range subs {
+		mustCreate(t, repo, sub)
+	}
+
+	for _, sub := range subs[1:] {
+		if err := repo.DeleteSubscription(ctx, sub.Id); err != nil {
+			t.Fatalf("DeleteSubscription: %v", err)
+		}
+	}
+
+	page, err := repo.FindSubscriptions(ctx, &domain.SubscriptionFilter{UserID: userID, Limit: 10})
+	if err != nil {
+		t.Fatalf("FindSubscriptions: %v", err)
+	}
+
+	if page.Total != 1 || len(page.Items) != 1 || page.Items[0].Id != subs[0].Id {
+		t.Errorf("FindSubscriptions of active subscriptions = %+v, want only %s", page.Items, subs[0].Id)
+	}
+
+	page, err = repo.FindSubscriptions(ctx, &domain.SubscriptionFilter{
+		UserID:   userID,
+		Deleted:  true,
+		SortBy:   "price",
+		SortDesc: true,
+		Limit:    10,
+	})
+	if err != nil {
+		t.Fatalf("FindSubscriptions: %v", err)
+	}
+
+	if page.Total != 2 || len(page.Items) != 2 || page.Items[0].Price != 300 || page.Items[1].Price != 200 {
+		t.Fatalf("FindSubscriptions in trash = %+v, want prices 300, 200", page.Items)
+	}
+
+	if page.Items[0].DeletedAt == nil {
+		t.Errorf("FindSubscriptions in trash returned a subscription without deleted_at: %+v", page.Items[0])
+	}
+}
+
+func testHistory(t *testing.T, repo postgres.SubscriptionRepository) {
+	ctx := requestmeta.With(context.Background(), requestmeta.Meta{RequestID: "req-1", Actor: "alice"})
+	sub := newSubscription(uuid.New(), "Okko", 300, month(time.July, 2025), month(time.July, 2025))
+	if err := repo.CreateSubscription(ctx, sub); err != nil {
+		t.Fatalf("CreateSubscription: %v", err)
+	}
+
+	sub.Price = 350
+	if err := repo.UpdateSubscription(ctx, sub); err != nil {
+		t.Fatalf("UpdateSubscription: %v", err)
+	}
+
+	stale := *sub
+	stale.Version = 1
+	if err := repo.UpdateSubscription(ctx, &stale); !errors.Is(err, errors_package.ErrPreconditionFailed) {
+		t.Fatalf("UpdateSubscription with a stale version = %v, want ErrPreconditionFailed", err)
+	}
+
+	if err := repo.DeleteSubscription(ctx, sub.Id); err != nil {
+		t.Fatalf("DeleteSubscription: %v", err)
+	}
+
+	if _, err := repo.RestoreSubscription(context.Background(), sub.Id); err != nil {
+		t.Fatalf("RestoreSubscription: %v", err)
+	}
+
+	events, err := repo.ListSubscriptionEvents(context.Background(), sub.Id)
+	if err != nil {
+		t.Fatalf("ListSubscriptionEvents: %v", err)
+	}
+
+	want := []domain.EventType{domain.EventCreated, domain.EventUpdated, domain.EventDeleted, domain.EventRestored}
+	if len(events) != len(want) {
+		t.Fatalf("ListSubscriptionEvents returned %d events, want %d: %+v", len(events), len(want), events)
+	}
+
+	for i, event := range events {
+		if event.Type != want[i] || event.SubscriptionId != sub.Id {
+			t.Errorf("event %d = %s for %s, want %s for %s", i, event.Type, event.SubscriptionId, want[i], sub.Id)
+		}
+	}
+
+	created, updated, deleted, restored := events[0], events[1], events[2], events[3]
+	if created.Before != nil || created.After == nil || created.After.Price != 300 || created.After.Version != 1 {
+		t.Errorf("created event snapshots = %+v -> %+v", created.Before, created.After)
+	}
+
+	if updated.Before == nil || updated.After == nil || updated.Before.Price != 300 || updated.After.Price != 350 ||
+		updated.After.Version != 2 {
+		t.Errorf("updated event snapshots = %+v -> %+v", updated.Before, updated.After)
+	}
+
+	if deleted.Before == nil || deleted.Before.DeletedAt != nil ||
+		deleted.After == nil || deleted.After.DeletedAt == nil {
+		t.Errorf("deleted event snapshots = %+v -> %+v", deleted.Before, deleted.After)
+	}
+
+	if created.Actor != "alice" || created.RequestId != "req-1" {
+		t.Errorf("created event actor = %q request = %q, want alice and req-1", created.Actor, created.RequestId)
+	}
+
+	if restored.Actor != requestmeta.SystemActor || restored.RequestId != "" {
+		t.Errorf("restored event actor = %q request = %q, want the system actor without a request",
+			restored.Actor, restored.RequestId)
+	}
+
+	_, err = repo.ListSubscriptionEvents(context.Background(), uuid.New())
+	if !errors.Is(err, errors_package.ErrNotFound) {
+		t.Fatalf("ListSubscriptionEvents of a missing id = %v, want ErrNotFound", err)
+	}
+}
+
+func testStream(t *testing.T, repo postgres.SubscriptionRepository) {
+	ctx := context.Background()
+	userID := uuid.New()
+	for i, price := range []int{300, 100, 200} {
+		start := month(time.Month(i+1), 2025)
+		mustCreate(t, repo, newSubscription(userID, "Okko", price, start, start))
+	}
+	mustCreate(t, repo, newSubscription(uuid.New(), "Okko", 400, month(time.July, 2025), month(time.July, 2025)))
+
+	var prices []int
+	err := repo.StreamSubscriptions(ctx, &domain.SubscriptionFilter{UserID: userID, SortBy: "price", Limit: 1},
+		func(sub *domain.Subscription) error {
+			prices = append(prices, sub.Price)
+			return nil
+		})
+	if err != nil {
+		t.Fatalf("StreamSubscriptions: %v", err)
+	}
+
+	if len(prices) != 3 || prices[0] != 100 || prices[1] != 200 || prices[2] != 300 {
+		t.Errorf("StreamSubscriptions prices = %v, want [100 200 300] regardless of Limit", prices)
+	}
+
+	stop := errors.New("stop")
+	var calls int
+	err = repo.StreamSubscriptions(ctx, &domain.SubscriptionFilter{UserID: userID},
+		func(*domain.Subscription) error {
+			calls++
+			return stop
+		})
+	if !errors.Is(err, stop) || calls != 1 {
+		t.Errorf("StreamSubscriptions with a failing callback = %v after %d calls, want stop after 1", err, calls)
+	}
+}
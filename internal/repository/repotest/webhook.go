@@ -0,0 +1,151 @@
+package repotest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Aiszhio/Task/internal/domain"
+	errors_package "github.com/Aiszhio/Task/internal/errors"
+	"github.com/Aiszhio/Task/internal/repository/postgres"
+	"github.com/google/uuid"
+)
+
+func runWebhooks(t *testing.T, newStores Factory) {
+	t.Run("CreateAndDelete", func(t *testing.T) { testWebhookCreateAndDelete(t, newStores(t).Webhooks) })
+	t.Run("Enqueue", func(t *testing.T) { testWebhookEnqueue(t, newStores(t).Webhooks) })
+	t.Run("ClaimAndSave", func(t *testing.T) { testWebhookClaimAndSave(t, newStores(t).Webhooks) })
+}
+
+var webhookRetry = domain.RetryPolicy{MaxAttempts: 2, Base: time.Minute, Max: time.Hour}
+
+func mustCreateWebhook(t *testing.T, repo postgres.WebhookRepository,
+	eventTypes ...domain.WebhookEventType) *domain.Webhook {
+	t.Helper()
+
+	webhook := &domain.Webhook{
+		Id:         uuid.New(),
+		URL:        "http://127.0.0.1:9000/hook",
+		EventTypes: eventTypes,
+		Secret:     "0123456789abcdef",
+	}
+	if err := repo.CreateWebhook(context.Background(), webhook); err != nil {
+		t.Fatalf("CreateWebhook: %v", err)
+	}
+
+	return webhook
+}
+
+func newWebhookEvent(eventType domain.WebhookEventType, at time.Time) *domain.WebhookEvent {
+	return &domain.WebhookEvent{Id: uuid.New(), Type: eventType, OccurredAt: at}
+}
+
+func mustEnqueue(t *testing.T, repo postgres.WebhookRepository, event *domain.WebhookEvent) int64 {
+	t.Helper()
+
+	enqueued, err := repo.EnqueueDeliveries(context.Background(), event, []byte(`{"id":"`+event.Id.String()+`"}`))
+	if err != nil {
+		t.Fatalf("EnqueueDeliveries: %v", err)
+	}
+
+	return enqueued
+}
+
+func testWebhookCreateAndDelete(t *testing.T, repo postgres.WebhookRepository) {
+	ctx := context.Background()
+
+	webhook := mustCreateWebhook(t, repo, domain.WebhookSubscriptionCreated)
+	if webhook.CreatedAt.IsZero() {
+		t.Errorf("CreateWebhook did not set CreatedAt")
+	}
+
+	read, err := repo.ReadWebhook(ctx, webhook.Id)
+	if err != nil || read.URL != webhook.URL || read.Secret != webhook.Secret || len(read.EventTypes) != 1 {
+		t.Fatalf("ReadWebhook = %+v, %v, want %+v", read, err, webhook)
+	}
+
+	mustEnqueue(t, repo, newWebhookEvent(domain.WebhookSubscriptionCreated, time.Now().UTC()))
+
+	if err = repo.DeleteWebhook(ctx, webhook.Id); err != nil {
+		t.Fatalf("DeleteWebhook: %v", err)
+	}
+
+	if err = repo.DeleteWebhook(ctx, webhook.Id); !errors.Is(err, errors_package.ErrNotFound) {
+		t.Errorf("second DeleteWebhook = %v, want ErrNotFound", err)
+	}
+
+	deliveries, err := repo.ListDeliveries(ctx, webhook.Id, "", 10)
+	if err != nil || len(deliveries) != 0 {
+		t.Errorf("ListDeliveries of a deleted webhook = %d, %v, want none", len(deliveries), err)
+	}
+}
+
+func testWebhookEnqueue(t *testing.T, repo postgres.WebhookRepository) {
+	ctx := context.Background()
+
+	created := mustCreateWebhook(t, repo, domain.WebhookSubscriptionCreated)
+	mustCreateWebhook(t, repo, domain.WebhookSubscriptionDeleted)
+
+	event := newWebhookEvent(domain.WebhookSubscriptionCreated, time.Now().UTC())
+	if enqueued := mustEnqueue(t, repo, event); enqueued != 1 {
+		t.Errorf("EnqueueDeliveries = %d, want 1 for the subscribed webhook only", enqueued)
+	}
+
+	if enqueued := mustEnqueue(t, repo, event); enqueued != 0 {
+		t.Errorf("EnqueueDeliveries of the same event = %d, want 0", enqueued)
+	}
+
+	deliveries, err := repo.ListDeliveries(ctx, created.Id, domain.DeliveryPending, 10)
+	if err != nil || len(deliveries) != 1 {
+		t.Fatalf("ListDeliveries = %d, %v, want 1", len(deliveries), err)
+	}
+
+	if got := deliveries[0]; got.EventId != event.Id || got.EventType != event.Type || got.Attempts != 0 {
+		t.Errorf("delivery = %+v, want a pending delivery of %s", got, event.Id)
+	}
+}
+
+func testWebhookClaimAndSave(t *testing.T, repo postgres.WebhookRepository) {
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	webhook := mustCreateWebhook(t, repo, domain.WebhookSubscriptionUpdated)
+	mustEnqueue(t, repo, newWebhookEvent(domain.WebhookSubscriptionUpdated, now.Add(-time.Second)))
+	mustEnqueue(t, repo, newWebhookEvent(domain.WebhookSubscriptionUpdated, now.Add(time.Hour)))
+
+	jobs, err := repo.ClaimDeliveries(ctx, now, time.Minute, 10)
+	if err != nil || len(jobs) != 1 {
+		t.Fatalf("ClaimDeliveries = %d, %v, want the one due delivery", len(jobs), err)
+	}
+
+	if jobs[0].URL != webhook.URL || jobs[0].Secret != webhook.Secret {
+		t.Errorf("job = %+v, want the url and secret of the webhook", jobs[0])
+	}
+
+	if again, _ := repo.ClaimDeliveries(ctx, now, time.Minute, 10); len(again) != 0 {
+		t.Errorf("ClaimDeliveries during the lease = %d, want 0", len(again))
+	}
+
+	delivery := jobs[0].Delivery
+	delivery.Record(now, 500, errors.New("unexpected status 500"), webhookRetry)
+	if err = repo.SaveDelivery(ctx, &delivery); err != nil {
+		t.Fatalf("SaveDelivery: %v", err)
+	}
+
+	retry, err := repo.ClaimDeliveries(ctx, now.Add(webhookRetry.Base), time.Minute, 10)
+	if err != nil || len(retry) != 1 || retry[0].Delivery.Attempts != 1 || retry[0].Delivery.ResponseStatus != 500 {
+		t.Fatalf("ClaimDeliveries after the backoff = %+v, %v, want the failed delivery", retry, err)
+	}
+
+	delivery = retry[0].Delivery
+	delivery.Record(now.Add(webhookRetry.Base), 500, errors.New("unexpected status 500"), webhookRetry)
+	if err = repo.SaveDelivery(ctx, &delivery); err != nil {
+		t.Fatalf("SaveDelivery: %v", err)
+	}
+
+	failed, err := repo.ListDeliveries(ctx, webhook.Id, domain.DeliveryFailed, 10)
+	if err != nil || len(failed) != 1 || failed[0].Attempts != 2 || failed[0].LastError == "" {
+		t.Errorf("ListDeliveries(failed) = %+v, %v, want the delivery out of attempts", failed, err)
+	}
+}
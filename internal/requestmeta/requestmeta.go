@@ -0,0 +1,29 @@
+// Package requestmeta передаёт через context сведения о том, кто и в рамках какого запроса
+// выполняет операцию; их записывают журнал изменений и логи
+package requestmeta
+
+import "context"
+
+// SystemActor — исполнитель операций, запущенных самим сервисом, а не клиентом
+const SystemActor = "system"
+
+type Meta struct {
+	RequestID string
+	Actor     string
+}
+
+type metaKey struct{}
+
+func With(ctx context.Context, meta Meta) context.Context {
+	return context.WithValue(ctx, metaKey{}, meta)
+}
+
+// From возвращает сведения о запросе; без них операция считается выполненной SystemActor
+func From(ctx context.Context) Meta {
+	meta, _ := ctx.Value(metaKey{}).(Meta)
+	if meta.Actor == "" {
+		meta.Actor = SystemActor
+	}
+
+	return meta
+}
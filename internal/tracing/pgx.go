@@ -0,0 +1,82 @@
+package tracing
+
+import (
+	"context"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var (
+	_ pgx.QueryTracer       = (*QueryTracer)(nil)
+	_ pgxpool.AcquireTracer = (*QueryTracer)(nil)
+)
+
+// QueryTracer открывает спан на каждый запрос pgx, включая BEGIN и COMMIT транзакций,
+// и на ожидание соединения из пула. Подключается через pgx.ConnConfig.Tracer
+type QueryTracer struct {
+	tracer trace.Tracer
+}
+
+func NewQueryTracer() *QueryTracer {
+	return &QueryTracer{tracer: Tracer()}
+}
+
+func (t *QueryTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn,
+	data pgx.TraceQueryStartData) context.Context {
+	operation := queryOperation(data.SQL)
+
+	ctx, _ = t.tracer.Start(ctx, operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBSystemNamePostgreSQL,
+			semconv.DBOperationName(operation),
+			semconv.DBQueryText(data.SQL),
+		),
+	)
+
+	return ctx
+}
+
+func (t *QueryTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	span := trace.SpanFromContext(ctx)
+	fail(span, data.Err)
+	span.End()
+}
+
+func (t *QueryTracer) TraceAcquireStart(ctx context.Context, _ *pgxpool.Pool,
+	_ pgxpool.TraceAcquireStartData) context.Context {
+	ctx, _ = t.tracer.Start(ctx, "pool.acquire",
+		trace.WithAttributes(semconv.DBSystemNamePostgreSQL))
+
+	return ctx
+}
+
+func (t *QueryTracer) TraceAcquireEnd(ctx context.Context, _ *pgxpool.Pool, data pgxpool.TraceAcquireEndData) {
+	span := trace.SpanFromContext(ctx)
+	fail(span, data.Err)
+	span.End()
+}
+
+// queryOperation — первое слово запроса (SELECT, INSERT, BEGIN), имя спана без параметров запроса
+func queryOperation(sql string) string {
+	fields := strings.Fields(sql)
+	if len(fields) == 0 {
+		return "query"
+	}
+
+	return strings.ToUpper(strings.TrimSuffix(fields[0], ";"))
+}
+
+func fail(span trace.Span, err error) {
+	if err == nil {
+		return
+	}
+
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
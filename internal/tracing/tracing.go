@@ -0,0 +1,75 @@
+// Package tracing настраивает трассировку OpenTelemetry: экспорт спанов по OTLP, распространение
+// контекста W3C traceparent, спаны запросов к PostgreSQL и идентификаторы трассы в логах
+package tracing
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/Aiszhio/Task"
+
+// Экспортёры спанов
+const (
+	ExporterNone = "none"
+	ExporterOTLP = "otlp"
+)
+
+// Config — настройки трассировки. Адрес коллектора, заголовки, сэмплирование и имя сервиса
+// экспортёр и SDK дополнительно читают из стандартных переменных OTEL_*
+type Config struct {
+	ServiceName string
+	// Exporter — otlp (OTLP/HTTP) или none: спаны не экспортируются, но трассы всё равно создаются
+	Exporter string
+}
+
+// Tracer — трассировщик сервиса; до Setup спаны никуда не записываются
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
+
+// Setup устанавливает глобальные провайдер трассировки и пропагатор W3C Trace Context.
+// Возвращённая функция дописывает накопленные спаны и останавливает экспорт
+func Setup(ctx context.Context, cfg Config) (func(context.Context) error, error) {
+	res, err := resource.New(ctx,
+		resource.WithSchemaURL(semconv.SchemaURL),
+		resource.WithAttributes(semconv.ServiceName(cfg.ServiceName)),
+		resource.WithFromEnv(),
+		resource.WithTelemetrySDK(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("tracing resource: %w", err)
+	}
+
+	options := []sdktrace.TracerProviderOption{sdktrace.WithResource(res)}
+
+	switch cfg.Exporter {
+	case ExporterNone, "":
+	case ExporterOTLP:
+		exporter, err := otlptracehttp.New(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("otlp exporter: %w", err)
+		}
+		options = append(options, sdktrace.WithBatcher(exporter))
+	default:
+		return nil, fmt.Errorf("unknown trace exporter %q, expected %s or %s", cfg.Exporter, ExporterOTLP, ExporterNone)
+	}
+
+	provider := sdktrace.NewTracerProvider(options...)
+
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	return provider.Shutdown, nil
+}
@@ -0,0 +1,153 @@
+package transport
+
+import (
+	"github.com/Aiszhio/Task/internal/usecase"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type APIKeyRepository interface {
+	Create() gin.HandlerFunc
+	List() gin.HandlerFunc
+	Revoke() gin.HandlerFunc
+	Rotate() gin.HandlerFunc
+}
+
+type APIKeyHandler struct {
+	Repository usecase.APIKeyUseCase
+}
+
+func NewAPIKeyHandler(repo usecase.APIKeyUseCase) *APIKeyHandler {
+	return &APIKeyHandler{
+		Repository: repo,
+	}
+}
+
+// CreateAPIKey godoc
+// @Summary     Выпустить API-ключ
+// @Description Создаёт ключ для внутреннего сервиса с правами subscriptions:read, subscriptions:write,
+// @Description reports:read. Ключ возвращается только в этом ответе, хранится лишь его хеш.
+// @Description Доступно только администратору.
+// @Tags        api-keys
+// @Security    BearerAuth
+// @Accept      json
+// @Produce     json
+// @Param       body body     APIKeyRequest true "JSON"
+// @Success     201  {object} IssuedAPIKeyResponse
+// @Failure     400  {object} ProblemDetails
+// @Failure     401  {object} ProblemDetails
+// @Failure     403  {object} ProblemDetails
+// @Failure     422  {object} ProblemDetails
+// @Failure     429  {object} ProblemDetails
+// @Failure     500  {object} ProblemDetails
+// @Router      /api-keys [post]
+func (handler *APIKeyHandler) Create() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var req APIKeyRequest
+
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			respondError(ctx, invalidRequest(err))
+			return
+		}
+
+		key, secret, err := handler.Repository.CreateAPIKey(ctx.Request.Context(), req.Name, req.Scopes)
+		if err != nil {
+			respondError(ctx, err)
+			return
+		}
+
+		ctx.JSON(201, IssuedAPIKeyResponse{APIKey: *key, Key: secret})
+	}
+}
+
+// ListAPIKeys godoc
+// @Summary     Список API-ключей
+// @Description Возвращает все ключи, включая отозванные, с временем последнего использования.
+// @Description Доступно только администратору.
+// @Tags        api-keys
+// @Security    BearerAuth
+// @Produce     json
+// @Success     200  {object} APIKeyListResponse
+// @Failure     401  {object} ProblemDetails
+// @Failure     403  {object} ProblemDetails
+// @Failure     429  {object} ProblemDetails
+// @Failure     500  {object} ProblemDetails
+// @Router      /api-keys [get]
+func (handler *APIKeyHandler) List() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		keys, err := handler.Repository.ListAPIKeys(ctx.Request.Context())
+		if err != nil {
+			respondError(ctx, err)
+			return
+		}
+
+		ctx.JSON(200, APIKeyListResponse{Items: keys})
+	}
+}
+
+// RevokeAPIKey godoc
+// @Summary     Отозвать API-ключ
+// @Description Запросы с отозванным ключом получают 401. Повторный отзыв ничего не меняет.
+// @Tags        api-keys
+// @Security    BearerAuth
+// @Produce     json
+// @Param       id   path     string true "API key ID"
+// @Success     200  {object} domain.APIKey
+// @Failure     400  {object} ProblemDetails
+// @Failure     401  {object} ProblemDetails
+// @Failure     403  {object} ProblemDetails
+// @Failure     404  {object} ProblemDetails
+// @Failure     429  {object} ProblemDetails
+// @Failure     500  {object} ProblemDetails
+// @Router      /api-keys/{id} [delete]
+func (handler *APIKeyHandler) Revoke() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id, err := uuid.Parse(ctx.Param("id"))
+		if err != nil {
+			respondError(ctx, invalidParam("id", err))
+			return
+		}
+
+		key, err := handler.Repository.RevokeAPIKey(ctx.Request.Context(), id)
+		if err != nil {
+			respondError(ctx, err)
+			return
+		}
+
+		ctx.JSON(200, key)
+	}
+}
+
+// RotateAPIKey godoc
+// @Summary     Перевыпустить API-ключ
+// @Description Отзывает действующий ключ и выпускает новый с тем же именем и правами.
+// @Description Новый ключ возвращается только в этом ответе.
+// @Tags        api-keys
+// @Security    BearerAuth
+// @Produce     json
+// @Param       id   path     string true "API key ID"
+// @Success     201  {object} IssuedAPIKeyResponse
+// @Failure     400  {object} ProblemDetails
+// @Failure     401  {object} ProblemDetails
+// @Failure     403  {object} ProblemDetails
+// @Failure     404  {object} ProblemDetails
+// @Failure     429  {object} ProblemDetails
+// @Failure     500  {object} ProblemDetails
+// @Router      /api-keys/{id}/rotate [post]
+func (handler *APIKeyHandler) Rotate() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id, err := uuid.Parse(ctx.Param("id"))
+		if err != nil {
+			respondError(ctx, invalidParam("id", err))
+			return
+		}
+
+		key, secret, err := handler.Repository.RotateAPIKey(ctx.Request.Context(), id)
+		if err != nil {
+			respondError(ctx, err)
+			return
+		}
+
+		ctx.JSON(201, IssuedAPIKeyResponse{APIKey: *key, Key: secret})
+	}
+}
@@ -0,0 +1,81 @@
+package transport
+
+import (
+	"strings"
+
+	"github.com/Aiszhio/Task/internal/auth"
+	errors_package "github.com/Aiszhio/Task/internal/errors"
+	"github.com/Aiszhio/Task/internal/requestmeta"
+	"github.com/Aiszhio/Task/internal/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	bearerScheme = "Bearer"
+	apiKeyScheme = "ApiKey"
+)
+
+// Authenticate требует заголовок Authorization: Bearer <JWT> для пользователей или ApiKey <ключ>
+// для сервисов и кладёт субъекта в context запроса; он же становится исполнителем в журнале изменений
+func Authenticate(verifier *auth.Verifier, keys usecase.APIKeyUseCase) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		scheme, credentials, _ := strings.Cut(ctx.GetHeader("Authorization"), " ")
+		credentials = strings.TrimSpace(credentials)
+
+		var (
+			principal *auth.Principal
+			err       error
+		)
+
+		switch {
+		case credentials == "":
+			err = errors_package.Unauthorized("bearer token or api key is required")
+		case strings.EqualFold(scheme, bearerScheme):
+			principal, err = verifier.Verify(credentials)
+			if err != nil {
+				err = errors_package.Unauthorized("invalid token: %v", err)
+			}
+		case strings.EqualFold(scheme, apiKeyScheme):
+			principal, err = keys.AuthenticateAPIKey(ctx.Request.Context(), credentials)
+		default:
+			err = errors_package.Unauthorized("unsupported authorization scheme %q", scheme)
+		}
+
+		if err != nil {
+			unauthorized(ctx, err)
+			return
+		}
+
+		authenticated(ctx, principal)
+		ctx.Next()
+	}
+}
+
+// RequireScope пропускает сервисы, которым выдано право scope; пользователей ограничивают
+// проверки владельца подписки в usecase
+func RequireScope(scope string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		principal, ok := auth.FromContext(ctx.Request.Context())
+		if ok && !principal.Allows(scope) {
+			respondError(ctx, errors_package.Forbidden("api key lacks the %s scope", scope))
+			return
+		}
+
+		ctx.Next()
+	}
+}
+
+// authenticated связывает запрос с субъектом
+func authenticated(ctx *gin.Context, principal *auth.Principal) {
+	reqCtx := auth.WithPrincipal(ctx.Request.Context(), principal)
+
+	meta := requestmeta.From(reqCtx)
+	meta.Actor = principal.Subject
+
+	ctx.Request = ctx.Request.WithContext(requestmeta.With(reqCtx, meta))
+}
+
+func unauthorized(ctx *gin.Context, err error) {
+	ctx.Header("WWW-Authenticate", bearerScheme+", "+apiKeyScheme)
+	respondError(ctx, err)
+}
@@ -1,29 +1,53 @@
 package transport
 
 import (
+	"strings"
 	"time"
 
 	"github.com/Aiszhio/Task/internal/domain"
 	errors_package "github.com/Aiszhio/Task/internal/errors"
+	"github.com/google/uuid"
 )
 
+const (
+	defaultPage     = 1
+	defaultPageSize = 20
+)
+
+// parseUUID разбирает идентификатор, уже проверенный правилом uuid; пустая строка даёт uuid.Nil
+func parseUUID(s string) uuid.UUID {
+	id, _ := uuid.Parse(s)
+	return id
+}
+
 func makesubscription(Subscription *SubscriptionRequest) (*domain.Subscription, error) {
-	start := time.Time(Subscription.StartDate)
+	start := Subscription.StartDate.Time()
 	var end time.Time
 
+	interval := domain.BillingInterval(Subscription.BillingInterval)
+	if interval == "" {
+		interval = domain.BillingMonth
+	}
+
+	count := Subscription.IntervalCount
+	if count == 0 {
+		count = 1
+	}
+
+	// без даты окончания подписка бессрочна
 	if Subscription.EndDate != nil {
-		end = time.Time(*Subscription.EndDate)
-	} else {
-		end = start.AddDate(0, 1, 0)
+		end = Subscription.EndDate.Time()
 	}
 
 	subscr := &domain.Subscription{
-		Id:          Subscription.Id,
-		ServiceName: Subscription.ServiceName,
-		Price:       Subscription.Price,
-		StartDate:   start,
-		EndDate:     end,
-		UserId:      Subscription.UserID,
+		Id:              parseUUID(Subscription.Id),
+		ServiceName:     Subscription.ServiceName,
+		Price:           Subscription.Price,
+		BillingInterval: interval,
+		IntervalCount:   count,
+		StartDate:       start,
+		EndDate:         end,
+		UserId:          parseUUID(Subscription.UserID),
 	}
 
 	return subscr, nil
@@ -38,20 +62,36 @@ func TransportToDomain(Subscription *SubscriptionRequest) (*domain.Subscription,
 	return sub, nil
 }
 
-func makelistsubscriptions(req *SubscriptionSummaryRequest) (*domain.SubscriptionSummary, error) {
-	start := time.Time(req.StartDate)
-	end := time.Time(req.EndDate)
-	var zeroDate MonthYear
+// SubscriptionToTransport представляет подписку в виде тела запроса PUT, к которому применяются патчи
+func SubscriptionToTransport(sub *domain.Subscription) *SubscriptionRequest {
+	req := &SubscriptionRequest{
+		Id:              sub.Id.String(),
+		ServiceName:     sub.ServiceName,
+		Price:           sub.Price,
+		BillingInterval: string(sub.BillingInterval),
+		IntervalCount:   sub.IntervalCount,
+		UserID:          sub.UserId.String(),
+		StartDate:       MonthYear(sub.StartDate.Format(monthYearLayout)),
+	}
+
+	if !sub.EndDate.IsZero() {
+		end := MonthYear(sub.EndDate.Format(monthYearLayout))
+		req.EndDate = &end
+	}
 
-	if req.EndDate == zeroDate {
+	return req
+}
+
+func makelistsubscriptions(req *SubscriptionSummaryRequest) (*domain.SubscriptionSummary, error) {
+	if req.EndDate == "" {
 		return nil, errors_package.ErrDateRequired
 	}
 
 	return &domain.SubscriptionSummary{
-		UserID:      req.UserID,
+		UserID:      parseUUID(req.UserID),
 		ServiceName: req.ServiceName,
-		StartDate:   start,
-		EndDate:     end,
+		StartDate:   req.StartDate.Time(),
+		EndDate:     req.EndDate.Time(),
 	}, nil
 }
 
@@ -63,3 +103,85 @@ func ListToDomain(req *SubscriptionSummaryRequest) (*domain.SubscriptionSummary,
 
 	return list, nil
 }
+
+func makefilter(req *SubscriptionListRequest) (*domain.SubscriptionFilter, error) {
+	filter := &domain.SubscriptionFilter{
+		UserID:      parseUUID(req.UserID),
+		ServiceName: req.ServiceName,
+		MinPrice:    req.MinPrice,
+		MaxPrice:    req.MaxPrice,
+		SortBy:      strings.TrimPrefix(req.Sort, "-"),
+		SortDesc:    strings.HasPrefix(req.Sort, "-"),
+	}
+
+	if req.ActiveAt != "" {
+		activeAt := req.ActiveAt.Time()
+		filter.ActiveAt = &activeAt
+	}
+
+	if req.Page == 0 {
+		req.Page = defaultPage
+	}
+
+	if req.PageSize == 0 {
+		req.PageSize = defaultPageSize
+	}
+
+	filter.Limit = req.PageSize
+	filter.Offset = (req.Page - 1) * req.PageSize
+
+	return filter, nil
+}
+
+func FilterToDomain(req *SubscriptionListRequest) (*domain.SubscriptionFilter, error) {
+	filter, err := makefilter(req)
+	if err != nil {
+		return nil, err
+	}
+
+	return filter, nil
+}
+
+// ImportReportToTransport описывает отклонённые строки так же, как ошибки в ответах problem+json
+func ImportReportToTransport(report *domain.ImportReport) *ImportReportResponse {
+	resp := &ImportReportResponse{
+		Mode:      string(report.Mode),
+		DryRun:    report.DryRun,
+		Committed: report.Committed,
+		Accepted:  make([]ImportRowResponse, 0, len(report.Accepted)),
+		Rejected:  make([]ImportRowResponse, 0, len(report.Rejected)),
+	}
+
+	for _, row := range report.Accepted {
+		accepted := ImportRowResponse{Line: row.Line}
+		// идентификатор имеет смысл, только если подписка действительно сохранена
+		if report.Committed {
+			accepted.Id = row.Id.String()
+		}
+
+		resp.Accepted = append(resp.Accepted, accepted)
+	}
+
+	for _, row := range report.Rejected {
+		detail, fields := describe(row.Err)
+		resp.Rejected = append(resp.Rejected, ImportRowResponse{Line: row.Line, Detail: detail, Errors: fields})
+	}
+
+	return resp
+}
+
+func CostToTransport(cost *domain.SubscriptionCost) *SubscriptionCostResponse {
+	resp := &SubscriptionCostResponse{
+		TotalCost: cost.TotalCost,
+		Months:    make([]MonthCostResponse, 0, len(cost.Months)),
+	}
+
+	for _, month := range cost.Months {
+		resp.Months = append(resp.Months, MonthCostResponse{
+			Month: month.Month.Format(monthYearLayout),
+			Cost:  month.Cost,
+		})
+	}
+
+	return resp
+}
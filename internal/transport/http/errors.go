@@ -0,0 +1,189 @@
+package transport
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	errors_package "github.com/Aiszhio/Task/internal/errors"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+const problemContentType = "application/problem+json"
+
+var errUnsupportedPatch = errors.New("patch must be " + mergePatchContentType + " or " + jsonPatchContentType)
+
+// requestError — запрос не удалось разобрать: неверный JSON, параметр пути или query
+type requestError struct {
+	field string
+	err   error
+}
+
+func (e *requestError) Error() string {
+	return e.err.Error()
+}
+
+func (e *requestError) Unwrap() error {
+	return e.err
+}
+
+func invalidRequest(err error) error {
+	return &requestError{err: err}
+}
+
+// invalidParam — параметр пути или query с указанным именем имеет неверный формат
+func invalidParam(field string, err error) error {
+	return &requestError{field: field, err: err}
+}
+
+// statusFor сопоставляет вид ошибки с кодом HTTP-ответа
+func statusFor(err error) int {
+	var (
+		validationErrs validator.ValidationErrors
+		typeErr        *json.UnmarshalTypeError
+		reqErr         *requestError
+		tooLarge       *http.MaxBytesError
+	)
+
+	switch {
+	case errors.As(err, &tooLarge):
+		return http.StatusRequestEntityTooLarge
+	case errors.As(err, &validationErrs), errors.As(err, &typeErr), errors.Is(err, errors_package.ErrValidation):
+		return http.StatusUnprocessableEntity
+	case errors.As(err, &reqErr):
+		return http.StatusBadRequest
+	case errors.Is(err, errUnsupportedPatch):
+		return http.StatusUnsupportedMediaType
+	case errors.Is(err, errors_package.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, errors_package.ErrConflict):
+		return http.StatusConflict
+	case errors.Is(err, errors_package.ErrPreconditionFailed):
+		return http.StatusPreconditionFailed
+	case errors.Is(err, errors_package.ErrUnauthorized):
+		return http.StatusUnauthorized
+	case errors.Is(err, errors_package.ErrForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, errors_package.ErrTooManyRequests):
+		return http.StatusTooManyRequests
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// respondError отвечает клиенту application/problem+json с кодом, соответствующим ошибке;
+// детали внутренних сбоев в ответ не попадают и передаются в ctx.Errors для middleware логирования
+func respondError(ctx *gin.Context, err error) {
+	status := statusFor(err)
+
+	problem := ProblemDetails{
+		Type:     "about:blank",
+		Title:    http.StatusText(status),
+		Status:   status,
+		Instance: ctx.Request.URL.Path,
+	}
+
+	if status == http.StatusInternalServerError {
+		_ = ctx.Error(err)
+	} else {
+		problem.Detail, problem.Errors = describe(err)
+	}
+
+	writeProblem(ctx, problem)
+}
+
+func writeProblem(ctx *gin.Context, problem ProblemDetails) {
+	ctx.Header("Content-Type", problemContentType)
+	ctx.AbortWithStatusJSON(problem.Status, problem)
+}
+
+// describe формирует текст ошибки и список полей, не прошедших проверку
+func describe(err error) (string, []FieldError) {
+	var (
+		validationErrs validator.ValidationErrors
+		typeErr        *json.UnmarshalTypeError
+		domainErr      *errors_package.Error
+		reqErr         *requestError
+	)
+
+	switch {
+	case errors.As(err, &validationErrs):
+		fields := make([]FieldError, 0, len(validationErrs))
+		for _, fieldErr := range validationErrs {
+			fields = append(fields, FieldError{
+				Field:   fieldErr.Field(),
+				Code:    validationCode(fieldErr.Tag()),
+				Message: validationMessage(fieldErr),
+			})
+		}
+		return "request validation failed", fields
+	case errors.As(err, &typeErr):
+		message := fmt.Sprintf("must be of type %s", typeErr.Type)
+		return "request validation failed", []FieldError{{
+			Field:   typeErr.Field,
+			Code:    errors_package.CodeInvalidFormat,
+			Message: message,
+		}}
+	case errors.As(err, &domainErr) && domainErr.Field != "":
+		return domainErr.Message, []FieldError{{
+			Field:   domainErr.Field,
+			Code:    domainErr.Code,
+			Message: domainErr.Message,
+		}}
+	case errors.As(err, &reqErr) && reqErr.field != "":
+		return reqErr.Error(), []FieldError{{
+			Field:   reqErr.field,
+			Code:    errors_package.CodeInvalidFormat,
+			Message: reqErr.Error(),
+		}}
+	default:
+		return errors_package.Message(err), nil
+	}
+}
+
+func validationCode(tag string) string {
+	switch tag {
+	case "required":
+		return errors_package.CodeRequired
+	case "min":
+		return errors_package.CodeTooSmall
+	case "oneof":
+		return errors_package.CodeInvalidValue
+	case "uuid", "monthyear":
+		return errors_package.CodeInvalidFormat
+	default:
+		return tag
+	}
+}
+
+func validationMessage(fieldErr validator.FieldError) string {
+	switch fieldErr.Tag() {
+	case "required":
+		return "field is required"
+	case "min":
+		return "must be at least " + fieldErr.Param()
+	case "oneof":
+		return "must be one of: " + fieldErr.Param()
+	case "uuid":
+		return "must be a valid UUID"
+	case "monthyear":
+		return "must be a month in MM-YYYY format"
+	default:
+		return "failed on the " + fieldErr.Tag() + " rule"
+	}
+}
+
+// NoRoute отвечает problem+json на запросы к неизвестным маршрутам
+func NoRoute() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		writeProblem(ctx, ProblemDetails{
+			Type:     "about:blank",
+			Title:    http.StatusText(http.StatusNotFound),
+			Status:   http.StatusNotFound,
+			Detail:   "route not found",
+			Instance: ctx.Request.URL.Path,
+		})
+	}
+}
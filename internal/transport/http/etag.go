@@ -0,0 +1,33 @@
+package transport
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// etag формирует сильный ETag из версии подписки
+func etag(version int) string {
+	return strconv.Quote(strconv.Itoa(version))
+}
+
+// expectedVersion разбирает заголовок If-Match: без заголовка или со значением "*"
+// версия не проверяется и возвращается 0
+func expectedVersion(ifMatch string) (int, error) {
+	ifMatch = strings.TrimSpace(ifMatch)
+	if ifMatch == "" || ifMatch == "*" {
+		return 0, nil
+	}
+
+	unquoted, err := strconv.Unquote(ifMatch)
+	if err != nil {
+		return 0, fmt.Errorf("If-Match must be a single strong ETag, got %s", ifMatch)
+	}
+
+	version, err := strconv.Atoi(unquoted)
+	if err != nil || version <= 0 {
+		return 0, fmt.Errorf("If-Match %s is not a subscription ETag", ifMatch)
+	}
+
+	return version, nil
+}
@@ -0,0 +1,131 @@
+package transport
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Aiszhio/Task/internal/domain"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	exportFormatCSV    = "csv"
+	exportFormatNDJSON = "ndjson"
+
+	// exportFlushRows — через сколько строк выгрузка отправляется клиенту
+	exportFlushRows = 100
+)
+
+// exportColumns — столбцы CSV выгрузки. Импорт читает из них все поля подписки, включая расчётный период,
+// поэтому файл загружается обратно без изменения стоимости; id и отметки времени при этом назначаются заново
+var exportColumns = []string{
+	"service_name", "price", "user_id", "start_date", "end_date",
+	"id", "billing_interval", "interval_count", "created_at", "updated_at",
+}
+
+// exportWriter пишет подписки в ответ в выбранном формате; заголовки ответа отправляются при первой записи
+type exportWriter struct {
+	ctx    *gin.Context
+	format string
+	csv    *csv.Writer
+	json   *json.Encoder
+	rows   int
+}
+
+func newExportWriter(ctx *gin.Context, format string) *exportWriter {
+	return &exportWriter{ctx: ctx, format: format}
+}
+
+func (w *exportWriter) started() bool {
+	return w.csv != nil || w.json != nil
+}
+
+func (w *exportWriter) start() error {
+	contentType, extension := "text/csv; charset=utf-8", exportFormatCSV
+	if w.format == exportFormatNDJSON {
+		contentType, extension = "application/x-ndjson", exportFormatNDJSON
+	}
+
+	w.ctx.Header("Content-Type", contentType)
+	w.ctx.Header("Content-Disposition", `attachment; filename="subscriptions.`+extension+`"`)
+	w.ctx.Status(http.StatusOK)
+
+	if w.format == exportFormatNDJSON {
+		w.json = json.NewEncoder(w.ctx.Writer)
+		return nil
+	}
+
+	w.csv = csv.NewWriter(w.ctx.Writer)
+	return w.csv.Write(exportColumns)
+}
+
+func (w *exportWriter) write(sub *domain.Subscription) error {
+	if !w.started() {
+		if err := w.start(); err != nil {
+			return err
+		}
+	}
+
+	var err error
+	if w.json != nil {
+		err = w.json.Encode(sub)
+	} else {
+		err = w.csv.Write(exportRecord(sub))
+	}
+	if err != nil {
+		return err
+	}
+
+	w.rows++
+	if w.rows%exportFlushRows == 0 {
+		return w.flush()
+	}
+
+	return nil
+}
+
+// finish отправляет остаток выгрузки; пустая выгрузка содержит только заголовок CSV
+func (w *exportWriter) finish() error {
+	if !w.started() {
+		if err := w.start(); err != nil {
+			return err
+		}
+	}
+
+	return w.flush()
+}
+
+func (w *exportWriter) flush() error {
+	if w.csv != nil {
+		w.csv.Flush()
+		if err := w.csv.Error(); err != nil {
+			return err
+		}
+	}
+
+	w.ctx.Writer.Flush()
+	return nil
+}
+
+func exportRecord(sub *domain.Subscription) []string {
+	var end string
+	if !sub.EndDate.IsZero() {
+		end = sub.EndDate.Format(monthYearLayout)
+	}
+
+	return []string{
+		sub.ServiceName,
+		strconv.Itoa(sub.Price),
+		sub.UserId.String(),
+		sub.StartDate.Format(monthYearLayout),
+		end,
+		sub.Id.String(),
+		string(sub.BillingInterval),
+		strconv.Itoa(sub.IntervalCount),
+		sub.CreatedAt.Format(time.RFC3339),
+		sub.UpdatedAt.Format(time.RFC3339),
+	}
+}
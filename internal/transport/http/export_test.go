@@ -0,0 +1,53 @@
+package transport
+
+import (
+	"bytes"
+	"encoding/csv"
+	"testing"
+	"time"
+
+	"github.com/Aiszhio/Task/internal/domain"
+	"github.com/google/uuid"
+)
+
+func TestExportImportRoundTrip(t *testing.T) {
+	userId := uuid.New()
+	subs := []domain.Subscription{
+		{ServiceName: "Cloud", Price: 1200, BillingInterval: domain.BillingYear, IntervalCount: 1},
+		{ServiceName: "Gym", Price: 900, BillingInterval: domain.BillingQuarter, IntervalCount: 2,
+			EndDate: time.Date(2026, time.June, 1, 0, 0, 0, 0, time.UTC)},
+		{ServiceName: "Okko", Price: 300, BillingInterval: domain.BillingWeek, IntervalCount: 1},
+	}
+
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	_ = writer.Write(exportColumns)
+	for i := range subs {
+		subs[i].Id = uuid.New()
+		subs[i].UserId = userId
+		subs[i].StartDate = time.Date(2025, time.July, 1, 0, 0, 0, 0, time.UTC)
+		_ = writer.Write(exportRecord(&subs[i]))
+	}
+	writer.Flush()
+
+	rows, err := parseImport(&buf)
+	if err != nil {
+		t.Fatalf("parseImport: %v", err)
+	}
+	if len(rows) != len(subs) {
+		t.Fatalf("parseImport = %d rows, want %d", len(rows), len(subs))
+	}
+
+	for i, row := range rows {
+		if row.Err != nil {
+			t.Fatalf("line %d: %v", row.Line, row.Err)
+		}
+
+		got, want := row.Subscription, &subs[i]
+		if got.ServiceName != want.ServiceName || got.Price != want.Price || got.UserId != want.UserId ||
+			got.BillingInterval != want.BillingInterval || got.IntervalCount != want.IntervalCount ||
+			!got.StartDate.Equal(want.StartDate) || !got.EndDate.Equal(want.EndDate) {
+			t.Errorf("line %d = %+v, want %+v", row.Line, got, want)
+		}
+	}
+}
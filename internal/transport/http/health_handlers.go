@@ -0,0 +1,64 @@
+package transport
+
+import (
+	"github.com/Aiszhio/Task/internal/health"
+	"github.com/gin-gonic/gin"
+)
+
+type HealthRepository interface {
+	Live() gin.HandlerFunc
+	Ready() gin.HandlerFunc
+}
+
+type HealthHandler struct {
+	Probe *health.Probe
+}
+
+func NewHealthHandler(probe *health.Probe) *HealthHandler {
+	return &HealthHandler{
+		Probe: probe,
+	}
+}
+
+// Live godoc
+// @Summary     Проба живости
+// @Description Отвечает 200, пока процесс работает; состояние базы не проверяет
+// @Tags        health
+// @Produce     json
+// @Success     200 {object} HealthResponse
+// @Router      /healthz [get]
+func (handler *HealthHandler) Live() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.JSON(200, HealthResponse{Status: "ok"})
+	}
+}
+
+// Ready godoc
+// @Summary     Проба готовности
+// @Description Проверяет, что база отвечает и к ней применены все миграции. С началом остановки
+// @Description сервиса сразу отвечает 503, чтобы балансировщик перестал присылать запросы.
+// @Tags        health
+// @Produce     json
+// @Success     200 {object} HealthResponse
+// @Failure     503 {object} HealthResponse
+// @Router      /readyz [get]
+func (handler *HealthHandler) Ready() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		results, ready := handler.Probe.Ready(ctx.Request.Context())
+
+		checks := make(map[string]string, len(results))
+		for name, err := range results {
+			checks[name] = "ok"
+			if err != nil {
+				checks[name] = err.Error()
+			}
+		}
+
+		if !ready {
+			ctx.JSON(503, HealthResponse{Status: "unavailable", Checks: checks})
+			return
+		}
+
+		ctx.JSON(200, HealthResponse{Status: "ready", Checks: checks})
+	}
+}
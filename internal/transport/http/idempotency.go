@@ -0,0 +1,148 @@
+package transport
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/Aiszhio/Task/internal/auth"
+	"github.com/Aiszhio/Task/internal/domain"
+	errors_package "github.com/Aiszhio/Task/internal/errors"
+	"github.com/Aiszhio/Task/internal/repository/postgres"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	idempotencyKeyHeader      = "Idempotency-Key"
+	idempotentReplayedHeader  = "Idempotent-Replayed"
+	maxIdempotencyKeyLength   = 255
+	idempotencyReleaseTimeout = 5 * time.Second
+)
+
+// maxIdempotentBodyBytes ограничивает тело запроса с ключом: оно читается в память целиком ради хеша
+const maxIdempotentBodyBytes = 1 << 20
+
+var errIdempotencyKeyLength = fmt.Errorf("must be 1 to %d characters long", maxIdempotencyKeyLength)
+
+// responseRecorder копирует тело ответа, чтобы сохранить его для повторных запросов
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (r *responseRecorder) Write(data []byte) (int, error) {
+	r.body.Write(data)
+	return r.ResponseWriter.Write(data)
+}
+
+func (r *responseRecorder) WriteString(s string) (int, error) {
+	r.body.WriteString(s)
+	return r.ResponseWriter.WriteString(s)
+}
+
+// Idempotency выполняет запрос с заголовком Idempotency-Key не более одного раза за ttl:
+// повтор с тем же телом получает сохранённый ответ, повтор с другим телом — 422,
+// повтор во время выполнения первого запроса — 409, тело больше maxIdempotentBodyBytes — 413.
+// Ответы 5xx не сохраняются, и запрос можно повторить; если первый запрос не получил ответа
+// за lease (процесс упал), повтор выполняется заново.
+// Ключи разных субъектов не пересекаются.
+func Idempotency(store postgres.IdempotencyRepository, ttl, lease time.Duration,
+	logger *slog.Logger) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		header := ctx.GetHeader(idempotencyKeyHeader)
+		if header == "" {
+			ctx.Next()
+			return
+		}
+
+		if len(header) > maxIdempotencyKeyLength {
+			respondError(ctx, invalidParam(idempotencyKeyHeader, errIdempotencyKeyLength))
+			return
+		}
+
+		key := scopedKey(ctx.Request.Context(), header)
+
+		body, err := io.ReadAll(http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxIdempotentBodyBytes))
+		if err != nil {
+			respondError(ctx, invalidRequest(err))
+			return
+		}
+		ctx.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		hash := requestHash(ctx.Request, body)
+
+		stored, claimed, err := store.ClaimKey(ctx.Request.Context(), key, hash, ttl, lease)
+		if err != nil {
+			respondError(ctx, err)
+			return
+		}
+
+		if !claimed {
+			replay(ctx, stored, header, hash)
+			return
+		}
+
+		recorder := &responseRecorder{ResponseWriter: ctx.Writer}
+		ctx.Writer = recorder
+
+		ctx.Next()
+
+		if ctx.Writer.Status() >= http.StatusInternalServerError {
+			releaseCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx.Request.Context()),
+				idempotencyReleaseTimeout)
+			defer cancel()
+
+			if err = store.ReleaseKey(releaseCtx, stored); err != nil {
+				logger.ErrorContext(ctx.Request.Context(), "failed to release idempotency key", "key", key, "err", err)
+			}
+			return
+		}
+
+		stored.StatusCode = ctx.Writer.Status()
+		stored.ContentType = ctx.Writer.Header().Get("Content-Type")
+		stored.Body = recorder.body.Bytes()
+
+		if err = store.SaveResponse(context.WithoutCancel(ctx.Request.Context()), stored); err != nil {
+			logger.ErrorContext(ctx.Request.Context(), "failed to save idempotent response", "key", key, "err", err)
+		}
+	}
+}
+
+// scopedKey привязывает ключ к субъекту запроса, чтобы клиенты не получали чужие ответы
+func scopedKey(ctx context.Context, key string) string {
+	if principal, ok := auth.FromContext(ctx); ok {
+		return principal.Subject + ":" + key
+	}
+
+	return key
+}
+
+// replay отвечает на повторный запрос сохранённым ответом первого
+func replay(ctx *gin.Context, stored *domain.IdempotentRequest, header, hash string) {
+	switch {
+	case stored.RequestHash != hash:
+		respondError(ctx, errors_package.Validation(idempotencyKeyHeader, errors_package.CodeInvalidValue,
+			"idempotency key was already used with a different request"))
+	case !stored.Completed():
+		respondError(ctx, errors_package.Conflict("request with idempotency key %q is still in progress", header))
+	default:
+		ctx.Header(idempotentReplayedHeader, "true")
+		ctx.Data(stored.StatusCode, stored.ContentType, stored.Body)
+		ctx.Abort()
+	}
+}
+
+// requestHash отличает запросы по методу, пути и телу
+func requestHash(req *http.Request, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(req.Method + " " + req.URL.Path + "\n"))
+	hash.Write(body)
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
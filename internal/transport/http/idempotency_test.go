@@ -0,0 +1,169 @@
+package transport
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Aiszhio/Task/internal/repository/memory"
+	"github.com/Aiszhio/Task/internal/repository/postgres"
+	"github.com/gin-gonic/gin"
+)
+
+const idempotencyTestKey = "3f0c2a9e-onboarding-42"
+
+// newIdempotentRouter отвечает на POST /subscriptions статусами из statuses по очереди
+// и считает вызовы обработчика
+func newIdempotentRouter(store postgres.IdempotencyRepository, lease time.Duration, calls *atomic.Int32,
+	statuses ...int) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.POST("/subscriptions", Idempotency(store, time.Hour, lease, slog.New(slog.DiscardHandler)),
+		func(ctx *gin.Context) {
+			call := int(calls.Add(1))
+			status := statuses[min(call, len(statuses))-1]
+			ctx.JSON(status, gin.H{"call": call})
+		})
+
+	return router
+}
+
+func postIdempotent(router http.Handler, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/subscriptions", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(idempotencyKeyHeader, idempotencyTestKey)
+
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	return resp
+}
+
+func TestIdempotencyReplay(t *testing.T) {
+	var calls atomic.Int32
+	router := newIdempotentRouter(memory.NewIdempotencyRepository(slog.New(slog.DiscardHandler)), time.Minute,
+		&calls, http.StatusCreated)
+
+	first := postIdempotent(router, `{"price": 400}`)
+	second := postIdempotent(router, `{"price": 400}`)
+
+	if calls.Load() != 1 {
+		t.Errorf("handler called %d times, want 1", calls.Load())
+	}
+	if second.Code != http.StatusCreated || second.Body.String() != first.Body.String() {
+		t.Errorf("replay = %d %s, want %d %s", second.Code, second.Body, first.Code, first.Body)
+	}
+	if second.Header().Get(idempotentReplayedHeader) != "true" {
+		t.Errorf("replay has no %s header", idempotentReplayedHeader)
+	}
+	if first.Header().Get(idempotentReplayedHeader) != "" {
+		t.Errorf("first response has the %s header", idempotentReplayedHeader)
+	}
+}
+
+func TestIdempotencyBodyTooLarge(t *testing.T) {
+	var calls atomic.Int32
+	router := newIdempotentRouter(memory.NewIdempotencyRepository(slog.New(slog.DiscardHandler)), time.Minute,
+		&calls, http.StatusCreated)
+
+	body := `{"service_name": "` + strings.Repeat("x", maxIdempotentBodyBytes) + `"}`
+	if resp := postIdempotent(router, body); resp.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("oversized request = %d, want 413", resp.Code)
+	}
+	if calls.Load() != 0 {
+		t.Errorf("handler called %d times, want the request rejected before it", calls.Load())
+	}
+
+	// ключ не занят отклонённым запросом
+	if resp := postIdempotent(router, `{"price": 400}`); resp.Code != http.StatusCreated {
+		t.Errorf("request after the oversized one = %d, want 201", resp.Code)
+	}
+}
+
+func TestIdempotencyConflict(t *testing.T) {
+	var calls atomic.Int32
+	router := newIdempotentRouter(memory.NewIdempotencyRepository(slog.New(slog.DiscardHandler)), time.Minute,
+		&calls, http.StatusCreated)
+
+	postIdempotent(router, `{"price": 400}`)
+	if resp := postIdempotent(router, `{"price": 500}`); resp.Code != http.StatusUnprocessableEntity {
+		t.Errorf("reuse with another body = %d, want 422", resp.Code)
+	}
+	if calls.Load() != 1 {
+		t.Errorf("handler called %d times, want 1", calls.Load())
+	}
+}
+
+func TestIdempotencyInProgress(t *testing.T) {
+	store := memory.NewIdempotencyRepository(slog.New(slog.DiscardHandler))
+	release := make(chan struct{})
+	started := make(chan struct{})
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/subscriptions", Idempotency(store, time.Hour, time.Minute, slog.New(slog.DiscardHandler)),
+		func(ctx *gin.Context) {
+			close(started)
+			<-release
+			ctx.JSON(http.StatusCreated, gin.H{})
+		})
+
+	done := make(chan *httptest.ResponseRecorder)
+	go func() { done <- postIdempotent(router, `{"price": 400}`) }()
+	<-started
+
+	if resp := postIdempotent(router, `{"price": 400}`); resp.Code != http.StatusConflict {
+		t.Errorf("retry during the first request = %d, want 409", resp.Code)
+	}
+
+	close(release)
+	if resp := <-done; resp.Code != http.StatusCreated {
+		t.Errorf("first request = %d, want 201", resp.Code)
+	}
+}
+
+func TestIdempotencyReleaseOnServerError(t *testing.T) {
+	var calls atomic.Int32
+	router := newIdempotentRouter(memory.NewIdempotencyRepository(slog.New(slog.DiscardHandler)), time.Minute,
+		&calls, http.StatusInternalServerError, http.StatusCreated)
+
+	if resp := postIdempotent(router, `{"price": 400}`); resp.Code != http.StatusInternalServerError {
+		t.Fatalf("first request = %d, want 500", resp.Code)
+	}
+
+	if resp := postIdempotent(router, `{"price": 400}`); resp.Code != http.StatusCreated {
+		t.Errorf("retry after 500 = %d, want 201", resp.Code)
+	}
+	if calls.Load() != 2 {
+		t.Errorf("handler called %d times, want 2", calls.Load())
+	}
+}
+
+func TestIdempotencyAbandonedKey(t *testing.T) {
+	var calls atomic.Int32
+	store := memory.NewIdempotencyRepository(slog.New(slog.DiscardHandler))
+	router := newIdempotentRouter(store, time.Millisecond, &calls, http.StatusCreated)
+
+	// первый запрос занял ключ, но процесс упал, не сохранив ответ и не освободив ключ
+	req := httptest.NewRequest(http.MethodPost, "/subscriptions", nil)
+	hash := requestHash(req, []byte(`{"price": 400}`))
+	key := scopedKey(context.Background(), idempotencyTestKey)
+	if _, _, err := store.ClaimKey(context.Background(), key, hash, time.Hour, time.Millisecond); err != nil {
+		t.Fatalf("ClaimKey: %v", err)
+	}
+
+	time.Sleep(10 * time.Millisecond)
+
+	if resp := postIdempotent(router, `{"price": 400}`); resp.Code != http.StatusCreated {
+		t.Errorf("retry after the lease = %d, want 201", resp.Code)
+	}
+	if calls.Load() != 1 {
+		t.Errorf("handler called %d times, want 1", calls.Load())
+	}
+}
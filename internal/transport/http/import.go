@@ -0,0 +1,152 @@
+package transport
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/Aiszhio/Task/internal/domain"
+	errors_package "github.com/Aiszhio/Task/internal/errors"
+	"github.com/gin-gonic/gin/binding"
+)
+
+const (
+	maxImportRows  = 1000
+	maxImportBytes = 5 << 20
+)
+
+// importColumns — столбцы CSV импорта; остальные столбцы, например id из выгрузки, пропускаются
+var importColumns = []string{
+	"service_name", "price", "billing_interval", "interval_count", "user_id", "start_date", "end_date",
+}
+
+// optionalImportColumns можно опустить: user_id берётся из токена, без расчётного периода подписка
+// помесячная, как и в JSON API, а без end_date — бессрочная
+var optionalImportColumns = map[string]bool{
+	"billing_interval": true,
+	"interval_count":   true,
+	"user_id":          true,
+	"end_date":         true,
+}
+
+var errImportTooLarge = fmt.Errorf("import is limited to %d rows and %d bytes", maxImportRows, maxImportBytes)
+
+// parseImport читает CSV с заголовком из importColumns (в любом порядке) и превращает строки в подписки.
+// Ошибки отдельных строк сохраняются в ImportRow.Err, ошибка формата файла прерывает разбор.
+func parseImport(r io.Reader) ([]domain.ImportRow, error) {
+	reader := csv.NewReader(io.LimitReader(r, maxImportBytes+1))
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, errors.New("csv is empty: expected a header row")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	columns, err := importHeader(header)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []domain.ImportRow
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if len(rows) == maxImportRows {
+			return nil, errImportTooLarge
+		}
+
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) && errors.Is(parseErr.Err, csv.ErrFieldCount) {
+			rows = append(rows, domain.ImportRow{Line: parseErr.StartLine, Err: errors_package.Validation("",
+				errors_package.CodeInvalidFormat, fmt.Sprintf("expected %d fields, got %d", len(header), len(record)))})
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		line, _ := reader.FieldPos(0)
+		sub, err := importRow(record, columns)
+		rows = append(rows, domain.ImportRow{Line: line, Subscription: sub, Err: err})
+	}
+
+	if reader.InputOffset() > maxImportBytes {
+		return nil, errImportTooLarge
+	}
+
+	return rows, nil
+}
+
+// importHeader сопоставляет имена столбцов с их позициями в строке
+func importHeader(header []string) (map[string]int, error) {
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if _, ok := columns[name]; ok {
+			return nil, fmt.Errorf("csv header has duplicate column %q", name)
+		}
+
+		columns[name] = i
+	}
+
+	for _, name := range importColumns {
+		if _, ok := columns[name]; !ok && !optionalImportColumns[name] {
+			return nil, fmt.Errorf("csv header is missing column %q", name)
+		}
+	}
+
+	return columns, nil
+}
+
+func importRow(record []string, columns map[string]int) (*domain.Subscription, error) {
+	field := func(name string) string {
+		if i, ok := columns[name]; ok {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	req := SubscriptionRequest{
+		ServiceName:     field("service_name"),
+		BillingInterval: field("billing_interval"),
+		UserID:          field("user_id"),
+		StartDate:       MonthYear(field("start_date")),
+	}
+
+	if price := field("price"); price != "" {
+		value, err := strconv.Atoi(price)
+		if err != nil {
+			return nil, errors_package.Validation("price", errors_package.CodeInvalidFormat, "must be an integer")
+		}
+		req.Price = value
+	}
+
+	if count := field("interval_count"); count != "" {
+		value, err := strconv.Atoi(count)
+		if err != nil {
+			return nil, errors_package.Validation("interval_count", errors_package.CodeInvalidFormat,
+				"must be an integer")
+		}
+		req.IntervalCount = value
+	}
+
+	if end := field("end_date"); end != "" {
+		endDate := MonthYear(end)
+		req.EndDate = &endDate
+	}
+
+	if err := binding.Validator.ValidateStruct(&req); err != nil {
+		return nil, err
+	}
+
+	return TransportToDomain(&req)
+}
@@ -0,0 +1,89 @@
+package transport
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Aiszhio/Task/internal/domain"
+)
+
+const importUser = "60601fee-2bf1-4721-ae6f-7636e79a0cba"
+
+func TestParseImportIntervals(t *testing.T) {
+	csv := "service_name,price,billing_interval,interval_count,user_id,start_date,end_date\n" +
+		"Cloud,1200,year,1," + importUser + ",07-2025,\n" +
+		"Gym,900,quarter,2," + importUser + ",07-2025,06-2026\n" +
+		"Okko,300,,," + importUser + ",07-2025,12-2025\n"
+
+	rows, err := parseImport(strings.NewReader(csv))
+	if err != nil {
+		t.Fatalf("parseImport: %v", err)
+	}
+
+	want := []struct {
+		interval domain.BillingInterval
+		count    int
+	}{
+		{domain.BillingYear, 1},
+		{domain.BillingQuarter, 2},
+		{domain.BillingMonth, 1},
+	}
+
+	if len(rows) != len(want) {
+		t.Fatalf("parseImport = %d rows, want %d", len(rows), len(want))
+	}
+
+	for i, row := range rows {
+		if row.Err != nil {
+			t.Fatalf("line %d: %v", row.Line, row.Err)
+		}
+		if row.Subscription.BillingInterval != want[i].interval || row.Subscription.IntervalCount != want[i].count {
+			t.Errorf("line %d = %s x%d, want %s x%d", row.Line, row.Subscription.BillingInterval,
+				row.Subscription.IntervalCount, want[i].interval, want[i].count)
+		}
+	}
+
+	if !rows[0].Subscription.EndDate.IsZero() {
+		t.Errorf("line %d EndDate = %v, want open-ended", rows[0].Line, rows[0].Subscription.EndDate)
+	}
+}
+
+func TestParseImportWithoutIntervalColumns(t *testing.T) {
+	rows, err := parseImport(strings.NewReader("service_name,price,start_date\nOkko,300,07-2025\n"))
+	if err != nil || len(rows) != 1 || rows[0].Err != nil {
+		t.Fatalf("parseImport = %+v, %v, want one valid row", rows, err)
+	}
+
+	if sub := rows[0].Subscription; sub.BillingInterval != domain.BillingMonth || sub.IntervalCount != 1 {
+		t.Errorf("subscription = %s x%d, want month x1", sub.BillingInterval, sub.IntervalCount)
+	}
+}
+
+func TestParseImportInvalidIntervals(t *testing.T) {
+	tests := []struct {
+		name     string
+		interval string
+		count    string
+	}{
+		{"unknown interval", "fortnight", "1"},
+		{"count not a number", "month", "two"},
+		{"negative count", "month", "-1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			csv := "service_name,price,billing_interval,interval_count,start_date\n" +
+				"Okko,300," + tt.interval + "," + tt.count + ",07-2025\n"
+
+			rows, err := parseImport(strings.NewReader(csv))
+			if err != nil || len(rows) != 1 {
+				t.Fatalf("parseImport = %+v, %v, want one row", rows, err)
+			}
+
+			_, fields := describe(rows[0].Err)
+			if len(fields) != 1 || (fields[0].Field != "billing_interval" && fields[0].Field != "interval_count") {
+				t.Errorf("row error = %v (%+v), want an error on the interval", rows[0].Err, fields)
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package transport
+
+import (
+	"encoding/json"
+	"mime"
+
+	"github.com/Aiszhio/Task/internal/domain"
+	errors_package "github.com/Aiszhio/Task/internal/errors"
+	jsonpatch "github.com/evanphx/json-patch/v5"
+	"github.com/gin-gonic/gin/binding"
+)
+
+const (
+	mergePatchContentType = "application/merge-patch+json"
+	jsonPatchContentType  = "application/json-patch+json"
+)
+
+// applyPatch применяет к подписке RFC 6902 JSON Patch или RFC 7396 JSON Merge Patch
+// в зависимости от Content-Type и проверяет результат по правилам тела PUT. Удалённая патчем
+// end_date ("end_date": null или операция remove) делает подписку бессрочной
+func applyPatch(current *domain.Subscription, contentType string, patch []byte) (*domain.Subscription, error) {
+	original, err := json.Marshal(SubscriptionToTransport(current))
+	if err != nil {
+		return nil, errors_package.Internal(err)
+	}
+
+	var patched []byte
+
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch mediaType {
+	case jsonPatchContentType:
+		operations, err := jsonpatch.DecodePatch(patch)
+		if err != nil {
+			return nil, invalidRequest(err)
+		}
+
+		// операции корректны, но неприменимы к текущему состоянию подписки (RFC 5789, 409)
+		patched, err = operations.Apply(original)
+		if err != nil {
+			return nil, errors_package.Conflict("json patch cannot be applied: %v", err)
+		}
+	case mergePatchContentType, binding.MIMEJSON, "":
+		patched, err = jsonpatch.MergePatch(original, patch)
+		if err != nil {
+			return nil, invalidRequest(err)
+		}
+	default:
+		return nil, errUnsupportedPatch
+	}
+
+	var req SubscriptionRequest
+	if err = json.Unmarshal(patched, &req); err != nil {
+		return nil, invalidRequest(err)
+	}
+
+	if req.Id != current.Id.String() {
+		return nil, errors_package.Validation("id", errors_package.CodeImmutable, "id of a subscription cannot be changed")
+	}
+
+	if err = binding.Validator.ValidateStruct(&req); err != nil {
+		return nil, err
+	}
+
+	return TransportToDomain(&req)
+}
@@ -0,0 +1,59 @@
+package transport
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Aiszhio/Task/internal/domain"
+	"github.com/google/uuid"
+)
+
+func patchedSubscription() *domain.Subscription {
+	return &domain.Subscription{
+		Id:              uuid.New(),
+		ServiceName:     "Yandex Plus",
+		Price:           400,
+		BillingInterval: domain.BillingYear,
+		IntervalCount:   1,
+		UserId:          uuid.New(),
+		StartDate:       time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC),
+		EndDate:         time.Date(2026, time.February, 1, 0, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestApplyPatch(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		patch       string
+		price       int
+		openEnded   bool
+	}{
+		{"merge price", mergePatchContentType, `{"price": 650}`, 650, false},
+		{"merge null end_date", mergePatchContentType, `{"end_date": null}`, 400, true},
+		{"json patch remove end_date", jsonPatchContentType, `[{"op": "remove", "path": "/end_date"}]`, 400, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			current := patchedSubscription()
+
+			got, err := applyPatch(current, tt.contentType, []byte(tt.patch))
+			if err != nil {
+				t.Fatalf("applyPatch: %v", err)
+			}
+
+			if got.Price != tt.price || got.BillingInterval != current.BillingInterval ||
+				!got.StartDate.Equal(current.StartDate) {
+				t.Errorf("applyPatch = %+v, want price %d and the other fields of %+v", got, tt.price, current)
+			}
+
+			if got.EndDate.IsZero() != tt.openEnded {
+				t.Errorf("EndDate = %v, want open-ended %v", got.EndDate, tt.openEnded)
+			}
+			if !tt.openEnded && !got.EndDate.Equal(current.EndDate) {
+				t.Errorf("EndDate = %v, want %v", got.EndDate, current.EndDate)
+			}
+		})
+	}
+}
@@ -0,0 +1,163 @@
+package transport
+
+import (
+	"fmt"
+	"log/slog"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Aiszhio/Task/internal/auth"
+	"github.com/Aiszhio/Task/internal/domain"
+	errors_package "github.com/Aiszhio/Task/internal/errors"
+	"github.com/Aiszhio/Task/internal/repository/postgres"
+	"github.com/gin-gonic/gin"
+)
+
+// RateLimits — лимиты запросов: Routes по маршруту вида "POST /subscriptions/list",
+// Default — для остальных маршрутов; без Default остальные маршруты не ограничены.
+// PerIP — общий лимит всех запросов с одного IP, в том числе с неверными учётными данными
+type RateLimits struct {
+	Default *domain.RateLimit
+	Routes  map[string]domain.RateLimit
+	PerIP   *domain.RateLimit
+}
+
+// ParseRateLimits разбирает лимит по умолчанию и лимит одного IP (например, 300/m; пустая строка —
+// без лимита) и лимиты маршрутов через запятую: "POST /subscriptions/list=60/m,GET /subscriptions/export=10/m"
+func ParseRateLimits(def, routes, perIP string) (RateLimits, error) {
+	limits := RateLimits{Routes: make(map[string]domain.RateLimit)}
+
+	for _, optional := range []struct {
+		value string
+		limit **domain.RateLimit
+	}{{def, &limits.Default}, {perIP, &limits.PerIP}} {
+		if strings.TrimSpace(optional.value) == "" {
+			continue
+		}
+
+		limit, err := domain.ParseRateLimit(optional.value)
+		if err != nil {
+			return RateLimits{}, err
+		}
+		*optional.limit = &limit
+	}
+
+	for _, entry := range strings.Split(routes, ",") {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+
+		route, value, ok := strings.Cut(entry, "=")
+		method, path, hasPath := strings.Cut(strings.TrimSpace(route), " ")
+		if !ok || !hasPath {
+			return RateLimits{}, fmt.Errorf("invalid route rate limit %q: expected <METHOD> <path>=<limit>", entry)
+		}
+
+		limit, err := domain.ParseRateLimit(value)
+		if err != nil {
+			return RateLimits{}, err
+		}
+
+		limits.Routes[strings.ToUpper(method)+" "+strings.TrimSpace(path)] = limit
+	}
+
+	return limits, nil
+}
+
+func (l RateLimits) forRoute(route string) (domain.RateLimit, bool) {
+	if limit, ok := l.Routes[route]; ok {
+		return limit, true
+	}
+
+	if l.Default != nil {
+		return *l.Default, true
+	}
+
+	return domain.RateLimit{}, false
+}
+
+// Longest — самый длинный период среди лимитов: ведро, простаивающее дольше, заведомо полное
+func (l RateLimits) Longest() time.Duration {
+	var longest time.Duration
+	for _, limit := range []*domain.RateLimit{l.Default, l.PerIP} {
+		if limit != nil {
+			longest = max(longest, limit.Per)
+		}
+	}
+
+	for _, limit := range l.Routes {
+		longest = max(longest, limit.Per)
+	}
+
+	return longest
+}
+
+// RateLimit ограничивает частоту запросов каждого клиента к каждому маршруту и сообщает состояние лимита
+// в заголовках RateLimit-*. Клиент — API-ключ или пользователь, если запрос аутентифицирован, иначе IP.
+// Должен стоять после Authenticate. Сбой хранилища лимитов не блокирует запросы.
+func RateLimit(store postgres.RateLimitRepository, limits RateLimits, logger *slog.Logger) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		route := ctx.Request.Method + " " + ctx.FullPath()
+
+		limit, ok := limits.forRoute(route)
+		if !ok || takeToken(ctx, store, route+" "+rateLimitClient(ctx), limit, logger) {
+			ctx.Next()
+		}
+	}
+}
+
+// RateLimitByIP ограничивает все запросы с одного IP лимитом PerIP. Должен стоять до Authenticate:
+// запросы с неверным токеном или API-ключом тоже расходуют лимит, и перебор ключей не может
+// без ограничений нагружать хранилище ключей
+func RateLimitByIP(store postgres.RateLimitRepository, limits RateLimits, logger *slog.Logger) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if limits.PerIP == nil || takeToken(ctx, store, "* ip:"+ctx.ClientIP(), *limits.PerIP, logger) {
+			ctx.Next()
+		}
+	}
+}
+
+// takeToken забирает токен из ведра key и сообщает состояние лимита в заголовках; если токена нет,
+// отвечает 429 и возвращает false
+func takeToken(ctx *gin.Context, store postgres.RateLimitRepository, key string, limit domain.RateLimit,
+	logger *slog.Logger) bool {
+	decision, err := store.TakeToken(ctx.Request.Context(), key, limit)
+	if err != nil {
+		logger.ErrorContext(ctx.Request.Context(), "rate limit unavailable, request let through", "key", key, "err", err)
+		return true
+	}
+
+	ctx.Header("RateLimit-Limit", strconv.Itoa(limit.Requests))
+	ctx.Header("RateLimit-Remaining", strconv.Itoa(decision.Remaining))
+	ctx.Header("RateLimit-Reset", seconds(decision.Reset))
+	ctx.Header("RateLimit-Policy", fmt.Sprintf("%d;w=%s", limit.Requests, seconds(limit.Per)))
+
+	if !decision.Allowed {
+		ctx.Header("Retry-After", seconds(decision.RetryAfter))
+		respondError(ctx, errors_package.TooManyRequests("rate limit of %s exceeded, retry in %s seconds",
+			limit, seconds(decision.RetryAfter)))
+		return false
+	}
+
+	return true
+}
+
+// rateLimitClient определяет, чей это запрос
+func rateLimitClient(ctx *gin.Context) string {
+	principal, ok := auth.FromContext(ctx.Request.Context())
+	switch {
+	case ok && principal.Service:
+		return principal.Subject
+	case ok:
+		return "user:" + principal.Subject
+	default:
+		return "ip:" + ctx.ClientIP()
+	}
+}
+
+// seconds округляет длительность вверх до целых секунд
+func seconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
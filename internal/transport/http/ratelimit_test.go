@@ -0,0 +1,139 @@
+package transport
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Aiszhio/Task/internal/auth"
+	"github.com/Aiszhio/Task/internal/domain"
+	errors_package "github.com/Aiszhio/Task/internal/errors"
+	"github.com/Aiszhio/Task/internal/repository/memory"
+	"github.com/Aiszhio/Task/internal/usecase"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func TestParseRateLimits(t *testing.T) {
+	limits, err := ParseRateLimits("300/m", "post /subscriptions/list=60/m, GET /subscriptions/export=10/m", "")
+	if err != nil {
+		t.Fatalf("ParseRateLimits: %v", err)
+	}
+
+	if limit, _ := limits.forRoute("POST /subscriptions/list"); limit.Requests != 60 {
+		t.Errorf("POST /subscriptions/list limit = %s, want 60/m", limit)
+	}
+	if limit, _ := limits.forRoute("GET /subscriptions"); limit.Requests != 300 {
+		t.Errorf("default limit = %s, want 300/m", limit)
+	}
+	if limits.PerIP != nil || limits.Longest() != time.Minute {
+		t.Errorf("ParseRateLimits = %+v, want no per-IP limit and a 1m window", limits)
+	}
+
+	for _, routes := range []string{"/subscriptions=10/m", "GET /subscriptions", "GET /subscriptions=often"} {
+		if _, err = ParseRateLimits("", routes, ""); err == nil {
+			t.Errorf("ParseRateLimits(%q) accepted invalid routes", routes)
+		}
+	}
+
+	if _, err = ParseRateLimits("", "", "10/d"); err == nil {
+		t.Errorf("ParseRateLimits accepted an invalid per-IP limit")
+	}
+}
+
+// countingKeys — API-ключи, которых нет; считает обращения к хранилищу
+type countingKeys struct {
+	usecase.APIKeyUseCase
+	lookups atomic.Int32
+}
+
+func (k *countingKeys) AuthenticateAPIKey(context.Context, string) (*auth.Principal, error) {
+	k.lookups.Add(1)
+	return nil, errors_package.Unauthorized("invalid api key")
+}
+
+func TestRateLimitByIPBeforeAuthentication(t *testing.T) {
+	verifier, err := auth.NewVerifier(auth.Config{HS256Secret: "secret"})
+	if err != nil {
+		t.Fatalf("NewVerifier: %v", err)
+	}
+
+	limits, err := ParseRateLimits("", "", "2/m")
+	if err != nil {
+		t.Fatalf("ParseRateLimits: %v", err)
+	}
+
+	logger := slog.New(slog.DiscardHandler)
+	store := memory.NewRateLimitRepository(logger)
+	keys := &countingKeys{}
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	if err = router.SetTrustedProxies(nil); err != nil {
+		t.Fatalf("SetTrustedProxies: %v", err)
+	}
+
+	api := router.Group("/")
+	api.Use(RateLimitByIP(store, limits, logger), Authenticate(verifier, keys), RateLimit(store, limits, logger))
+	api.GET("/subscriptions", func(ctx *gin.Context) { ctx.Status(http.StatusOK) })
+
+	request := func(remoteAddr, authorization string) int {
+		req := httptest.NewRequest(http.MethodGet, "/subscriptions", nil)
+		req.RemoteAddr = remoteAddr
+		req.Header.Set("Authorization", authorization)
+		// без доверенных прокси заголовок не подменяет адрес клиента
+		req.Header.Set("X-Forwarded-For", uuid.NewString())
+
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		return resp.Code
+	}
+
+	var codes []int
+	for range 5 {
+		codes = append(codes, request("203.0.113.7:40000", "ApiKey sk_guess"))
+	}
+
+	want := []int{http.StatusUnauthorized, http.StatusUnauthorized, http.StatusTooManyRequests,
+		http.StatusTooManyRequests, http.StatusTooManyRequests}
+	for i := range want {
+		if codes[i] != want[i] {
+			t.Fatalf("responses = %v, want %v", codes, want)
+		}
+	}
+
+	if keys.lookups.Load() != 2 {
+		t.Errorf("api key lookups = %d, want 2", keys.lookups.Load())
+	}
+
+	if code := request("198.51.100.1:40000", "ApiKey sk_guess"); code != http.StatusUnauthorized {
+		t.Errorf("request from another IP = %d, want 401", code)
+	}
+}
+
+func TestRateLimitHeaders(t *testing.T) {
+	limits := RateLimits{Routes: map[string]domain.RateLimit{"GET /subscriptions": {Requests: 1, Per: time.Minute}}}
+	logger := slog.New(slog.DiscardHandler)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/subscriptions", RateLimit(memory.NewRateLimitRepository(logger), limits, logger),
+		func(ctx *gin.Context) { ctx.Status(http.StatusOK) })
+
+	first, second := httptest.NewRecorder(), httptest.NewRecorder()
+	router.ServeHTTP(first, httptest.NewRequest(http.MethodGet, "/subscriptions", nil))
+	router.ServeHTTP(second, httptest.NewRequest(http.MethodGet, "/subscriptions", nil))
+
+	if first.Code != http.StatusOK || first.Header().Get("RateLimit-Remaining") != "0" ||
+		first.Header().Get("RateLimit-Policy") != "1;w=60" {
+		t.Errorf("first response = %d %v, want 200 with the limit headers", first.Code, first.Header())
+	}
+
+	if second.Code != http.StatusTooManyRequests || second.Header().Get("Retry-After") != "60" {
+		t.Errorf("second response = %d %v, want 429 with Retry-After: 60", second.Code, second.Header())
+	}
+}
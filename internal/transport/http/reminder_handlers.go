@@ -0,0 +1,187 @@
+package transport
+
+import (
+	"github.com/Aiszhio/Task/internal/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+// defaultReminderLimit — сколько напоминаний возвращается, если limit не указан
+const defaultReminderLimit = 50
+
+type ReminderRepository interface {
+	List() gin.HandlerFunc
+	Contact() gin.HandlerFunc
+	SetContact() gin.HandlerFunc
+	DeleteContact() gin.HandlerFunc
+}
+
+type ReminderHandler struct {
+	Repository usecase.ReminderUseCase
+}
+
+func NewReminderHandler(repo usecase.ReminderUseCase) *ReminderHandler {
+	return &ReminderHandler{
+		Repository: repo,
+	}
+}
+
+// ListReminders godoc
+// @Summary     Журнал напоминаний
+// @Description Возвращает напоминания о продлении и окончании подписок, начиная с новых: канал, окно,
+// @Description число попыток и ошибку. Пользователь видит только свои напоминания, администратор
+// @Description и сервис без user_id — напоминания всех пользователей.
+// @Tags        reminders
+// @Security    BearerAuth
+// @Security    ApiKeyAuth
+// @Produce     json
+// @Param       user_id query    string false "ID пользователя"
+// @Param       limit   query    int    false "Число напоминаний, до 100" default(50)
+// @Success     200  {object} ReminderListResponse
+// @Failure     400  {object} ProblemDetails
+// @Failure     401  {object} ProblemDetails
+// @Failure     403  {object} ProblemDetails
+// @Failure     429  {object} ProblemDetails
+// @Failure     500  {object} ProblemDetails
+// @Router      /reminders [get]
+func (handler *ReminderHandler) List() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var req ReminderListRequest
+
+		if err := ctx.ShouldBindQuery(&req); err != nil {
+			respondError(ctx, invalidRequest(err))
+			return
+		}
+
+		if req.Limit == 0 {
+			req.Limit = defaultReminderLimit
+		}
+
+		reminders, err := handler.Repository.ListReminders(ctx.Request.Context(), parseUUID(req.UserID), req.Limit)
+		if err != nil {
+			respondError(ctx, err)
+			return
+		}
+
+		ctx.JSON(200, ReminderListResponse{Items: reminders})
+	}
+}
+
+// GetReminderContact godoc
+// @Summary     Адрес для напоминаний
+// @Description Возвращает адрес, на который пользователю приходят напоминания по почте.
+// @Description Администратору и сервису нужно указать user_id.
+// @Tags        reminders
+// @Security    BearerAuth
+// @Security    ApiKeyAuth
+// @Produce     json
+// @Param       user_id query    string false "ID пользователя"
+// @Success     200  {object} domain.ReminderContact
+// @Failure     400  {object} ProblemDetails
+// @Failure     401  {object} ProblemDetails
+// @Failure     403  {object} ProblemDetails
+// @Failure     404  {object} ProblemDetails
+// @Failure     422  {object} ProblemDetails
+// @Failure     429  {object} ProblemDetails
+// @Failure     500  {object} ProblemDetails
+// @Router      /reminders/contact [get]
+func (handler *ReminderHandler) Contact() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var query ReminderContactQuery
+
+		if err := ctx.ShouldBindQuery(&query); err != nil {
+			respondError(ctx, invalidRequest(err))
+			return
+		}
+
+		contact, err := handler.Repository.GetContact(ctx.Request.Context(), parseUUID(query.UserID))
+		if err != nil {
+			respondError(ctx, err)
+			return
+		}
+
+		ctx.JSON(200, contact)
+	}
+}
+
+// SetReminderContact godoc
+// @Summary     Задать адрес для напоминаний
+// @Description Задаёт адрес, на который приходят напоминания по почте, заменяя прежний. Без адреса
+// @Description напоминания по почте пользователю не отправляются. Администратору и сервису нужно
+// @Description указать user_id.
+// @Tags        reminders
+// @Security    BearerAuth
+// @Security    ApiKeyAuth
+// @Accept      json
+// @Produce     json
+// @Param       user_id query    string                 false "ID пользователя"
+// @Param       body    body     ReminderContactRequest true  "JSON"
+// @Success     200  {object} domain.ReminderContact
+// @Failure     400  {object} ProblemDetails
+// @Failure     401  {object} ProblemDetails
+// @Failure     403  {object} ProblemDetails
+// @Failure     422  {object} ProblemDetails
+// @Failure     429  {object} ProblemDetails
+// @Failure     500  {object} ProblemDetails
+// @Router      /reminders/contact [put]
+func (handler *ReminderHandler) SetContact() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var (
+			query ReminderContactQuery
+			req   ReminderContactRequest
+		)
+
+		if err := ctx.ShouldBindQuery(&query); err != nil {
+			respondError(ctx, invalidRequest(err))
+			return
+		}
+
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			respondError(ctx, invalidRequest(err))
+			return
+		}
+
+		contact, err := handler.Repository.SetContact(ctx.Request.Context(), parseUUID(query.UserID), req.Email)
+		if err != nil {
+			respondError(ctx, err)
+			return
+		}
+
+		ctx.JSON(200, contact)
+	}
+}
+
+// DeleteReminderContact godoc
+// @Summary     Удалить адрес для напоминаний
+// @Description Удаляет адрес; напоминания по почте пользователю больше не отправляются.
+// @Description Администратору и сервису нужно указать user_id.
+// @Tags        reminders
+// @Security    BearerAuth
+// @Security    ApiKeyAuth
+// @Produce     json
+// @Param       user_id query    string false "ID пользователя"
+// @Success     200  {object} SuccessResponse
+// @Failure     400  {object} ProblemDetails
+// @Failure     401  {object} ProblemDetails
+// @Failure     403  {object} ProblemDetails
+// @Failure     404  {object} ProblemDetails
+// @Failure     422  {object} ProblemDetails
+// @Failure     429  {object} ProblemDetails
+// @Failure     500  {object} ProblemDetails
+// @Router      /reminders/contact [delete]
+func (handler *ReminderHandler) DeleteContact() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var query ReminderContactQuery
+
+		if err := ctx.ShouldBindQuery(&query); err != nil {
+			respondError(ctx, invalidRequest(err))
+			return
+		}
+
+		if err := handler.Repository.DeleteContact(ctx.Request.Context(), parseUUID(query.UserID)); err != nil {
+			respondError(ctx, err)
+			return
+		}
+
+		ctx.JSON(200, gin.H{"message": "Reminder contact deleted"})
+	}
+}
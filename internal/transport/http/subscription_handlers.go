@@ -1,8 +1,11 @@
 package transport
 
 import (
+	"io"
 	"log"
 
+	"github.com/Aiszhio/Task/internal/domain"
+	errors_package "github.com/Aiszhio/Task/internal/errors"
 	"github.com/Aiszhio/Task/internal/usecase"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -12,8 +15,15 @@ type SubscriptionRepository interface {
 	Create() gin.HandlerFunc
 	GetByID() gin.HandlerFunc
 	Update() gin.HandlerFunc
+	Patch() gin.HandlerFunc
 	DeleteByID() gin.HandlerFunc
+	Restore() gin.HandlerFunc
+	Trash() gin.HandlerFunc
+	History() gin.HandlerFunc
+	Import() gin.HandlerFunc
+	Export() gin.HandlerFunc
 	ListByPeriod() gin.HandlerFunc
+	List() gin.HandlerFunc
 }
 
 type SubscriptionHandler struct {
@@ -30,44 +40,200 @@ func NewSubscriptionHandler(repo usecase.SubscriptionUseCase) *SubscriptionHandl
 // @Summary     Создать подписку
 // @Description Создаёт новую запись о подписке
 // @Tags        subscriptions
+// @Security    BearerAuth
+// @Security    ApiKeyAuth
 // @Accept      json
 // @Produce     json
+// @Description С заголовком Idempotency-Key повтор запроса возвращает сохранённый ответ первого
+// @Param       Idempotency-Key header string false "Ключ идемпотентности запроса"
 // @Param       body  body  SubscriptionRequest true "JSON"
 // @Success     201   {object} domain.Subscription
-// @Failure     400   {object} ErrorResponse
+// @Header      201   {string} Location "Адрес созданной подписки"
+// @Header      201   {string} ETag "Версия подписки для заголовка If-Match"
+// @Header      201   {string} Idempotent-Replayed "true, если ответ повторён по Idempotency-Key"
+// @Failure     400   {object} ProblemDetails
+// @Failure     401   {object} ProblemDetails
+// @Failure     403   {object} ProblemDetails
+// @Failure     409   {object} ProblemDetails
+// @Failure     413   {object} ProblemDetails
+// @Failure     422   {object} ProblemDetails
+// @Failure     429   {object} ProblemDetails
+// @Failure     500   {object} ProblemDetails
 // @Router      /subscriptions [post]
 func (handler *SubscriptionHandler) Create() gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		var req SubscriptionRequest
 
 		if err := ctx.ShouldBindJSON(&req); err != nil {
-			ctx.JSON(400, gin.H{"error": err.Error()})
+			respondError(ctx, invalidRequest(err))
 			return
 		}
 
 		sub, err := TransportToDomain(&req)
 		if err != nil {
 			log.Printf("error in converting data to domain: %v\n", err)
-			ctx.JSON(400, gin.H{"error": err.Error()})
+			respondError(ctx, invalidRequest(err))
+			return
 		}
 
-		err = handler.Repository.AcceptSubscription(ctx, sub)
+		err = handler.Repository.AcceptSubscription(ctx.Request.Context(), sub)
 		if err != nil {
-			ctx.JSON(400, gin.H{"error": err.Error()})
+			respondError(ctx, err)
 			return
 		}
 
-		ctx.JSON(200, gin.H{"message": "Subscription created"})
+		ctx.Header("Location", ctx.Request.URL.Path+"/"+sub.Id.String())
+		ctx.Header("ETag", etag(sub.Version))
+		ctx.JSON(201, sub)
+	}
+}
+
+// ImportSubscriptions godoc
+// @Summary     Импорт подписок из CSV
+// @Description CSV с заголовком service_name, price, billing_interval, interval_count, user_id, start_date,
+// @Description end_date (даты в формате MM-YYYY). Без billing_interval и interval_count подписка помесячная,
+// @Description без end_date — бессрочная. Файл передаётся телом запроса или полем file формы multipart.
+// @Description В режиме atomic строки сохраняются вместе или не сохраняются вовсе, в режиме best_effort —
+// @Description каждая корректная строка отдельно. dry_run только проверяет строки, в том числе на конфликты
+// @Description с уже сохранёнными подписками. Отчёт перечисляет все отклонённые строки.
+// @Tags        subscriptions
+// @Security    BearerAuth
+// @Security    ApiKeyAuth
+// @Accept      text/csv
+// @Accept      multipart/form-data
+// @Produce     json
+// @Param       mode     query string false "Режим импорта" Enums(atomic, best_effort) default(atomic)
+// @Param       dry_run  query bool   false "Только проверить строки, не сохраняя"
+// @Param       body     body  string true  "CSV"
+// @Success     200  {object} ImportReportResponse
+// @Failure     400  {object} ProblemDetails
+// @Failure     401  {object} ProblemDetails
+// @Failure     403  {object} ProblemDetails
+// @Failure     422  {object} ProblemDetails
+// @Failure     429  {object} ProblemDetails
+// @Failure     500  {object} ProblemDetails
+// @Router      /subscriptions/import [post]
+func (handler *SubscriptionHandler) Import() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var req ImportRequest
+
+		if err := ctx.ShouldBindQuery(&req); err != nil {
+			respondError(ctx, invalidRequest(err))
+			return
+		}
+
+		if req.Mode == "" {
+			req.Mode = string(domain.ImportAtomic)
+		}
+
+		body := io.Reader(ctx.Request.Body)
+		if ctx.ContentType() == "multipart/form-data" {
+			file, err := ctx.FormFile("file")
+			if err != nil {
+				respondError(ctx, invalidParam("file", err))
+				return
+			}
+
+			opened, err := file.Open()
+			if err != nil {
+				respondError(ctx, invalidParam("file", err))
+				return
+			}
+			defer opened.Close()
+
+			body = opened
+		}
+
+		rows, err := parseImport(body)
+		if err != nil {
+			respondError(ctx, invalidParam("file", err))
+			return
+		}
+
+		report, err := handler.Repository.ImportSubscriptions(ctx.Request.Context(), rows,
+			domain.ImportMode(req.Mode), req.DryRun)
+		if err != nil {
+			respondError(ctx, err)
+			return
+		}
+
+		ctx.JSON(200, ImportReportToTransport(report))
+	}
+}
+
+// ExportSubscriptions godoc
+// @Summary     Выгрузка подписок
+// @Description Потоково выгружает все подписки по фильтрам списка в CSV или NDJSON (по объекту на строку).
+// @Description Даты в CSV записаны в формате MM-YYYY, как при импорте.
+// @Tags        subscriptions
+// @Security    BearerAuth
+// @Security    ApiKeyAuth
+// @Produce     text/csv
+// @Produce     application/x-ndjson
+// @Param       format        query string false "Формат выгрузки" Enums(csv, ndjson) default(csv)
+// @Param       user_id       query string false "ID пользователя"
+// @Param       service_name  query string false "Название сервиса"
+// @Param       active_at     query string false "Активна в месяце (MM-YYYY)"
+// @Param       min_price     query int    false "Минимальная цена"
+// @Param       max_price     query int    false "Максимальная цена"
+// @Param       sort          query string false "Поле сортировки, префикс '-' для убывания" example(-price)
+// @Success     200  {string} string "CSV или NDJSON"
+// @Failure     400  {object} ProblemDetails
+// @Failure     401  {object} ProblemDetails
+// @Failure     403  {object} ProblemDetails
+// @Failure     422  {object} ProblemDetails
+// @Failure     429  {object} ProblemDetails
+// @Failure     500  {object} ProblemDetails
+// @Router      /subscriptions/export [get]
+func (handler *SubscriptionHandler) Export() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var req SubscriptionExportRequest
+
+		if err := ctx.ShouldBindQuery(&req); err != nil {
+			respondError(ctx, invalidRequest(err))
+			return
+		}
+
+		filter, err := FilterToDomain(&req.SubscriptionListRequest)
+		if err != nil {
+			respondError(ctx, invalidRequest(err))
+			return
+		}
+
+		writer := newExportWriter(ctx, req.Format)
+
+		err = handler.Repository.ExportSubscriptions(ctx.Request.Context(), filter, writer.write)
+		if err == nil {
+			err = writer.finish()
+		}
+
+		if err != nil && !ctx.Writer.Written() {
+			respondError(ctx, err)
+			return
+		}
+
+		if err != nil {
+			// заголовки уже отправлены: клиент получит обрезанную выгрузку, причина попадёт в лог
+			_ = ctx.Error(err)
+		}
 	}
 }
 
 // GetSubscription godoc
 // @Summary     Получить подписку по ID
 // @Tags        subscriptions
+// @Security    BearerAuth
+// @Security    ApiKeyAuth
 // @Produce     json
 // @Param       id   path  string true "Subscription ID"
 // @Success     200  {object} domain.Subscription
-// @Failure     400  {object} ErrorResponse
+// @Header      200  {string} ETag "Версия подписки для заголовка If-Match"
+// @Failure     400  {object} ProblemDetails
+// @Failure     401  {object} ProblemDetails
+// @Failure     403  {object} ProblemDetails
+// @Failure     404  {object} ProblemDetails
+// @Failure     429  {object} ProblemDetails
+// @Failure     500  {object} ProblemDetails
 // @Router      /subscriptions/{id} [get]
 func (handler *SubscriptionHandler) GetByID() gin.HandlerFunc {
 	return func(ctx *gin.Context) {
@@ -75,62 +241,179 @@ func (handler *SubscriptionHandler) GetByID() gin.HandlerFunc {
 
 		id, err := uuid.Parse(idStr)
 		if err != nil {
-			ctx.JSON(400, gin.H{"error": err.Error()})
+			respondError(ctx, invalidParam("id", err))
 			return
 		}
 
-		subscription, err := handler.Repository.GetSubscription(ctx, id)
+		subscription, err := handler.Repository.GetSubscription(ctx.Request.Context(), id)
 		if err != nil {
-			ctx.JSON(400, gin.H{"error": err.Error()})
+			respondError(ctx, err)
 			return
 		}
 
+		ctx.Header("ETag", etag(subscription.Version))
 		ctx.JSON(200, gin.H{"message": subscription})
 	}
 }
 
 // UpdateSubscription godoc
 // @Summary     Полное обновление подписки
+// @Description Если передан If-Match, подписка обновляется только при совпадении версии
 // @Tags        subscriptions
+// @Security    BearerAuth
+// @Security    ApiKeyAuth
 // @Accept      json
 // @Produce     json
-// @Param       id   path  string true "Subscription ID"
-// @Param       body body  SubscriptionRequest true "JSON"
+// @Param       id       path   string true  "Subscription ID"
+// @Param       If-Match header string false "ETag, полученный из GET /subscriptions/{id}"
+// @Param       body     body   SubscriptionRequest true "JSON"
 // @Success     200  {object} domain.Subscription
-// @Failure     400  {object} ErrorResponse
+// @Header      200  {string} ETag "Новая версия подписки"
+// @Failure     400  {object} ProblemDetails
+// @Failure     401  {object} ProblemDetails
+// @Failure     403  {object} ProblemDetails
+// @Failure     404  {object} ProblemDetails
+// @Failure     412  {object} ProblemDetails
+// @Failure     422  {object} ProblemDetails
+// @Failure     429  {object} ProblemDetails
+// @Failure     500  {object} ProblemDetails
 // @Router      /subscriptions/{id} [put]
 func (handler *SubscriptionHandler) Update() gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		var req SubscriptionRequest
 
+		id, err := uuid.Parse(ctx.Param("id"))
+		if err != nil {
+			respondError(ctx, invalidParam("id", err))
+			return
+		}
+
+		version, err := expectedVersion(ctx.GetHeader("If-Match"))
+		if err != nil {
+			respondError(ctx, invalidParam("If-Match", err))
+			return
+		}
+
 		if err := ctx.ShouldBindJSON(&req); err != nil {
-			ctx.JSON(400, gin.H{"error": err.Error()})
+			respondError(ctx, invalidRequest(err))
 			return
 		}
 
 		sub, err := TransportToDomain(&req)
 		if err != nil {
 			log.Printf("error in converting data to domain: %v\n", err)
-			ctx.JSON(400, gin.H{"error": err.Error()})
+			respondError(ctx, invalidRequest(err))
+			return
 		}
 
-		err = handler.Repository.RefreshSubscription(ctx, sub)
+		sub.Id = id
+		sub.Version = version
+
+		err = handler.Repository.RefreshSubscription(ctx.Request.Context(), sub)
 		if err != nil {
-			ctx.JSON(400, gin.H{"error": err.Error()})
+			respondError(ctx, err)
 			return
 		}
 
+		ctx.Header("ETag", etag(sub.Version))
 		ctx.JSON(200, gin.H{"message": "Subscription was successfully updated"})
 	}
 }
 
+// PatchSubscription godoc
+// @Summary     Частичное обновление подписки
+// @Description Принимает JSON Merge Patch (RFC 7396) или JSON Patch (RFC 6902) поверх полей тела PUT.
+// @Description Дата начала в прошлом допустима, если патч её не меняет; id и user_id изменить нельзя.
+// @Description "end_date": null удаляет дату окончания, и подписка становится бессрочной.
+// @Tags        subscriptions
+// @Security    BearerAuth
+// @Security    ApiKeyAuth
+// @Accept      application/merge-patch+json
+// @Accept      application/json-patch+json
+// @Produce     json
+// @Param       id       path   string true  "Subscription ID"
+// @Param       If-Match header string false "ETag, полученный из GET /subscriptions/{id}"
+// @Param       body     body   object true  "Merge patch или массив операций JSON Patch"
+// @Success     200  {object} domain.Subscription
+// @Header      200  {string} ETag "Новая версия подписки"
+// @Failure     400  {object} ProblemDetails
+// @Failure     401  {object} ProblemDetails
+// @Failure     403  {object} ProblemDetails
+// @Failure     404  {object} ProblemDetails
+// @Failure     409  {object} ProblemDetails
+// @Failure     412  {object} ProblemDetails
+// @Failure     415  {object} ProblemDetails
+// @Failure     422  {object} ProblemDetails
+// @Failure     429  {object} ProblemDetails
+// @Failure     500  {object} ProblemDetails
+// @Router      /subscriptions/{id} [patch]
+func (handler *SubscriptionHandler) Patch() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id, err := uuid.Parse(ctx.Param("id"))
+		if err != nil {
+			respondError(ctx, invalidParam("id", err))
+			return
+		}
+
+		version, err := expectedVersion(ctx.GetHeader("If-Match"))
+		if err != nil {
+			respondError(ctx, invalidParam("If-Match", err))
+			return
+		}
+
+		body, err := io.ReadAll(ctx.Request.Body)
+		if err != nil {
+			respondError(ctx, invalidRequest(err))
+			return
+		}
+
+		current, err := handler.Repository.GetSubscription(ctx.Request.Context(), id)
+		if err != nil {
+			respondError(ctx, err)
+			return
+		}
+
+		if version != 0 && version != current.Version {
+			respondError(ctx, errors_package.PreconditionFailed("subscription %s has version %d, expected %d",
+				id, current.Version, version))
+			return
+		}
+
+		sub, err := applyPatch(current, ctx.ContentType(), body)
+		if err != nil {
+			respondError(ctx, err)
+			return
+		}
+
+		sub.Version = current.Version
+		sub.CreatedAt = current.CreatedAt
+
+		err = handler.Repository.AmendSubscription(ctx.Request.Context(), current, sub)
+		if err != nil {
+			respondError(ctx, err)
+			return
+		}
+
+		ctx.Header("ETag", etag(sub.Version))
+		ctx.JSON(200, gin.H{"message": sub})
+	}
+}
+
 // DeleteSubscription godoc
 // @Summary     Удалить подписку
+// @Description Переносит подписку в корзину, откуда её можно восстановить до окончательной очистки
 // @Tags        subscriptions
+// @Security    BearerAuth
+// @Security    ApiKeyAuth
 // @Produce     json
 // @Param       id   path  string true "Subscription ID"
 // @Success     200  {object} SuccessResponse
-// @Failure     400  {object} ErrorResponse
+// @Failure     400  {object} ProblemDetails
+// @Failure     401  {object} ProblemDetails
+// @Failure     403  {object} ProblemDetails
+// @Failure     404  {object} ProblemDetails
+// @Failure     429  {object} ProblemDetails
+// @Failure     500  {object} ProblemDetails
 // @Router      /subscriptions/{id} [delete]
 func (handler *SubscriptionHandler) DeleteByID() gin.HandlerFunc {
 	return func(ctx *gin.Context) {
@@ -138,13 +421,13 @@ func (handler *SubscriptionHandler) DeleteByID() gin.HandlerFunc {
 
 		id, err := uuid.Parse(idStr)
 		if err != nil {
-			ctx.JSON(400, gin.H{"error": err.Error()})
+			respondError(ctx, invalidParam("id", err))
 			return
 		}
 
-		err = handler.Repository.RemoveSubscription(ctx, id)
+		err = handler.Repository.RemoveSubscription(ctx.Request.Context(), id)
 		if err != nil {
-			ctx.JSON(400, gin.H{"error": err.Error()})
+			respondError(ctx, err)
 			return
 		}
 
@@ -152,37 +435,223 @@ func (handler *SubscriptionHandler) DeleteByID() gin.HandlerFunc {
 	}
 }
 
+// RestoreSubscription godoc
+// @Summary     Восстановить подписку из корзины
+// @Tags        subscriptions
+// @Security    BearerAuth
+// @Security    ApiKeyAuth
+// @Produce     json
+// @Param       id   path  string true "Subscription ID"
+// @Success     200  {object} domain.Subscription
+// @Header      200  {string} ETag "Версия восстановленной подписки"
+// @Failure     400  {object} ProblemDetails
+// @Failure     401  {object} ProblemDetails
+// @Failure     403  {object} ProblemDetails
+// @Failure     404  {object} ProblemDetails
+// @Failure     409  {object} ProblemDetails
+// @Failure     429  {object} ProblemDetails
+// @Failure     500  {object} ProblemDetails
+// @Router      /subscriptions/{id}/restore [post]
+func (handler *SubscriptionHandler) Restore() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id, err := uuid.Parse(ctx.Param("id"))
+		if err != nil {
+			respondError(ctx, invalidParam("id", err))
+			return
+		}
+
+		subscription, err := handler.Repository.RestoreSubscription(ctx.Request.Context(), id)
+		if err != nil {
+			respondError(ctx, err)
+			return
+		}
+
+		ctx.Header("ETag", etag(subscription.Version))
+		ctx.JSON(200, gin.H{"message": subscription})
+	}
+}
+
+// SubscriptionHistory godoc
+// @Summary     Журнал изменений подписки
+// @Description Возвращает изменения подписки от старых к новым: состояние до и после, исполнителя и
+// @Description идентификатор запроса. Журнал доступен и для удалённых подписок.
+// @Tags        subscriptions
+// @Security    BearerAuth
+// @Security    ApiKeyAuth
+// @Produce     json
+// @Param       id   path  string true "Subscription ID"
+// @Success     200  {object} SubscriptionHistoryResponse
+// @Failure     400  {object} ProblemDetails
+// @Failure     401  {object} ProblemDetails
+// @Failure     403  {object} ProblemDetails
+// @Failure     404  {object} ProblemDetails
+// @Failure     429  {object} ProblemDetails
+// @Failure     500  {object} ProblemDetails
+// @Router      /subscriptions/{id}/history [get]
+func (handler *SubscriptionHandler) History() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id, err := uuid.Parse(ctx.Param("id"))
+		if err != nil {
+			respondError(ctx, invalidParam("id", err))
+			return
+		}
+
+		events, err := handler.Repository.GetSubscriptionHistory(ctx.Request.Context(), id)
+		if err != nil {
+			respondError(ctx, err)
+			return
+		}
+
+		ctx.JSON(200, SubscriptionHistoryResponse{Items: events})
+	}
+}
+
+// TrashSubscriptions godoc
+// @Summary     Корзина подписок
+// @Description Возвращает страницу удалённых подписок с теми же фильтрами и сортировкой, что и список
+// @Tags        subscriptions
+// @Security    BearerAuth
+// @Security    ApiKeyAuth
+// @Produce     json
+// @Param       user_id       query string false "ID пользователя"
+// @Param       service_name  query string false "Название сервиса"
+// @Param       active_at     query string false "Активна в месяце (MM-YYYY)"
+// @Param       min_price     query int    false "Минимальная цена"
+// @Param       max_price     query int    false "Максимальная цена"
+// @Param       sort          query string false "Поле сортировки, префикс '-' для убывания" example(-deleted_at)
+// @Param       page          query int    false "Номер страницы" default(1)
+// @Param       page_size     query int    false "Размер страницы (не более 100)" default(20)
+// @Success     200  {object} SubscriptionListResponse
+// @Failure     400  {object} ProblemDetails
+// @Failure     401  {object} ProblemDetails
+// @Failure     403  {object} ProblemDetails
+// @Failure     422  {object} ProblemDetails
+// @Failure     429  {object} ProblemDetails
+// @Failure     500  {object} ProblemDetails
+// @Router      /subscriptions/trash [get]
+func (handler *SubscriptionHandler) Trash() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var req SubscriptionListRequest
+
+		if err := ctx.ShouldBindQuery(&req); err != nil {
+			respondError(ctx, invalidRequest(err))
+			return
+		}
+
+		filter, err := FilterToDomain(&req)
+		if err != nil {
+			respondError(ctx, invalidRequest(err))
+			return
+		}
+
+		filter.Deleted = true
+
+		page, err := handler.Repository.GetSubscriptions(ctx.Request.Context(), filter)
+		if err != nil {
+			respondError(ctx, err)
+			return
+		}
+
+		ctx.JSON(200, SubscriptionListResponse{
+			Items:    page.Items,
+			Total:    page.Total,
+			Page:     req.Page,
+			PageSize: req.PageSize,
+		})
+	}
+}
+
 // ListSubscriptions godoc
 // @Summary     Сумма подписок за период
-// @Description Возвращает суммарную стоимость подписок по фильтру
+// @Description Возвращает суммарную стоимость подписок по фильтру и её разбивку по месяцам периода
 // @Tags        subscriptions
+// @Security    BearerAuth
+// @Security    ApiKeyAuth
 // @Accept      json
 // @Produce     json
 // @Param       body body SubscriptionSummaryRequest true "JSON"
-// @Success     200  {object} SuccessResponse  "total_cost"
-// @Failure     400  {object} ErrorResponse
+// @Success     200  {object} SubscriptionCostResponse
+// @Failure     400  {object} ProblemDetails
+// @Failure     401  {object} ProblemDetails
+// @Failure     403  {object} ProblemDetails
+// @Failure     422  {object} ProblemDetails
+// @Failure     429  {object} ProblemDetails
+// @Failure     500  {object} ProblemDetails
 // @Router      /subscriptions/list [post]
 func (handler *SubscriptionHandler) ListByPeriod() gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		var req SubscriptionSummaryRequest
 
 		if err := ctx.ShouldBindJSON(&req); err != nil {
-			ctx.JSON(400, gin.H{"error": err.Error()})
+			respondError(ctx, invalidRequest(err))
 			return
 		}
 
 		list, err := ListToDomain(&req)
 		if err != nil {
-			ctx.JSON(400, gin.H{"error": err.Error()})
+			respondError(ctx, invalidRequest(err))
+			return
+		}
+
+		cost, err := handler.Repository.GetListSubscriptions(ctx.Request.Context(), list)
+		if err != nil {
+			respondError(ctx, err)
+			return
+		}
+
+		ctx.JSON(200, CostToTransport(cost))
+	}
+}
+
+// ListSubscriptions godoc
+// @Summary     Список подписок
+// @Description Возвращает страницу подписок с фильтрацией и сортировкой
+// @Tags        subscriptions
+// @Security    BearerAuth
+// @Security    ApiKeyAuth
+// @Produce     json
+// @Param       user_id       query string false "ID пользователя"
+// @Param       service_name  query string false "Название сервиса"
+// @Param       active_at     query string false "Активна в месяце (MM-YYYY)"
+// @Param       min_price     query int    false "Минимальная цена"
+// @Param       max_price     query int    false "Максимальная цена"
+// @Param       sort          query string false "Поле сортировки, префикс '-' для убывания" example(-price)
+// @Param       page          query int    false "Номер страницы" default(1)
+// @Param       page_size     query int    false "Размер страницы (не более 100)" default(20)
+// @Success     200  {object} SubscriptionListResponse
+// @Failure     400  {object} ProblemDetails
+// @Failure     401  {object} ProblemDetails
+// @Failure     403  {object} ProblemDetails
+// @Failure     422  {object} ProblemDetails
+// @Failure     429  {object} ProblemDetails
+// @Failure     500  {object} ProblemDetails
+// @Router      /subscriptions [get]
+func (handler *SubscriptionHandler) List() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var req SubscriptionListRequest
+
+		if err := ctx.ShouldBindQuery(&req); err != nil {
+			respondError(ctx, invalidRequest(err))
+			return
+		}
+
+		filter, err := FilterToDomain(&req)
+		if err != nil {
+			respondError(ctx, invalidRequest(err))
 			return
 		}
 
-		subscriptions, err := handler.Repository.GetListSubscriptions(ctx, list)
+		page, err := handler.Repository.GetSubscriptions(ctx.Request.Context(), filter)
 		if err != nil {
-			ctx.JSON(400, gin.H{"error": err.Error()})
+			respondError(ctx, err)
 			return
 		}
 
-		ctx.JSON(200, gin.H{"total_cost": subscriptions})
+		ctx.JSON(200, SubscriptionListResponse{
+			Items:    page.Items,
+			Total:    page.Total,
+			Page:     req.Page,
+			PageSize: req.PageSize,
+		})
 	}
 }
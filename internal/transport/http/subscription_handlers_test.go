@@ -0,0 +1,63 @@
+package transport
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Aiszhio/Task/internal/domain"
+	"github.com/Aiszhio/Task/internal/repository/memory"
+	"github.com/Aiszhio/Task/internal/usecase"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func TestCreateSubscription(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	logger := slog.New(slog.DiscardHandler)
+	subs := memory.NewRepository(logger)
+	handler := NewSubscriptionHandler(usecase.NewSubscriptionUseCase(subs, memory.NewOutboxRepository(subs, logger)))
+
+	router := gin.New()
+	router.POST("/subscriptions", Idempotency(memory.NewIdempotencyRepository(logger), time.Hour, time.Minute, logger),
+		handler.Create())
+
+	body := `{"service_name": "Okko", "price": 300, "user_id": "` + uuid.NewString() + `", "start_date": "` +
+		time.Now().AddDate(0, 1, 0).Format("01-2006") + `"}`
+	post := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/subscriptions", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(idempotencyKeyHeader, idempotencyTestKey)
+
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		return resp
+	}
+
+	first := post()
+	if first.Code != http.StatusCreated {
+		t.Fatalf("Create = %d %s, want 201", first.Code, first.Body)
+	}
+
+	var created domain.Subscription
+	if err := json.Unmarshal(first.Body.Bytes(), &created); err != nil || created.Id == uuid.Nil {
+		t.Fatalf("Create body %s, %v, want the created subscription", first.Body, err)
+	}
+	if location := first.Header().Get("Location"); location != "/subscriptions/"+created.Id.String() {
+		t.Errorf("Location = %q, want the address of %s", location, created.Id)
+	}
+	if tag := first.Header().Get("ETag"); tag != etag(created.Version) {
+		t.Errorf("ETag = %q, want %q", tag, etag(created.Version))
+	}
+
+	// повтор по ключу сообщает клиенту ту же подписку, а не создаёт новую
+	replayed := post()
+	if replayed.Code != http.StatusCreated || replayed.Body.String() != first.Body.String() {
+		t.Errorf("replay = %d %s, want %d %s", replayed.Code, replayed.Body, first.Code, first.Body)
+	}
+}
@@ -1,11 +1,29 @@
 package transport
 
-// ErrorResponse описывает формат ошибки в API
-type ErrorResponse struct {
-	Error string `json:"error" example:"something went wrong"`
+// ProblemDetails описывает формат ошибки в API по RFC 7807 (application/problem+json)
+type ProblemDetails struct {
+	Type     string       `json:"type"               example:"about:blank"`
+	Title    string       `json:"title"              example:"Unprocessable Entity"`
+	Status   int          `json:"status"             example:"422"`
+	Detail   string       `json:"detail,omitempty"   example:"subscription name cannot be empty"`
+	Instance string       `json:"instance,omitempty" example:"/subscriptions"`
+	Errors   []FieldError `json:"errors,omitempty"`
+}
+
+// FieldError указывает на поле запроса, не прошедшее проверку, и нарушенное правило
+type FieldError struct {
+	Field   string `json:"field"   example:"service_name"`
+	Code    string `json:"code"    example:"required"`
+	Message string `json:"message" example:"subscription name cannot be empty"`
 }
 
 // SuccessResponse обёртка для всех успешных ответов с полем "message"
 type SuccessResponse struct {
 	Message interface{} `json:"message"`
 }
+
+// HealthResponse — результат пробы: общий статус и, для готовности, итог каждой проверки
+type HealthResponse struct {
+	Status string            `json:"status"           example:"ready"`
+	Checks map[string]string `json:"checks,omitempty"`
+}
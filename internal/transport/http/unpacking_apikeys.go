@@ -0,0 +1,18 @@
+package transport
+
+import "github.com/Aiszhio/Task/internal/domain"
+
+type APIKeyRequest struct {
+	Name   string   `json:"name"   binding:"required" example:"billing"`
+	Scopes []string `json:"scopes" binding:"required,min=1" example:"subscriptions:read,reports:read"`
+}
+
+type APIKeyListResponse struct {
+	Items []domain.APIKey `json:"items"`
+}
+
+// IssuedAPIKeyResponse — новый ключ; поле key показывается один раз и больше не может быть получено
+type IssuedAPIKeyResponse struct {
+	domain.APIKey
+	Key string `json:"key" example:"sk_Zm9vYmFyYmF6cXV4..."`
+}
@@ -0,0 +1,20 @@
+package transport
+
+import "github.com/Aiszhio/Task/internal/domain"
+
+type ReminderListRequest struct {
+	UserID string `form:"user_id" binding:"omitempty,uuid"`
+	Limit  int    `form:"limit"   binding:"omitempty,min=1,max=100" example:"50"`
+}
+
+type ReminderListResponse struct {
+	Items []domain.Reminder `json:"items"`
+}
+
+type ReminderContactQuery struct {
+	UserID string `form:"user_id" binding:"omitempty,uuid"`
+}
+
+type ReminderContactRequest struct {
+	Email string `json:"email" binding:"required" example:"alice@example.com"`
+}
@@ -1,48 +1,94 @@
 package transport
 
 import (
-	"encoding/json"
-	"fmt"
 	"time"
 
-	"github.com/google/uuid"
+	"github.com/Aiszhio/Task/internal/domain"
 )
 
-type MonthYear time.Time
+const monthYearLayout = "01-2006"
+
+// MonthYear — месяц в формате MM-YYYY
+type MonthYear string
 
 type SubscriptionRequest struct {
-	Id          uuid.UUID  `json:"id"`
-	ServiceName string     `json:"service_name" binding:"required"`
-	Price       int        `json:"price"        binding:"required,min=0"`
-	UserID      uuid.UUID  `json:"user_id"      binding:"required"`
-	StartDate   MonthYear  `json:"start_date"   binding:"required"`
-	EndDate     *MonthYear `json:"end_date,omitempty"`
+	Id              string     `json:"id"               binding:"omitempty,uuid"`
+	ServiceName     string     `json:"service_name"     binding:"required"`
+	Price           int        `json:"price"            binding:"required,min=0"`
+	BillingInterval string     `json:"billing_interval" binding:"omitempty,oneof=week month quarter year" example:"month"`
+	IntervalCount   int        `json:"interval_count"   binding:"omitempty,min=1" example:"1"`
+	UserID          string     `json:"user_id"          binding:"omitempty,uuid"`
+	StartDate       MonthYear  `json:"start_date"       binding:"required,monthyear" example:"07-2025"`
+	EndDate         *MonthYear `json:"end_date,omitempty" binding:"omitempty,monthyear" example:"12-2025"`
 }
 
 type SubscriptionSummaryRequest struct {
-	UserID      uuid.UUID `json:"user_id"       binding:"required"`
+	UserID      string    `json:"user_id"       binding:"omitempty,uuid"`
 	ServiceName string    `json:"service_name"  binding:"required"`
-	StartDate   MonthYear `json:"start_date"    binding:"required"`
-	EndDate     MonthYear `json:"end_date"      binding:"required"`
+	StartDate   MonthYear `json:"start_date"    binding:"required,monthyear" example:"07-2025"`
+	EndDate     MonthYear `json:"end_date"      binding:"required,monthyear" example:"12-2025"`
+}
+
+type SubscriptionListRequest struct {
+	UserID      string    `form:"user_id"    binding:"omitempty,uuid"`
+	ServiceName string    `form:"service_name"`
+	ActiveAt    MonthYear `form:"active_at"  binding:"omitempty,monthyear"`
+	MinPrice    *int      `form:"min_price"  binding:"omitempty,min=0"`
+	MaxPrice    *int      `form:"max_price"  binding:"omitempty,min=0"`
+	Sort        string    `form:"sort"`
+	Page        int       `form:"page"       binding:"omitempty,min=1"`
+	PageSize    int       `form:"page_size"  binding:"omitempty,min=1"`
+}
+
+// SubscriptionExportRequest — фильтры списка и формат выгрузки; параметры страницы не учитываются
+type SubscriptionExportRequest struct {
+	SubscriptionListRequest
+	Format string `form:"format" binding:"omitempty,oneof=csv ndjson" example:"csv"`
+}
+
+type ImportRequest struct {
+	Mode   string `form:"mode"    binding:"omitempty,oneof=atomic best_effort" example:"atomic"`
+	DryRun bool   `form:"dry_run"`
 }
 
-func (m *MonthYear) UnmarshalJSON(data []byte) error {
-	str := ""
-	if err := json.Unmarshal(data, &str); err != nil {
-		return err
-	}
+type SubscriptionListResponse struct {
+	Items    []domain.Subscription `json:"items"`
+	Total    uint64                `json:"total"`
+	Page     int                   `json:"page"`
+	PageSize int                   `json:"page_size"`
+}
+
+type SubscriptionHistoryResponse struct {
+	Items []domain.SubscriptionEvent `json:"items"`
+}
 
-	formattedTime, err := time.Parse("01-2006", str)
-	if err != nil {
-		return fmt.Errorf("invalid date format %s: %s", str, err)
-	}
+type ImportRowResponse struct {
+	Line   int          `json:"line"             example:"2"`
+	Id     string       `json:"id,omitempty"`
+	Detail string       `json:"detail,omitempty"`
+	Errors []FieldError `json:"errors,omitempty"`
+}
 
-	*m = MonthYear(formattedTime)
+type ImportReportResponse struct {
+	Mode      string              `json:"mode"      example:"atomic"`
+	DryRun    bool                `json:"dry_run"`
+	Committed bool                `json:"committed"`
+	Accepted  []ImportRowResponse `json:"accepted"`
+	Rejected  []ImportRowResponse `json:"rejected"`
+}
+
+type MonthCostResponse struct {
+	Month string `json:"month" example:"07-2025"`
+	Cost  uint64 `json:"cost"  example:"400"`
+}
 
-	return nil
+type SubscriptionCostResponse struct {
+	TotalCost uint64              `json:"total_cost" example:"2400"`
+	Months    []MonthCostResponse `json:"months"`
 }
 
-func (m *MonthYear) MarshalJSON() ([]byte, error) {
-	t := time.Time(*m)
-	return []byte(t.Format("07-2025")), nil
+// Time возвращает первое число месяца; значение должно пройти проверку monthyear
+func (m MonthYear) Time() time.Time {
+	t, _ := time.Parse(monthYearLayout, string(m))
+	return t
 }
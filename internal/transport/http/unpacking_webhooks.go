@@ -0,0 +1,29 @@
+package transport
+
+import "github.com/Aiszhio/Task/internal/domain"
+
+type WebhookRequest struct {
+	URL        string                    `json:"url"         binding:"required" example:"https://billing.local/hooks"`
+	EventTypes []domain.WebhookEventType `json:"event_types" binding:"required,min=1" example:"subscription.created"`
+	// Secret подписывает запросы; если не указан, генерируется
+	Secret string `json:"secret" example:"whsec_change-me-please"`
+}
+
+type WebhookListResponse struct {
+	Items []domain.Webhook `json:"items"`
+}
+
+// RegisteredWebhookResponse — новый вебхук; поле secret показывается один раз и больше не может быть получено
+type RegisteredWebhookResponse struct {
+	domain.Webhook
+	Secret string `json:"secret" example:"whsec_Zm9vYmFyYmF6cXV4..."`
+}
+
+type DeliveryListRequest struct {
+	Status string `form:"status" binding:"omitempty,oneof=pending succeeded failed" example:"failed"`
+	Limit  int    `form:"limit"  binding:"omitempty,min=1,max=100" example:"50"`
+}
+
+type DeliveryListResponse struct {
+	Items []domain.WebhookDelivery `json:"items"`
+}
@@ -0,0 +1,36 @@
+package transport
+
+import (
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+func init() {
+	validate, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return
+	}
+
+	validate.RegisterTagNameFunc(fieldName)
+
+	_ = validate.RegisterValidation("monthyear", func(fl validator.FieldLevel) bool {
+		_, err := time.Parse(monthYearLayout, fl.Field().String())
+		return err == nil
+	})
+}
+
+// fieldName возвращает имя поля так, как его видит клиент: из тега json или form
+func fieldName(field reflect.StructField) string {
+	for _, tag := range []string{"json", "form"} {
+		name, _, _ := strings.Cut(field.Tag.Get(tag), ",")
+		if name != "" && name != "-" {
+			return name
+		}
+	}
+
+	return field.Name
+}
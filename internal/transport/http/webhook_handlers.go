@@ -0,0 +1,169 @@
+package transport
+
+import (
+	"github.com/Aiszhio/Task/internal/domain"
+	"github.com/Aiszhio/Task/internal/usecase"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// defaultDeliveryLimit — сколько доставок возвращается, если limit не указан
+const defaultDeliveryLimit = 50
+
+type WebhookRepository interface {
+	Create() gin.HandlerFunc
+	List() gin.HandlerFunc
+	Delete() gin.HandlerFunc
+	Deliveries() gin.HandlerFunc
+}
+
+type WebhookHandler struct {
+	Repository usecase.WebhookUseCase
+}
+
+func NewWebhookHandler(repo usecase.WebhookUseCase) *WebhookHandler {
+	return &WebhookHandler{
+		Repository: repo,
+	}
+}
+
+// CreateWebhook godoc
+// @Summary     Зарегистрировать вебхук
+// @Description Подписывает адрес на события subscription.created, subscription.updated, subscription.deleted,
+// @Description subscription.ending_soon, subscription.reminder. Каждый запрос подписан HMAC-SHA256 секретом
+// @Description вебхука; секрет возвращается только в этом ответе. Доступно только администратору.
+// @Tags        webhooks
+// @Security    BearerAuth
+// @Accept      json
+// @Produce     json
+// @Param       body body     WebhookRequest true "JSON"
+// @Success     201  {object} RegisteredWebhookResponse
+// @Failure     400  {object} ProblemDetails
+// @Failure     401  {object} ProblemDetails
+// @Failure     403  {object} ProblemDetails
+// @Failure     422  {object} ProblemDetails
+// @Failure     429  {object} ProblemDetails
+// @Failure     500  {object} ProblemDetails
+// @Router      /webhooks [post]
+func (handler *WebhookHandler) Create() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var req WebhookRequest
+
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			respondError(ctx, invalidRequest(err))
+			return
+		}
+
+		webhook, secret, err := handler.Repository.CreateWebhook(ctx.Request.Context(), req.URL, req.EventTypes,
+			req.Secret)
+		if err != nil {
+			respondError(ctx, err)
+			return
+		}
+
+		ctx.JSON(201, RegisteredWebhookResponse{Webhook: *webhook, Secret: secret})
+	}
+}
+
+// ListWebhooks godoc
+// @Summary     Список вебхуков
+// @Description Возвращает зарегистрированные вебхуки без секретов. Доступно только администратору.
+// @Tags        webhooks
+// @Security    BearerAuth
+// @Produce     json
+// @Success     200  {object} WebhookListResponse
+// @Failure     401  {object} ProblemDetails
+// @Failure     403  {object} ProblemDetails
+// @Failure     429  {object} ProblemDetails
+// @Failure     500  {object} ProblemDetails
+// @Router      /webhooks [get]
+func (handler *WebhookHandler) List() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		webhooks, err := handler.Repository.ListWebhooks(ctx.Request.Context())
+		if err != nil {
+			respondError(ctx, err)
+			return
+		}
+
+		ctx.JSON(200, WebhookListResponse{Items: webhooks})
+	}
+}
+
+// DeleteWebhook godoc
+// @Summary     Удалить вебхук
+// @Description Удаляет вебхук вместе с журналом доставок; неотправленные события отменяются.
+// @Tags        webhooks
+// @Security    BearerAuth
+// @Produce     json
+// @Param       id   path     string true "Webhook ID"
+// @Success     200  {object} SuccessResponse
+// @Failure     400  {object} ProblemDetails
+// @Failure     401  {object} ProblemDetails
+// @Failure     403  {object} ProblemDetails
+// @Failure     404  {object} ProblemDetails
+// @Failure     429  {object} ProblemDetails
+// @Failure     500  {object} ProblemDetails
+// @Router      /webhooks/{id} [delete]
+func (handler *WebhookHandler) Delete() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id, err := uuid.Parse(ctx.Param("id"))
+		if err != nil {
+			respondError(ctx, invalidParam("id", err))
+			return
+		}
+
+		if err = handler.Repository.DeleteWebhook(ctx.Request.Context(), id); err != nil {
+			respondError(ctx, err)
+			return
+		}
+
+		ctx.JSON(200, gin.H{"message": "Webhook deleted"})
+	}
+}
+
+// ListDeliveries godoc
+// @Summary     Журнал доставок вебхука
+// @Description Возвращает доставки событий, начиная с новых: число попыток, код последнего ответа,
+// @Description ошибку и время следующей попытки. Доставка в статусе failed исчерпала попытки.
+// @Tags        webhooks
+// @Security    BearerAuth
+// @Produce     json
+// @Param       id     path     string true  "Webhook ID"
+// @Param       status query    string false "Статус доставки" Enums(pending, succeeded, failed)
+// @Param       limit  query    int    false "Число доставок, до 100" default(50)
+// @Success     200  {object} DeliveryListResponse
+// @Failure     400  {object} ProblemDetails
+// @Failure     401  {object} ProblemDetails
+// @Failure     403  {object} ProblemDetails
+// @Failure     404  {object} ProblemDetails
+// @Failure     429  {object} ProblemDetails
+// @Failure     500  {object} ProblemDetails
+// @Router      /webhooks/{id}/deliveries [get]
+func (handler *WebhookHandler) Deliveries() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id, err := uuid.Parse(ctx.Param("id"))
+		if err != nil {
+			respondError(ctx, invalidParam("id", err))
+			return
+		}
+
+		var req DeliveryListRequest
+		if err = ctx.ShouldBindQuery(&req); err != nil {
+			respondError(ctx, invalidRequest(err))
+			return
+		}
+
+		if req.Limit == 0 {
+			req.Limit = defaultDeliveryLimit
+		}
+
+		deliveries, err := handler.Repository.ListDeliveries(ctx.Request.Context(), id,
+			domain.DeliveryStatus(req.Status), req.Limit)
+		if err != nil {
+			respondError(ctx, err)
+			return
+		}
+
+		ctx.JSON(200, DeliveryListResponse{Items: deliveries})
+	}
+}
@@ -0,0 +1,61 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/Aiszhio/Task/internal/auth"
+	"github.com/Aiszhio/Task/internal/domain"
+	errors_package "github.com/Aiszhio/Task/internal/errors"
+	"github.com/google/uuid"
+)
+
+// Проверки доступа опираются на субъекта запроса из context. Вызовы без субъекта
+// (фоновые задачи, сервис с отключённой аутентификацией) считаются доверенными.
+
+// authorize проверяет, что субъект запроса может работать с подписками пользователя userID
+func authorize(ctx context.Context, userID uuid.UUID) error {
+	principal, ok := auth.FromContext(ctx)
+	if !ok || principal.CanActFor(userID) {
+		return nil
+	}
+
+	return errors_package.Forbidden("cannot act on behalf of user %s", userID)
+}
+
+// scopeuser подставляет пользователя из токена, если userID не указан, и проверяет доступ
+// к указанному; администратору и сервису пользователь не подставляется
+func scopeuser(ctx context.Context, userID *uuid.UUID) error {
+	principal, ok := auth.FromContext(ctx)
+	if !ok {
+		return nil
+	}
+
+	if *userID == uuid.Nil && !principal.AllUsers() {
+		*userID = principal.UserID
+	}
+
+	if *userID == uuid.Nil {
+		return nil
+	}
+
+	return authorize(ctx, *userID)
+}
+
+// visible скрывает чужую подписку так, будто её не существует
+func visible(ctx context.Context, sub *domain.Subscription) error {
+	if authorize(ctx, sub.UserId) != nil {
+		return errors_package.NotFound("subscription %s not found", sub.Id)
+	}
+
+	return nil
+}
+
+// internal разрешает операцию только доверенным вызовам и администраторам
+func internal(ctx context.Context) error {
+	principal, ok := auth.FromContext(ctx)
+	if !ok || principal.Admin() {
+		return nil
+	}
+
+	return errors_package.Forbidden("operation requires the %s scope", auth.ScopeAdmin)
+}
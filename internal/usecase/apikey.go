@@ -0,0 +1,161 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Aiszhio/Task/internal/auth"
+	"github.com/Aiszhio/Task/internal/domain"
+	errors_package "github.com/Aiszhio/Task/internal/errors"
+	"github.com/Aiszhio/Task/internal/repository/postgres"
+	"github.com/google/uuid"
+)
+
+type APIKeyUseCase interface {
+	// CreateAPIKey выпускает ключ; сам ключ возвращается только здесь и в RotateAPIKey
+	CreateAPIKey(ctx context.Context, name string, scopes []string) (*domain.APIKey, string, error)
+	ListAPIKeys(ctx context.Context) ([]domain.APIKey, error)
+	RevokeAPIKey(ctx context.Context, id uuid.UUID) (*domain.APIKey, error)
+	RotateAPIKey(ctx context.Context, id uuid.UUID) (*domain.APIKey, string, error)
+	// AuthenticateAPIKey находит действующий ключ и возвращает сервис, которому он выдан
+	AuthenticateAPIKey(ctx context.Context, key string) (*auth.Principal, error)
+}
+
+// lastUsedPrecision — как часто обновляется время последнего использования ключа
+const lastUsedPrecision = time.Minute
+
+type APIKeyUseCaseImpl struct {
+	db postgres.APIKeyRepository
+}
+
+func NewAPIKeyUseCase(db postgres.APIKeyRepository) *APIKeyUseCaseImpl {
+	return &APIKeyUseCaseImpl{
+		db: db,
+	}
+}
+
+func validatescopes(scopes []string) error {
+	if len(scopes) == 0 {
+		return errors_package.EmptyKeyScopes
+	}
+
+	for _, scope := range scopes {
+		if !auth.ValidAPIKeyScope(scope) {
+			return errors_package.InvalidKeyScope
+		}
+	}
+
+	return nil
+}
+
+// issue генерирует секрет нового ключа
+func issue(name string, scopes []string) (*domain.APIKey, string, error) {
+	secret, hash, prefix, err := auth.NewAPIKey()
+	if err != nil {
+		return nil, "", errors_package.Internal(err)
+	}
+
+	return &domain.APIKey{
+		Id:     uuid.New(),
+		Name:   name,
+		Prefix: prefix,
+		Hash:   hash,
+		Scopes: scopes,
+	}, secret, nil
+}
+
+func (uc *APIKeyUseCaseImpl) CreateAPIKey(ctx context.Context, name string,
+	scopes []string) (*domain.APIKey, string, error) {
+	if err := internal(ctx); err != nil {
+		return nil, "", err
+	}
+
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, "", errors_package.EmptyKeyName
+	}
+
+	scopes = slices.Compact(slices.Sorted(slices.Values(scopes)))
+	if err := validatescopes(scopes); err != nil {
+		return nil, "", err
+	}
+
+	key, secret, err := issue(name, scopes)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if err = uc.db.CreateAPIKey(ctx, key); err != nil {
+		return nil, "", err
+	}
+
+	return key, secret, nil
+}
+
+func (uc *APIKeyUseCaseImpl) ListAPIKeys(ctx context.Context) ([]domain.APIKey, error) {
+	if err := internal(ctx); err != nil {
+		return nil, err
+	}
+
+	return uc.db.ListAPIKeys(ctx)
+}
+
+func (uc *APIKeyUseCaseImpl) RevokeAPIKey(ctx context.Context, id uuid.UUID) (*domain.APIKey, error) {
+	if err := internal(ctx); err != nil {
+		return nil, err
+	}
+
+	if id == uuid.Nil {
+		return nil, errors_package.ErrEmptyId
+	}
+
+	return uc.db.RevokeAPIKey(ctx, id)
+}
+
+// RotateAPIKey заменяет действующий ключ новым с теми же именем и правами; старый ключ отзывается сразу
+func (uc *APIKeyUseCaseImpl) RotateAPIKey(ctx context.Context, id uuid.UUID) (*domain.APIKey, string, error) {
+	if err := internal(ctx); err != nil {
+		return nil, "", err
+	}
+
+	if id == uuid.Nil {
+		return nil, "", errors_package.ErrEmptyId
+	}
+
+	replacement, secret, err := issue("", nil)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if err = uc.db.RotateAPIKey(ctx, id, replacement); err != nil {
+		return nil, "", err
+	}
+
+	return replacement, secret, nil
+}
+
+func (uc *APIKeyUseCaseImpl) AuthenticateAPIKey(ctx context.Context, key string) (*auth.Principal, error) {
+	stored, err := uc.db.FindAPIKey(ctx, auth.HashAPIKey(key))
+	if errors.Is(err, errors_package.ErrNotFound) {
+		return nil, errors_package.Unauthorized("invalid api key")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if !stored.Active() {
+		return nil, errors_package.Unauthorized("api key %s has been revoked", stored.Prefix)
+	}
+
+	// сбой записи уже залогирован хранилищем и не должен отклонять запрос
+	_ = uc.db.TouchAPIKey(ctx, stored.Id, time.Now().UTC(), lastUsedPrecision)
+
+	return &auth.Principal{
+		Subject: "apikey:" + stored.Id.String(),
+		Scopes:  stored.Scopes,
+		Service: true,
+	}, nil
+}
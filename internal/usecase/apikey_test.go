@@ -0,0 +1,94 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"slices"
+	"testing"
+
+	"github.com/Aiszhio/Task/internal/auth"
+	errors_package "github.com/Aiszhio/Task/internal/errors"
+	"github.com/Aiszhio/Task/internal/repository/memory"
+	"github.com/Aiszhio/Task/internal/usecase"
+	"github.com/google/uuid"
+)
+
+func newLogger() *slog.Logger {
+	return slog.New(slog.DiscardHandler)
+}
+
+func TestAuthenticateAPIKey(t *testing.T) {
+	ctx := context.Background()
+	uc := usecase.NewAPIKeyUseCase(memory.NewAPIKeyRepository(newLogger()))
+
+	key, secret, err := uc.CreateAPIKey(ctx, "billing", []string{auth.ScopeReportsRead, auth.ScopeSubscriptionsRead})
+	if err != nil {
+		t.Fatalf("CreateAPIKey: %v", err)
+	}
+	if key.Hash != auth.HashAPIKey(secret) || key.Hash == secret {
+		t.Errorf("CreateAPIKey stored hash %s, want the hash of the key, not the key", key.Hash)
+	}
+
+	principal, err := uc.AuthenticateAPIKey(ctx, secret)
+	if err != nil {
+		t.Fatalf("AuthenticateAPIKey: %v", err)
+	}
+	if !principal.Service || !principal.Allows(auth.ScopeReportsRead) || principal.Allows(auth.ScopeSubscriptionsWrite) {
+		t.Errorf("AuthenticateAPIKey = %+v, want a service with the scopes of the key", principal)
+	}
+	if !principal.CanActFor(uuid.New()) || principal.Admin() {
+		t.Errorf("AuthenticateAPIKey = %+v, want a service of all users without admin rights", principal)
+	}
+
+	if _, err = uc.AuthenticateAPIKey(ctx, secret+"x"); !errors.Is(err, errors_package.ErrUnauthorized) {
+		t.Errorf("AuthenticateAPIKey of an unknown key = %v, want ErrUnauthorized", err)
+	}
+
+	rotated, rotatedSecret, err := uc.RotateAPIKey(ctx, key.Id)
+	if err != nil {
+		t.Fatalf("RotateAPIKey: %v", err)
+	}
+	if !slices.Equal(rotated.Scopes, key.Scopes) {
+		t.Errorf("RotateAPIKey scopes = %v, want %v", rotated.Scopes, key.Scopes)
+	}
+
+	if _, err = uc.AuthenticateAPIKey(ctx, secret); !errors.Is(err, errors_package.ErrUnauthorized) {
+		t.Errorf("AuthenticateAPIKey of a rotated key = %v, want ErrUnauthorized", err)
+	}
+	if _, err = uc.AuthenticateAPIKey(ctx, rotatedSecret); err != nil {
+		t.Errorf("AuthenticateAPIKey of the replacement: %v", err)
+	}
+
+	if _, err = uc.RevokeAPIKey(ctx, rotated.Id); err != nil {
+		t.Fatalf("RevokeAPIKey: %v", err)
+	}
+	if _, err = uc.AuthenticateAPIKey(ctx, rotatedSecret); !errors.Is(err, errors_package.ErrUnauthorized) {
+		t.Errorf("AuthenticateAPIKey of a revoked key = %v, want ErrUnauthorized", err)
+	}
+}
+
+func TestCreateAPIKeyScopes(t *testing.T) {
+	uc := usecase.NewAPIKeyUseCase(memory.NewAPIKeyRepository(newLogger()))
+
+	tests := []struct {
+		name   string
+		ctx    context.Context
+		scopes []string
+		want   error
+	}{
+		{"no scopes", context.Background(), nil, errors_package.EmptyKeyScopes},
+		{"admin scope", context.Background(), []string{auth.ScopeAdmin}, errors_package.InvalidKeyScope},
+		{"unknown scope", context.Background(), []string{"subscriptions:*"}, errors_package.InvalidKeyScope},
+		{"not an admin", auth.WithPrincipal(context.Background(), &auth.Principal{UserID: uuid.New()}),
+			[]string{auth.ScopeReportsRead}, errors_package.ErrForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := uc.CreateAPIKey(tt.ctx, "billing", tt.scopes); !errors.Is(err, tt.want) {
+				t.Errorf("CreateAPIKey = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,212 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/Aiszhio/Task/internal/domain"
+	errors_package "github.com/Aiszhio/Task/internal/errors"
+	"github.com/Aiszhio/Task/internal/repository/postgres"
+	"github.com/google/uuid"
+)
+
+// ReminderChannel доставляет напоминание пользователю; ошибка — неудачная попытка
+type ReminderChannel interface {
+	Name() string
+	Send(ctx context.Context, reminder *domain.Reminder) error
+}
+
+type ReminderUseCase interface {
+	// SetContact задаёт адрес для напоминаний по почте; без userId — адрес пользователя из токена
+	SetContact(ctx context.Context, userId uuid.UUID, email string) (*domain.ReminderContact, error)
+	GetContact(ctx context.Context, userId uuid.UUID) (*domain.ReminderContact, error)
+	DeleteContact(ctx context.Context, userId uuid.UUID) error
+	// ListReminders возвращает напоминания пользователя, начиная с новых; администратору и сервису
+	// без userId — напоминания всех пользователей
+	ListReminders(ctx context.Context, userId uuid.UUID, limit int) ([]domain.Reminder, error)
+	// SendReminders отправляет напоминания, которые пора отправить в момент now, и возвращает
+	// число отправленных
+	SendReminders(ctx context.Context, now time.Time) (int, error)
+}
+
+const maxReminderPage = 100
+
+type ReminderUseCaseImpl struct {
+	db       postgres.ReminderRepository
+	subs     postgres.SubscriptionRepository
+	channels []ReminderChannel
+	windows  []domain.ReminderWindow
+	retry    domain.RetryPolicy
+	lease    time.Duration
+}
+
+// NewReminderUseCase создаёт напоминания, которые уходят по всем channels за каждое из windows
+// до продления или окончания подписки. Неудачная отправка повторяется по retry; lease должна
+// превышать время одной отправки, иначе напоминание может параллельно взять другая реплика
+func NewReminderUseCase(db postgres.ReminderRepository, subs postgres.SubscriptionRepository,
+	channels []ReminderChannel, windows []domain.ReminderWindow, retry domain.RetryPolicy,
+	lease time.Duration) *ReminderUseCaseImpl {
+	return &ReminderUseCaseImpl{
+		db:       db,
+		subs:     subs,
+		channels: channels,
+		windows:  windows,
+		retry:    retry,
+		lease:    lease,
+	}
+}
+
+// contactuser определяет, чей адрес читается или меняется: адрес есть только у конкретного пользователя
+func contactuser(ctx context.Context, userId uuid.UUID) (uuid.UUID, error) {
+	if err := scopeuser(ctx, &userId); err != nil {
+		return uuid.Nil, err
+	}
+
+	if userId == uuid.Nil {
+		return uuid.Nil, errors_package.EmptyUser
+	}
+
+	return userId, nil
+}
+
+func (uc *ReminderUseCaseImpl) SetContact(ctx context.Context, userId uuid.UUID,
+	email string) (*domain.ReminderContact, error) {
+	userId, err := contactuser(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	email = strings.TrimSpace(email)
+	if address, err := mail.ParseAddress(email); err != nil || address.Address != email {
+		return nil, errors_package.InvalidReminderEmail
+	}
+
+	contact := &domain.ReminderContact{UserId: userId, Email: email}
+	if err = uc.db.SaveContact(ctx, contact); err != nil {
+		return nil, err
+	}
+
+	return contact, nil
+}
+
+func (uc *ReminderUseCaseImpl) GetContact(ctx context.Context, userId uuid.UUID) (*domain.ReminderContact, error) {
+	userId, err := contactuser(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	return uc.db.ReadContact(ctx, userId)
+}
+
+func (uc *ReminderUseCaseImpl) DeleteContact(ctx context.Context, userId uuid.UUID) error {
+	userId, err := contactuser(ctx, userId)
+	if err != nil {
+		return err
+	}
+
+	return uc.db.DeleteContact(ctx, userId)
+}
+
+func (uc *ReminderUseCaseImpl) ListReminders(ctx context.Context, userId uuid.UUID,
+	limit int) ([]domain.Reminder, error) {
+	if err := scopeuser(ctx, &userId); err != nil {
+		return nil, err
+	}
+
+	if limit <= 0 || limit > maxReminderPage {
+		return nil, errors_package.InvalidPageSize
+	}
+
+	return uc.db.ListReminders(ctx, userId, limit)
+}
+
+// SendReminders находит среди действующих в этом месяце подписок те, что продлятся или закончатся
+// в пределах окон, и отправляет напоминания по каждому каналу. Напоминание сначала заводится
+// в хранилище, поэтому уже отправленное не уходит повторно ни при следующем запуске, ни с другой
+// реплики. Письма получают только пользователи, указавшие адрес; сбой записи итога уже залогирован
+// хранилищем, и напоминание повторится после аренды
+func (uc *ReminderUseCaseImpl) SendReminders(ctx context.Context, now time.Time) (int, error) {
+	if err := internal(ctx); err != nil {
+		return 0, err
+	}
+
+	month := domain.MonthStart(now)
+	filter := &domain.SubscriptionFilter{ActiveAt: &month}
+
+	// подписки собираются заранее, чтобы медленная отправка не держала открытым курсор
+	var due []domain.Reminder
+	err := uc.subs.StreamSubscriptions(ctx, filter, func(sub *domain.Subscription) error {
+		for _, notice := range sub.DueReminders(now, uc.windows) {
+			for _, channel := range uc.channels {
+				due = append(due, *domain.NewReminder(*sub, notice, channel.Name()))
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	emails := make(map[uuid.UUID]string)
+	channels := make(map[string]ReminderChannel, len(uc.channels))
+	for _, channel := range uc.channels {
+		channels[channel.Name()] = channel
+	}
+
+	var sent int
+	for i := range due {
+		reminder := &due[i]
+
+		if reminder.Channel == domain.ReminderChannelEmail {
+			email, err := uc.email(ctx, emails, reminder.UserId)
+			if err != nil {
+				return sent, err
+			}
+			if email == "" {
+				continue
+			}
+			reminder.Email = email
+		}
+
+		claimed, err := uc.db.ClaimReminder(ctx, reminder, time.Now().UTC(), uc.lease)
+		if err != nil {
+			return sent, err
+		}
+		if !claimed {
+			continue
+		}
+
+		sendErr := channels[reminder.Channel].Send(ctx, reminder)
+		reminder.Record(time.Now().UTC(), sendErr, uc.retry)
+		_ = uc.db.SaveReminder(ctx, reminder)
+
+		if sendErr == nil {
+			sent++
+		}
+	}
+
+	return sent, nil
+}
+
+// email возвращает адрес пользователя для напоминаний, запоминая его в cache; пустой — адреса нет
+func (uc *ReminderUseCaseImpl) email(ctx context.Context, cache map[uuid.UUID]string,
+	userId uuid.UUID) (string, error) {
+	if email, ok := cache[userId]; ok {
+		return email, nil
+	}
+
+	contact, err := uc.db.ReadContact(ctx, userId)
+	if errors.Is(err, errors_package.ErrNotFound) {
+		cache[userId] = ""
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	cache[userId] = contact.Email
+	return contact.Email, nil
+}
@@ -0,0 +1,102 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Aiszhio/Task/internal/domain"
+	"github.com/Aiszhio/Task/internal/repository/memory"
+	"github.com/Aiszhio/Task/internal/usecase"
+	"github.com/google/uuid"
+)
+
+// fakeChannel запоминает отправленные напоминания и отказывает, если задан err
+type fakeChannel struct {
+	name string
+	err  error
+	sent []domain.Reminder
+}
+
+func (c *fakeChannel) Name() string {
+	return c.name
+}
+
+func (c *fakeChannel) Send(_ context.Context, reminder *domain.Reminder) error {
+	c.sent = append(c.sent, *reminder)
+	return c.err
+}
+
+func TestSendReminders(t *testing.T) {
+	ctx := context.Background()
+	subs := memory.NewRepository(newLogger())
+	reminders := memory.NewReminderRepository(newLogger())
+
+	email := &fakeChannel{name: domain.ReminderChannelEmail, err: errors.New("smtp is down")}
+	logs := &fakeChannel{name: domain.ReminderChannelLog}
+	windows := []domain.ReminderWindow{domain.ReminderWindow(24 * time.Hour), domain.ReminderWindow(7 * 24 * time.Hour)}
+	retry := domain.RetryPolicy{MaxAttempts: 3, Base: time.Hour, Max: time.Hour}
+	uc := usecase.NewReminderUseCase(reminders, subs, []usecase.ReminderChannel{email, logs}, windows, retry,
+		time.Minute)
+
+	// обе подписки продлятся 1 апреля, через три с половиной дня
+	now := time.Date(2025, time.March, 28, 12, 0, 0, 0, time.UTC)
+	start := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+	withContact := &domain.Subscription{Id: uuid.New(), UserId: uuid.New(), ServiceName: "Okko", Price: 300,
+		BillingInterval: domain.BillingMonth, IntervalCount: 1, StartDate: start}
+	withoutContact := &domain.Subscription{Id: uuid.New(), UserId: uuid.New(), ServiceName: "Ivi", Price: 200,
+		BillingInterval: domain.BillingMonth, IntervalCount: 1, StartDate: start}
+	for _, sub := range []*domain.Subscription{withContact, withoutContact} {
+		if err := subs.CreateSubscription(ctx, sub); err != nil {
+			t.Fatalf("CreateSubscription: %v", err)
+		}
+	}
+
+	if _, err := uc.SetContact(ctx, withContact.UserId, "alice@example.com"); err != nil {
+		t.Fatalf("SetContact: %v", err)
+	}
+
+	sent, err := uc.SendReminders(ctx, now)
+	if err != nil || sent != 2 {
+		t.Fatalf("SendReminders = %d, %v, want two log reminders sent", sent, err)
+	}
+
+	// письмо уходит только пользователю с адресом
+	if len(email.sent) != 1 || email.sent[0].UserId != withContact.UserId || email.sent[0].Email != "alice@example.com" {
+		t.Fatalf("emails = %+v, want one to alice@example.com", email.sent)
+	}
+	if len(logs.sent) != 2 {
+		t.Fatalf("log reminders = %d, want one per subscription", len(logs.sent))
+	}
+
+	listed, err := uc.ListReminders(ctx, withContact.UserId, 10)
+	if err != nil || len(listed) != 2 {
+		t.Fatalf("ListReminders = %+v, %v, want the email and log reminders", listed, err)
+	}
+	for _, reminder := range listed {
+		if reminder.Channel != domain.ReminderChannelEmail {
+			continue
+		}
+		if reminder.Status != domain.DeliveryPending || reminder.Attempts != 1 || reminder.LastError != "smtp is down" ||
+			reminder.NextAttemptAt == nil || reminder.NextAttemptAt.Before(time.Now().Add(retry.Base/2)) {
+			t.Errorf("failed email = %+v, want pending with a retry after the backoff", reminder)
+		}
+	}
+
+	// повторный запуск ничего не отправляет: отправленные заведены, неудачное ждёт повтора
+	sent, err = uc.SendReminders(ctx, now.Add(time.Minute))
+	if err != nil || sent != 0 || len(email.sent) != 1 || len(logs.sent) != 2 {
+		t.Fatalf("second SendReminders = %d, %v with %d emails and %d logs, want nothing new",
+			sent, err, len(email.sent), len(logs.sent))
+	}
+
+	// в узком окне заводится новое напоминание, а не повтор прежнего
+	sent, err = uc.SendReminders(ctx, time.Date(2025, time.March, 31, 12, 0, 0, 0, time.UTC))
+	if err != nil || sent != 2 || len(logs.sent) != 4 {
+		t.Fatalf("SendReminders in the narrow window = %d, %v, want two new log reminders", sent, err)
+	}
+	if logs.sent[2].Window != windows[0] || logs.sent[2].Id == logs.sent[0].Id {
+		t.Errorf("narrow reminder = %+v, want a new reminder for the 1d window", logs.sent[2])
+	}
+}
@@ -1,34 +1,93 @@
 package usecase
 
 import (
+	"context"
+	"errors"
+	"math"
+	"slices"
 	"time"
 
 	"github.com/Aiszhio/Task/internal/domain"
 	errors_package "github.com/Aiszhio/Task/internal/errors"
 	"github.com/Aiszhio/Task/internal/repository/postgres"
-	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
 
 type SubscriptionUseCase interface {
-	AcceptSubscription(ctx *gin.Context, Subscription *domain.Subscription) error
-	GetSubscription(ctx *gin.Context, id uuid.UUID) (*domain.Subscription, error)
-	RemoveSubscription(ctx *gin.Context, id uuid.UUID) error
-	RefreshSubscription(ctx *gin.Context, Subscription *domain.Subscription) error
-	GetListSubscriptions(ctx *gin.Context, Subscription *domain.SubscriptionSummary) (uint64, error)
+	AcceptSubscription(ctx context.Context, Subscription *domain.Subscription) error
+	GetSubscription(ctx context.Context, id uuid.UUID) (*domain.Subscription, error)
+	RemoveSubscription(ctx context.Context, id uuid.UUID) error
+	RefreshSubscription(ctx context.Context, Subscription *domain.Subscription) error
+	AmendSubscription(ctx context.Context, current, patched *domain.Subscription) error
+	GetListSubscriptions(ctx context.Context, Subscription *domain.SubscriptionSummary) (*domain.SubscriptionCost, error)
+	GetSubscriptions(ctx context.Context, filter *domain.SubscriptionFilter) (*domain.SubscriptionPage, error)
+	RestoreSubscription(ctx context.Context, id uuid.UUID) (*domain.Subscription, error)
+	PurgeSubscriptions(ctx context.Context, retention time.Duration) (int64, error)
+	GetSubscriptionHistory(ctx context.Context, id uuid.UUID) ([]domain.SubscriptionEvent, error)
+	ImportSubscriptions(ctx context.Context, rows []domain.ImportRow, mode domain.ImportMode,
+		dryRun bool) (*domain.ImportReport, error)
+	ExportSubscriptions(ctx context.Context, filter *domain.SubscriptionFilter,
+		fn func(sub *domain.Subscription) error) error
+	GetSubscriptionStats(ctx context.Context, at time.Time) (*domain.SubscriptionStats, error)
+	// NotifyEndingSubscriptions публикует subscription.ending_soon о подписках, которые закончатся
+	// в ближайшие within, и возвращает число новых событий
+	NotifyEndingSubscriptions(ctx context.Context, now time.Time, within time.Duration) (int, error)
 }
 
+const maxPageSize = 100
+
+// SubscriptionUseCaseImpl не публикует изменения подписок сам: хранилище записывает их в outbox
+// в той же транзакции, а разбирает outbox worker.RunOutboxRelay
 type SubscriptionUseCaseImpl struct {
-	db postgres.SubscriptionRepository
+	db     postgres.SubscriptionRepository
+	outbox postgres.OutboxRepository
 }
 
-func NewSubscriptionUseCase(db postgres.SubscriptionRepository) *SubscriptionUseCaseImpl {
+func NewSubscriptionUseCase(db postgres.SubscriptionRepository,
+	outbox postgres.OutboxRepository) *SubscriptionUseCaseImpl {
 	return &SubscriptionUseCaseImpl{
-		db: db,
+		db:     db,
+		outbox: outbox,
 	}
 }
 
 func validatesub(Subscription *domain.Subscription) error {
+	err := validatefields(Subscription)
+	if err != nil {
+		return err
+	}
+
+	if startsinpast(Subscription.StartDate) {
+		return errors_package.SubscriptionInPast
+	}
+
+	return nil
+}
+
+// validatepatch проверяет частичное изменение подписки: дата начала в прошлом допустима,
+// пока патч её не меняет, а пользователя подписки сменить нельзя
+func validatepatch(current, patched *domain.Subscription) error {
+	err := validatefields(patched)
+	if err != nil {
+		return err
+	}
+
+	if patched.UserId != current.UserId {
+		return errors_package.ImmutableUser
+	}
+
+	if !patched.StartDate.Equal(current.StartDate) && startsinpast(patched.StartDate) {
+		return errors_package.SubscriptionInPast
+	}
+
+	if !patched.EndDate.IsZero() && patched.EndDate.Before(patched.StartDate) {
+		return errors_package.InvalidPeriod
+	}
+
+	return nil
+}
+
+func validatefields(Subscription *domain.Subscription) error {
 	if Subscription == nil {
 		return errors_package.EmptySub
 	}
@@ -45,23 +104,63 @@ func validatesub(Subscription *domain.Subscription) error {
 		return errors_package.InvalidPrice
 	}
 
-	start := Subscription.StartDate
+	if !Subscription.BillingInterval.Valid() {
+		return errors_package.InvalidInterval
+	}
+
+	if Subscription.IntervalCount <= 0 {
+		return errors_package.InvalidIntervalCnt
+	}
+
+	return nil
+}
+
+func startsinpast(start time.Time) bool {
 	now := time.Now()
 
-	if start.Year() < now.Year() ||
-		(start.Year() == now.Year() && start.Month() < now.Month()) {
-		return errors_package.SubscriptionInPast
+	return start.Year() < now.Year() ||
+		(start.Year() == now.Year() && start.Month() < now.Month())
+}
+
+func validatefilter(filter *domain.SubscriptionFilter) error {
+	if filter.SortBy != "" && !slices.Contains(domain.SubscriptionSortFields, filter.SortBy) {
+		return errors_package.InvalidSortField
+	}
+
+	if filter.MinPrice != nil && filter.MaxPrice != nil && *filter.MinPrice > *filter.MaxPrice {
+		return errors_package.InvalidPriceRange
 	}
 
 	return nil
 }
 
-func (uc *SubscriptionUseCaseImpl) AcceptSubscription(ctx *gin.Context, Subscription *domain.Subscription) error {
-	err := validatesub(Subscription)
+func validatepage(filter *domain.SubscriptionFilter) error {
+	if filter.Limit <= 0 || filter.Limit > maxPageSize || filter.Offset < 0 {
+		return errors_package.InvalidPageSize
+	}
+
+	return nil
+}
+
+func (uc *SubscriptionUseCaseImpl) AcceptSubscription(ctx context.Context, Subscription *domain.Subscription) error {
+	if Subscription == nil {
+		return errors_package.EmptySub
+	}
+
+	err := scopeuser(ctx, &Subscription.UserId)
+	if err != nil {
+		return err
+	}
+
+	err = validatesub(Subscription)
 	if err != nil {
 		return err
 	}
 
+	if Subscription.Id == uuid.Nil {
+		Subscription.Id = uuid.New()
+	}
+
 	err = uc.db.CreateSubscription(ctx, Subscription)
 	if err != nil {
 		return err
@@ -70,7 +169,133 @@ func (uc *SubscriptionUseCaseImpl) AcceptSubscription(ctx *gin.Context, Subscrip
 	return nil
 }
 
-func (uc *SubscriptionUseCaseImpl) GetSubscription(ctx *gin.Context, id uuid.UUID) (*domain.Subscription, error) {
+// ImportSubscriptions проверяет строки импорта через validatesub и сохраняет корректные:
+// в режиме ImportAtomic все вместе или ни одной, в режиме ImportBestEffort каждую отдельно.
+// При dryRun, а в режиме ImportAtomic и при отклонённых проверкой строках, корректные строки
+// только сверяются с хранилищем на конфликты. Сбой хранилища прерывает импорт целиком.
+func (uc *SubscriptionUseCaseImpl) ImportSubscriptions(ctx context.Context, rows []domain.ImportRow,
+	mode domain.ImportMode, dryRun bool) (*domain.ImportReport, error) {
+	if mode != domain.ImportAtomic && mode != domain.ImportBestEffort {
+		return nil, errors_package.InvalidImportMode
+	}
+
+	report := &domain.ImportReport{Mode: mode, DryRun: dryRun}
+
+	var valid []domain.ImportRow
+	for _, row := range rows {
+		if row.Err == nil {
+			row.Err = scopeuser(ctx, &row.Subscription.UserId)
+		}
+
+		if row.Err == nil {
+			row.Err = validatesub(row.Subscription)
+		}
+
+		if row.Err != nil {
+			report.Rejected = append(report.Rejected, domain.ImportResult{Line: row.Line, Err: row.Err})
+			continue
+		}
+
+		if row.Subscription.Id == uuid.Nil {
+			row.Subscription.Id = uuid.New()
+		}
+
+		valid = append(valid, row)
+	}
+
+	if len(valid) == 0 {
+		report.Accepted = accepted(valid)
+		return report, nil
+	}
+
+	var err error
+	switch {
+	case dryRun || (mode == domain.ImportAtomic && len(report.Rejected) > 0):
+		err = uc.importatomic(ctx, valid, report, true)
+	case mode == domain.ImportAtomic:
+		err = uc.importatomic(ctx, valid, report, false)
+	default:
+		err = uc.importbesteffort(ctx, valid, report)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	slices.SortFunc(report.Rejected, func(a, b domain.ImportResult) int { return a.Line - b.Line })
+
+	return report, nil
+}
+
+// importatomic сохраняет строки одной транзакцией; при check транзакция откатывается
+// и строки только проверяются на конфликты
+func (uc *SubscriptionUseCaseImpl) importatomic(ctx context.Context, valid []domain.ImportRow,
+	report *domain.ImportReport, check bool) error {
+	subs := make([]*domain.Subscription, 0, len(valid))
+	for _, row := range valid {
+		subs = append(subs, row.Subscription)
+	}
+
+	err := uc.db.CreateSubscriptions(ctx, subs, check)
+
+	var rowErrs errors_package.RowErrors
+	if errors.As(err, &rowErrs) && !errors.Is(err, errors_package.ErrInternal) {
+		// транзакция откатилась: конфликтующие строки отклонены, остальные не сохранены
+		conflicting := make(map[int]bool, len(rowErrs))
+		for _, rowErr := range rowErrs {
+			conflicting[rowErr.Index] = true
+			report.Rejected = append(report.Rejected,
+				domain.ImportResult{Line: valid[rowErr.Index].Line, Err: rowErr.Err})
+		}
+
+		var rest []domain.ImportRow
+		for i, row := range valid {
+			if !conflicting[i] {
+				rest = append(rest, row)
+			}
+		}
+
+		report.Accepted = accepted(rest)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	report.Accepted = accepted(valid)
+	report.Committed = !check
+	return nil
+}
+
+func (uc *SubscriptionUseCaseImpl) importbesteffort(ctx context.Context, valid []domain.ImportRow,
+	report *domain.ImportReport) error {
+	for _, row := range valid {
+		err := uc.db.CreateSubscription(ctx, row.Subscription)
+		if errors.Is(err, errors_package.ErrInternal) {
+			return err
+		}
+
+		if err != nil {
+			report.Rejected = append(report.Rejected, domain.ImportResult{Line: row.Line, Err: err})
+			continue
+		}
+
+		report.Accepted = append(report.Accepted, domain.ImportResult{Line: row.Line, Id: row.Subscription.Id})
+	}
+
+	report.Committed = true
+	return nil
+}
+
+func accepted(rows []domain.ImportRow) []domain.ImportResult {
+	results := make([]domain.ImportResult, 0, len(rows))
+	for _, row := range rows {
+		results = append(results, domain.ImportResult{Line: row.Line, Id: row.Subscription.Id})
+	}
+
+	return results
+}
+
+func (uc *SubscriptionUseCaseImpl) GetSubscription(ctx context.Context, id uuid.UUID) (*domain.Subscription, error) {
 	if id == uuid.Nil {
 		return nil, errors_package.ErrEmptyId
 	}
@@ -80,15 +305,25 @@ func (uc *SubscriptionUseCaseImpl) GetSubscription(ctx *gin.Context, id uuid.UUI
 		return nil, err
 	}
 
+	err = visible(ctx, subscr)
+	if err != nil {
+		return nil, err
+	}
+
 	return subscr, nil
 }
 
-func (uc *SubscriptionUseCaseImpl) RemoveSubscription(ctx *gin.Context, id uuid.UUID) error {
+func (uc *SubscriptionUseCaseImpl) RemoveSubscription(ctx context.Context, id uuid.UUID) error {
 	if id == uuid.Nil {
 		return errors_package.ErrEmptyId
 	}
 
-	err := uc.db.DeleteSubscription(ctx, id)
+	_, err := uc.GetSubscription(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	err = uc.db.DeleteSubscription(ctx, id)
 	if err != nil {
 		return err
 	}
@@ -96,8 +331,93 @@ func (uc *SubscriptionUseCaseImpl) RemoveSubscription(ctx *gin.Context, id uuid.
 	return nil
 }
 
-func (uc *SubscriptionUseCaseImpl) RefreshSubscription(ctx *gin.Context, Subscription *domain.Subscription) error {
-	err := validatesub(Subscription)
+// RestoreSubscription возвращает удалённую подписку из корзины
+func (uc *SubscriptionUseCaseImpl) RestoreSubscription(ctx context.Context,
+	id uuid.UUID) (*domain.Subscription, error) {
+	if id == uuid.Nil {
+		return nil, errors_package.ErrEmptyId
+	}
+
+	deleted, err := uc.db.ReadDeletedSubscription(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	err = visible(ctx, deleted)
+	if err != nil {
+		return nil, err
+	}
+
+	return uc.db.RestoreSubscription(ctx, id)
+}
+
+// PurgeSubscriptions окончательно удаляет подписки, находящиеся в корзине дольше retention
+func (uc *SubscriptionUseCaseImpl) PurgeSubscriptions(ctx context.Context, retention time.Duration) (int64, error) {
+	if err := internal(ctx); err != nil {
+		return 0, err
+	}
+
+	if retention < 0 {
+		retention = 0
+	}
+
+	return uc.db.PurgeSubscriptions(ctx, retention)
+}
+
+// GetSubscriptionHistory возвращает журнал изменений подписки, в том числе удалённой
+func (uc *SubscriptionUseCaseImpl) GetSubscriptionHistory(ctx context.Context,
+	id uuid.UUID) ([]domain.SubscriptionEvent, error) {
+	if id == uuid.Nil {
+		return nil, errors_package.ErrEmptyId
+	}
+
+	events, err := uc.db.ListSubscriptionEvents(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// владелец не меняется за время жизни подписки, поэтому достаточно первой записи
+	err = visible(ctx, snapshotof(&events[0]))
+	if err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
+
+// snapshotof возвращает состояние подписки, записанное в событии журнала
+func snapshotof(event *domain.SubscriptionEvent) *domain.Subscription {
+	if event.After != nil {
+		return event.After
+	}
+	if event.Before != nil {
+		return event.Before
+	}
+
+	return &domain.Subscription{Id: event.SubscriptionId}
+}
+
+// RefreshSubscription заменяет подписку целиком; пользователя подписки сменить нельзя,
+// а если он не указан, остаётся прежним
+func (uc *SubscriptionUseCaseImpl) RefreshSubscription(ctx context.Context, Subscription *domain.Subscription) error {
+	if Subscription == nil {
+		return errors_package.EmptySub
+	}
+
+	current, err := uc.GetSubscription(ctx, Subscription.Id)
+	if err != nil {
+		return err
+	}
+
+	if Subscription.UserId == uuid.Nil {
+		Subscription.UserId = current.UserId
+	}
+
+	if Subscription.UserId != current.UserId {
+		return errors_package.ImmutableUser
+	}
+
+	err = validatesub(Subscription)
 	if err != nil {
 		return err
 	}
@@ -110,10 +430,164 @@ func (uc *SubscriptionUseCaseImpl) RefreshSubscription(ctx *gin.Context, Subscri
 	return nil
 }
 
-func (uc *SubscriptionUseCaseImpl) GetListSubscriptions(ctx *gin.Context, Subscription *domain.SubscriptionSummary) (uint64, error) {
+// AmendSubscription сохраняет частично изменённую подписку; версия patched
+// используется для проверки, что current не изменилась после чтения
+func (uc *SubscriptionUseCaseImpl) AmendSubscription(ctx context.Context, current, patched *domain.Subscription) error {
+	err := visible(ctx, current)
+	if err != nil {
+		return err
+	}
+
+	err = validatepatch(current, patched)
+	if err != nil {
+		return err
+	}
+
+	return uc.db.UpdateSubscription(ctx, patched)
+}
+
+func (uc *SubscriptionUseCaseImpl) GetListSubscriptions(ctx context.Context,
+	Subscription *domain.SubscriptionSummary) (*domain.SubscriptionCost, error) {
+	err := scopeuser(ctx, &Subscription.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	if Subscription.UserID == uuid.Nil {
+		return nil, errors_package.EmptyUser
+	}
+
 	if Subscription.EndDate.Before(Subscription.StartDate) {
-		return 0, errors_package.InvalidPeriod
+		return nil, errors_package.InvalidPeriod
+	}
+
+	subscriptions, err := uc.db.ListSubscriptions(ctx, Subscription.StartDate, Subscription.EndDate,
+		Subscription.UserID, Subscription.ServiceName)
+	if err != nil {
+		return nil, err
+	}
+
+	return calculatecost(subscriptions, Subscription.StartDate, Subscription.EndDate), nil
+}
+
+// calculatecost раскладывает подписки по месяцам периода [start, end] включительно,
+// приводя стоимость каждой подписки к месячной независимо от её расчётного периода
+func calculatecost(subscriptions []domain.Subscription, start, end time.Time) *domain.SubscriptionCost {
+	cost := &domain.SubscriptionCost{
+		Months: []domain.MonthCost{},
+	}
+
+	last := domain.MonthStart(end)
+	for month := domain.MonthStart(start); !month.After(last); month = month.AddDate(0, 1, 0) {
+		var charged float64
+		for i := range subscriptions {
+			if subscriptions[i].ActiveIn(month) {
+				charged += subscriptions[i].MonthlyCost()
+			}
+		}
+
+		monthCost := domain.MonthCost{Month: month, Cost: uint64(math.Round(charged))}
+
+		cost.TotalCost += monthCost.Cost
+		cost.Months = append(cost.Months, monthCost)
+	}
+
+	return cost
+}
+
+func (uc *SubscriptionUseCaseImpl) GetSubscriptions(ctx context.Context,
+	filter *domain.SubscriptionFilter) (*domain.SubscriptionPage, error) {
+	err := scopeuser(ctx, &filter.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = validatefilter(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	err = validatepage(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return uc.db.FindSubscriptions(ctx, filter)
+}
+
+// ExportSubscriptions передаёт в fn все подписки по фильтру без постраничного ограничения
+func (uc *SubscriptionUseCaseImpl) ExportSubscriptions(ctx context.Context, filter *domain.SubscriptionFilter,
+	fn func(sub *domain.Subscription) error) error {
+	err := scopeuser(ctx, &filter.UserID)
+	if err != nil {
+		return err
+	}
+
+	err = validatefilter(filter)
+	if err != nil {
+		return err
+	}
+
+	return uc.db.StreamSubscriptions(ctx, filter, fn)
+}
+
+// GetSubscriptionStats считает подписки всех пользователей, действующие в месяце at,
+// и их стоимость, приведённую к месячной
+func (uc *SubscriptionUseCaseImpl) GetSubscriptionStats(ctx context.Context,
+	at time.Time) (*domain.SubscriptionStats, error) {
+	if err := internal(ctx); err != nil {
+		return nil, err
+	}
+
+	month := domain.MonthStart(at)
+	stats := &domain.SubscriptionStats{}
+
+	filter := &domain.SubscriptionFilter{ActiveAt: &month}
+
+	err := uc.db.StreamSubscriptions(ctx, filter, func(sub *domain.Subscription) error {
+		stats.Active++
+		stats.MonthlySpend += sub.MonthlyCost()
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return stats, nil
+}
+
+// NotifyEndingSubscriptions находит среди действующих в этом месяце подписок те, что закончатся
+// в ближайшие within, и добавляет события о них в outbox. О каждой подписке и дате окончания
+// сообщается один раз, пока событие хранится в outbox
+func (uc *SubscriptionUseCaseImpl) NotifyEndingSubscriptions(ctx context.Context, now time.Time,
+	within time.Duration) (int, error) {
+	if err := internal(ctx); err != nil {
+		return 0, err
+	}
+
+	month := domain.MonthStart(now)
+	filter := &domain.SubscriptionFilter{ActiveAt: &month}
+
+	var notified int
+	err := uc.db.StreamSubscriptions(ctx, filter, func(sub *domain.Subscription) error {
+		endsAt, ok := sub.EndsAt()
+		if !ok || !endsAt.After(now) || endsAt.Sub(now) > within {
+			return nil
+		}
+
+		added, err := uc.outbox.EnqueueMessage(ctx, domain.EndingSoonEvent(*sub, endsAt, now))
+		if err != nil {
+			return err
+		}
+
+		if added {
+			notified++
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
 	}
 
-	return uc.db.ListSubscriptions(ctx, Subscription.StartDate, Subscription.EndDate, Subscription.UserID, Subscription.ServiceName)
+	return notified, nil
 }
@@ -0,0 +1,103 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/Aiszhio/Task/internal/domain"
+	errors_package "github.com/Aiszhio/Task/internal/errors"
+	"github.com/Aiszhio/Task/internal/repository/memory"
+	"github.com/Aiszhio/Task/internal/usecase"
+	"github.com/google/uuid"
+)
+
+func TestImportSubscriptions(t *testing.T) {
+	start := time.Now().UTC().AddDate(0, 1, 0)
+	start = time.Date(start.Year(), start.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		mode          domain.ImportMode
+		dryRun        bool
+		invalid       bool
+		wantCommitted bool
+		wantAccepted  []int
+		wantRejected  []int
+	}{
+		{name: "atomic reports every conflict", mode: domain.ImportAtomic,
+			wantAccepted: []int{3}, wantRejected: []int{2, 4}},
+		{name: "atomic with an invalid row", mode: domain.ImportAtomic, invalid: true,
+			wantAccepted: []int{3}, wantRejected: []int{2, 4, 5}},
+		{name: "dry run checks conflicts", mode: domain.ImportBestEffort, dryRun: true,
+			wantAccepted: []int{3}, wantRejected: []int{2, 4}},
+		{name: "best effort saves the rest", mode: domain.ImportBestEffort, wantCommitted: true,
+			wantAccepted: []int{3}, wantRejected: []int{2, 4}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			logger := newLogger()
+			subs := memory.NewRepository(logger)
+			uc := usecase.NewSubscriptionUseCase(subs, memory.NewOutboxRepository(subs, logger))
+
+			userID := uuid.New()
+			subscription := func(service string, price int) *domain.Subscription {
+				return &domain.Subscription{ServiceName: service, Price: price, UserId: userID, StartDate: start,
+					BillingInterval: domain.BillingMonth, IntervalCount: 1}
+			}
+
+			for _, sub := range []*domain.Subscription{subscription("Okko", 300), subscription("Ivi", 200)} {
+				if err := uc.AcceptSubscription(ctx, sub); err != nil {
+					t.Fatalf("AcceptSubscription: %v", err)
+				}
+			}
+
+			rows := []domain.ImportRow{
+				{Line: 2, Subscription: subscription("Okko", 400)},
+				{Line: 3, Subscription: subscription("Wink", 100)},
+				{Line: 4, Subscription: subscription("Ivi", 250)},
+			}
+			if tc.invalid {
+				rows = append(rows, domain.ImportRow{Line: 5, Subscription: subscription("Start", -1)})
+			}
+
+			report, err := uc.ImportSubscriptions(ctx, rows, tc.mode, tc.dryRun)
+			if err != nil {
+				t.Fatalf("ImportSubscriptions: %v", err)
+			}
+
+			if report.Committed != tc.wantCommitted {
+				t.Errorf("Committed = %v, want %v", report.Committed, tc.wantCommitted)
+			}
+			if got := lines(report.Accepted); !slices.Equal(got, tc.wantAccepted) {
+				t.Errorf("accepted lines = %v, want %v", got, tc.wantAccepted)
+			}
+			if got := lines(report.Rejected); !slices.Equal(got, tc.wantRejected) {
+				t.Errorf("rejected lines = %v, want %v", got, tc.wantRejected)
+			}
+			for _, rejected := range report.Rejected[:2] {
+				if !errors.Is(rejected.Err, errors_package.ErrConflict) {
+					t.Errorf("line %d rejected with %v, want ErrConflict", rejected.Line, rejected.Err)
+				}
+			}
+
+			_, err = uc.GetSubscription(ctx, rows[1].Subscription.Id)
+			if saved := err == nil; saved != tc.wantCommitted {
+				t.Errorf("accepted row saved = %v (%v), want %v", saved, err, tc.wantCommitted)
+			}
+		})
+	}
+}
+
+func lines(results []domain.ImportResult) []int {
+	got := make([]int, 0, len(results))
+	for _, result := range results {
+		got = append(got, result.Line)
+	}
+
+	return got
+}
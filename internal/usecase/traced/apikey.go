@@ -0,0 +1,57 @@
+package traced
+
+import (
+	"context"
+
+	"github.com/Aiszhio/Task/internal/auth"
+	"github.com/Aiszhio/Task/internal/domain"
+	"github.com/Aiszhio/Task/internal/usecase"
+	"github.com/google/uuid"
+)
+
+var _ usecase.APIKeyUseCase = (*APIKeyUseCase)(nil)
+
+type APIKeyUseCase struct {
+	spanner
+	next usecase.APIKeyUseCase
+}
+
+func NewAPIKeyUseCase(next usecase.APIKeyUseCase) *APIKeyUseCase {
+	return &APIKeyUseCase{
+		spanner: newSpanner("APIKeyUseCase"),
+		next:    next,
+	}
+}
+
+func (u *APIKeyUseCase) CreateAPIKey(ctx context.Context, name string,
+	scopes []string) (key *domain.APIKey, secret string, err error) {
+	ctx, span := u.start(ctx, "CreateAPIKey")
+	defer end(span, &err)
+	return u.next.CreateAPIKey(ctx, name, scopes)
+}
+
+func (u *APIKeyUseCase) ListAPIKeys(ctx context.Context) (keys []domain.APIKey, err error) {
+	ctx, span := u.start(ctx, "ListAPIKeys")
+	defer end(span, &err)
+	return u.next.ListAPIKeys(ctx)
+}
+
+func (u *APIKeyUseCase) RevokeAPIKey(ctx context.Context, id uuid.UUID) (key *domain.APIKey, err error) {
+	ctx, span := u.start(ctx, "RevokeAPIKey")
+	defer end(span, &err)
+	return u.next.RevokeAPIKey(ctx, id)
+}
+
+func (u *APIKeyUseCase) RotateAPIKey(ctx context.Context,
+	id uuid.UUID) (key *domain.APIKey, secret string, err error) {
+	ctx, span := u.start(ctx, "RotateAPIKey")
+	defer end(span, &err)
+	return u.next.RotateAPIKey(ctx, id)
+}
+
+func (u *APIKeyUseCase) AuthenticateAPIKey(ctx context.Context,
+	key string) (principal *auth.Principal, err error) {
+	ctx, span := u.start(ctx, "AuthenticateAPIKey")
+	defer end(span, &err)
+	return u.next.AuthenticateAPIKey(ctx, key)
+}
@@ -0,0 +1,57 @@
+package traced
+
+import (
+	"context"
+	"time"
+
+	"github.com/Aiszhio/Task/internal/domain"
+	"github.com/Aiszhio/Task/internal/usecase"
+	"github.com/google/uuid"
+)
+
+var _ usecase.ReminderUseCase = (*ReminderUseCase)(nil)
+
+type ReminderUseCase struct {
+	spanner
+	next usecase.ReminderUseCase
+}
+
+func NewReminderUseCase(next usecase.ReminderUseCase) *ReminderUseCase {
+	return &ReminderUseCase{
+		spanner: newSpanner("ReminderUseCase"),
+		next:    next,
+	}
+}
+
+func (u *ReminderUseCase) SetContact(ctx context.Context, userId uuid.UUID,
+	email string) (contact *domain.ReminderContact, err error) {
+	ctx, span := u.start(ctx, "SetContact")
+	defer end(span, &err)
+	return u.next.SetContact(ctx, userId, email)
+}
+
+func (u *ReminderUseCase) GetContact(ctx context.Context,
+	userId uuid.UUID) (contact *domain.ReminderContact, err error) {
+	ctx, span := u.start(ctx, "GetContact")
+	defer end(span, &err)
+	return u.next.GetContact(ctx, userId)
+}
+
+func (u *ReminderUseCase) DeleteContact(ctx context.Context, userId uuid.UUID) (err error) {
+	ctx, span := u.start(ctx, "DeleteContact")
+	defer end(span, &err)
+	return u.next.DeleteContact(ctx, userId)
+}
+
+func (u *ReminderUseCase) ListReminders(ctx context.Context, userId uuid.UUID,
+	limit int) (reminders []domain.Reminder, err error) {
+	ctx, span := u.start(ctx, "ListReminders")
+	defer end(span, &err)
+	return u.next.ListReminders(ctx, userId, limit)
+}
+
+func (u *ReminderUseCase) SendReminders(ctx context.Context, now time.Time) (sent int, err error) {
+	ctx, span := u.start(ctx, "SendReminders")
+	defer end(span, &err)
+	return u.next.SendReminders(ctx, now)
+}
@@ -0,0 +1,119 @@
+package traced
+
+import (
+	"context"
+	"time"
+
+	"github.com/Aiszhio/Task/internal/domain"
+	"github.com/Aiszhio/Task/internal/usecase"
+	"github.com/google/uuid"
+)
+
+var _ usecase.SubscriptionUseCase = (*SubscriptionUseCase)(nil)
+
+type SubscriptionUseCase struct {
+	spanner
+	next usecase.SubscriptionUseCase
+}
+
+func NewSubscriptionUseCase(next usecase.SubscriptionUseCase) *SubscriptionUseCase {
+	return &SubscriptionUseCase{
+		spanner: newSpanner("SubscriptionUseCase"),
+		next:    next,
+	}
+}
+
+func (u *SubscriptionUseCase) AcceptSubscription(ctx context.Context, sub *domain.Subscription) (err error) {
+	ctx, span := u.start(ctx, "AcceptSubscription")
+	defer end(span, &err)
+	return u.next.AcceptSubscription(ctx, sub)
+}
+
+func (u *SubscriptionUseCase) GetSubscription(ctx context.Context,
+	id uuid.UUID) (sub *domain.Subscription, err error) {
+	ctx, span := u.start(ctx, "GetSubscription")
+	defer end(span, &err)
+	return u.next.GetSubscription(ctx, id)
+}
+
+func (u *SubscriptionUseCase) RemoveSubscription(ctx context.Context, id uuid.UUID) (err error) {
+	ctx, span := u.start(ctx, "RemoveSubscription")
+	defer end(span, &err)
+	return u.next.RemoveSubscription(ctx, id)
+}
+
+func (u *SubscriptionUseCase) RefreshSubscription(ctx context.Context, sub *domain.Subscription) (err error) {
+	ctx, span := u.start(ctx, "RefreshSubscription")
+	defer end(span, &err)
+	return u.next.RefreshSubscription(ctx, sub)
+}
+
+func (u *SubscriptionUseCase) AmendSubscription(ctx context.Context,
+	current, patched *domain.Subscription) (err error) {
+	ctx, span := u.start(ctx, "AmendSubscription")
+	defer end(span, &err)
+	return u.next.AmendSubscription(ctx, current, patched)
+}
+
+func (u *SubscriptionUseCase) GetListSubscriptions(ctx context.Context,
+	summary *domain.SubscriptionSummary) (cost *domain.SubscriptionCost, err error) {
+	ctx, span := u.start(ctx, "GetListSubscriptions")
+	defer end(span, &err)
+	return u.next.GetListSubscriptions(ctx, summary)
+}
+
+func (u *SubscriptionUseCase) GetSubscriptions(ctx context.Context,
+	filter *domain.SubscriptionFilter) (page *domain.SubscriptionPage, err error) {
+	ctx, span := u.start(ctx, "GetSubscriptions")
+	defer end(span, &err)
+	return u.next.GetSubscriptions(ctx, filter)
+}
+
+func (u *SubscriptionUseCase) RestoreSubscription(ctx context.Context,
+	id uuid.UUID) (sub *domain.Subscription, err error) {
+	ctx, span := u.start(ctx, "RestoreSubscription")
+	defer end(span, &err)
+	return u.next.RestoreSubscription(ctx, id)
+}
+
+func (u *SubscriptionUseCase) PurgeSubscriptions(ctx context.Context,
+	retention time.Duration) (purged int64, err error) {
+	ctx, span := u.start(ctx, "PurgeSubscriptions")
+	defer end(span, &err)
+	return u.next.PurgeSubscriptions(ctx, retention)
+}
+
+func (u *SubscriptionUseCase) GetSubscriptionHistory(ctx context.Context,
+	id uuid.UUID) (events []domain.SubscriptionEvent, err error) {
+	ctx, span := u.start(ctx, "GetSubscriptionHistory")
+	defer end(span, &err)
+	return u.next.GetSubscriptionHistory(ctx, id)
+}
+
+func (u *SubscriptionUseCase) ImportSubscriptions(ctx context.Context, rows []domain.ImportRow,
+	mode domain.ImportMode, dryRun bool) (report *domain.ImportReport, err error) {
+	ctx, span := u.start(ctx, "ImportSubscriptions")
+	defer end(span, &err)
+	return u.next.ImportSubscriptions(ctx, rows, mode, dryRun)
+}
+
+func (u *SubscriptionUseCase) ExportSubscriptions(ctx context.Context, filter *domain.SubscriptionFilter,
+	fn func(sub *domain.Subscription) error) (err error) {
+	ctx, span := u.start(ctx, "ExportSubscriptions")
+	defer end(span, &err)
+	return u.next.ExportSubscriptions(ctx, filter, fn)
+}
+
+func (u *SubscriptionUseCase) GetSubscriptionStats(ctx context.Context,
+	at time.Time) (stats *domain.SubscriptionStats, err error) {
+	ctx, span := u.start(ctx, "GetSubscriptionStats")
+	defer end(span, &err)
+	return u.next.GetSubscriptionStats(ctx, at)
+}
+
+func (u *SubscriptionUseCase) NotifyEndingSubscriptions(ctx context.Context, now time.Time,
+	within time.Duration) (notified int, err error) {
+	ctx, span := u.start(ctx, "NotifyEndingSubscriptions")
+	defer end(span, &err)
+	return u.next.NotifyEndingSubscriptions(ctx, now, within)
+}
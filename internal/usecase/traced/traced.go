@@ -0,0 +1,38 @@
+// Package traced оборачивает сценарии использования и открывает спан на каждый вызов
+package traced
+
+import (
+	"context"
+	"errors"
+
+	errors_package "github.com/Aiszhio/Task/internal/errors"
+	"github.com/Aiszhio/Task/internal/tracing"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type spanner struct {
+	tracer  trace.Tracer
+	usecase string
+}
+
+func newSpanner(usecase string) spanner {
+	return spanner{tracer: tracing.Tracer(), usecase: usecase}
+}
+
+func (s spanner) start(ctx context.Context, operation string) (context.Context, trace.Span) {
+	return s.tracer.Start(ctx, s.usecase+"."+operation)
+}
+
+// end вызывается через defer; ошибки предметной области записываются в спан событием,
+// а сбоем спан помечает только ErrInternal
+func end(span trace.Span, err *error) {
+	if *err != nil {
+		span.RecordError(*err)
+		if errors.Is(*err, errors_package.ErrInternal) {
+			span.SetStatus(codes.Error, (*err).Error())
+		}
+	}
+
+	span.End()
+}
@@ -0,0 +1,61 @@
+package traced
+
+import (
+	"context"
+
+	"github.com/Aiszhio/Task/internal/domain"
+	"github.com/Aiszhio/Task/internal/usecase"
+	"github.com/google/uuid"
+)
+
+var _ usecase.WebhookUseCase = (*WebhookUseCase)(nil)
+
+type WebhookUseCase struct {
+	spanner
+	next usecase.WebhookUseCase
+}
+
+func NewWebhookUseCase(next usecase.WebhookUseCase) *WebhookUseCase {
+	return &WebhookUseCase{
+		spanner: newSpanner("WebhookUseCase"),
+		next:    next,
+	}
+}
+
+func (u *WebhookUseCase) CreateWebhook(ctx context.Context, rawURL string, eventTypes []domain.WebhookEventType,
+	secret string) (webhook *domain.Webhook, issued string, err error) {
+	ctx, span := u.start(ctx, "CreateWebhook")
+	defer end(span, &err)
+	return u.next.CreateWebhook(ctx, rawURL, eventTypes, secret)
+}
+
+func (u *WebhookUseCase) ListWebhooks(ctx context.Context) (webhooks []domain.Webhook, err error) {
+	ctx, span := u.start(ctx, "ListWebhooks")
+	defer end(span, &err)
+	return u.next.ListWebhooks(ctx)
+}
+
+func (u *WebhookUseCase) DeleteWebhook(ctx context.Context, id uuid.UUID) (err error) {
+	ctx, span := u.start(ctx, "DeleteWebhook")
+	defer end(span, &err)
+	return u.next.DeleteWebhook(ctx, id)
+}
+
+func (u *WebhookUseCase) ListDeliveries(ctx context.Context, webhookId uuid.UUID, status domain.DeliveryStatus,
+	limit int) (deliveries []domain.WebhookDelivery, err error) {
+	ctx, span := u.start(ctx, "ListDeliveries")
+	defer end(span, &err)
+	return u.next.ListDeliveries(ctx, webhookId, status, limit)
+}
+
+func (u *WebhookUseCase) Publish(ctx context.Context, event *domain.WebhookEvent) (err error) {
+	ctx, span := u.start(ctx, "Publish")
+	defer end(span, &err)
+	return u.next.Publish(ctx, event)
+}
+
+func (u *WebhookUseCase) DispatchDeliveries(ctx context.Context, limit int) (sent int, err error) {
+	ctx, span := u.start(ctx, "DispatchDeliveries")
+	defer end(span, &err)
+	return u.next.DispatchDeliveries(ctx, limit)
+}
@@ -0,0 +1,207 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Aiszhio/Task/internal/domain"
+	errors_package "github.com/Aiszhio/Task/internal/errors"
+	"github.com/Aiszhio/Task/internal/repository/postgres"
+	"github.com/Aiszhio/Task/internal/webhook"
+	"github.com/google/uuid"
+)
+
+// EventPublisher — приёмник, в который outbox публикует события подписок
+type EventPublisher interface {
+	Publish(ctx context.Context, event *domain.WebhookEvent) error
+}
+
+// WebhookSender отправляет доставку получателю и возвращает код ответа; ошибка — неудачная попытка
+type WebhookSender interface {
+	Send(ctx context.Context, job *domain.WebhookJob) (int, error)
+}
+
+type WebhookUseCase interface {
+	EventPublisher
+	// CreateWebhook регистрирует вебхук; пустой secret генерируется. Секрет возвращается только здесь
+	CreateWebhook(ctx context.Context, rawURL string, eventTypes []domain.WebhookEventType,
+		secret string) (*domain.Webhook, string, error)
+	ListWebhooks(ctx context.Context) ([]domain.Webhook, error)
+	DeleteWebhook(ctx context.Context, id uuid.UUID) error
+	ListDeliveries(ctx context.Context, webhookId uuid.UUID, status domain.DeliveryStatus,
+		limit int) ([]domain.WebhookDelivery, error)
+	// DispatchDeliveries отправляет до limit наступивших доставок и возвращает число отправленных
+	DispatchDeliveries(ctx context.Context, limit int) (int, error)
+}
+
+const (
+	minWebhookSecret = 16
+	maxDeliveryPage  = 100
+)
+
+type WebhookUseCaseImpl struct {
+	db     postgres.WebhookRepository
+	sender WebhookSender
+	retry  domain.RetryPolicy
+	lease  time.Duration
+}
+
+// NewWebhookUseCase создаёт вебхуки с политикой повторов retry; lease должна превышать время
+// одной попытки, иначе доставку может параллельно взять другая реплика
+func NewWebhookUseCase(db postgres.WebhookRepository, sender WebhookSender, retry domain.RetryPolicy,
+	lease time.Duration) *WebhookUseCaseImpl {
+	return &WebhookUseCaseImpl{
+		db:     db,
+		sender: sender,
+		retry:  retry,
+		lease:  lease,
+	}
+}
+
+func validatewebhook(rawURL string, eventTypes []domain.WebhookEventType, secret string) error {
+	target, err := url.Parse(rawURL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return errors_package.InvalidWebhookURL
+	}
+
+	if len(eventTypes) == 0 {
+		return errors_package.EmptyWebhookEvents
+	}
+
+	for _, eventType := range eventTypes {
+		if !eventType.Valid() {
+			return errors_package.InvalidWebhookEvent
+		}
+	}
+
+	if secret != "" && len(secret) < minWebhookSecret {
+		return errors_package.WeakWebhookSecret
+	}
+
+	return nil
+}
+
+func (uc *WebhookUseCaseImpl) CreateWebhook(ctx context.Context, rawURL string,
+	eventTypes []domain.WebhookEventType, secret string) (*domain.Webhook, string, error) {
+	if err := internal(ctx); err != nil {
+		return nil, "", err
+	}
+
+	rawURL, secret = strings.TrimSpace(rawURL), strings.TrimSpace(secret)
+	eventTypes = slices.Compact(slices.Sorted(slices.Values(eventTypes)))
+	if err := validatewebhook(rawURL, eventTypes, secret); err != nil {
+		return nil, "", err
+	}
+
+	if secret == "" {
+		generated, err := webhook.NewSecret()
+		if err != nil {
+			return nil, "", errors_package.Internal(err)
+		}
+		secret = generated
+	}
+
+	hook := &domain.Webhook{
+		Id:         uuid.New(),
+		URL:        rawURL,
+		EventTypes: eventTypes,
+		Secret:     secret,
+	}
+
+	if err := uc.db.CreateWebhook(ctx, hook); err != nil {
+		return nil, "", err
+	}
+
+	return hook, secret, nil
+}
+
+func (uc *WebhookUseCaseImpl) ListWebhooks(ctx context.Context) ([]domain.Webhook, error) {
+	if err := internal(ctx); err != nil {
+		return nil, err
+	}
+
+	return uc.db.ListWebhooks(ctx)
+}
+
+func (uc *WebhookUseCaseImpl) DeleteWebhook(ctx context.Context, id uuid.UUID) error {
+	if err := internal(ctx); err != nil {
+		return err
+	}
+
+	if id == uuid.Nil {
+		return errors_package.ErrEmptyId
+	}
+
+	return uc.db.DeleteWebhook(ctx, id)
+}
+
+func (uc *WebhookUseCaseImpl) ListDeliveries(ctx context.Context, webhookId uuid.UUID, status domain.DeliveryStatus,
+	limit int) ([]domain.WebhookDelivery, error) {
+	if err := internal(ctx); err != nil {
+		return nil, err
+	}
+
+	if webhookId == uuid.Nil {
+		return nil, errors_package.ErrEmptyId
+	}
+
+	switch status {
+	case "", domain.DeliveryPending, domain.DeliverySucceeded, domain.DeliveryFailed:
+	default:
+		return nil, errors_package.InvalidDeliveryStatus
+	}
+
+	if limit <= 0 || limit > maxDeliveryPage {
+		return nil, errors_package.InvalidPageSize
+	}
+
+	if _, err := uc.db.ReadWebhook(ctx, webhookId); err != nil {
+		return nil, err
+	}
+
+	return uc.db.ListDeliveries(ctx, webhookId, status, limit)
+}
+
+// Publish заводит доставки события на все вебхуки, подписанные на его вид
+func (uc *WebhookUseCaseImpl) Publish(ctx context.Context, event *domain.WebhookEvent) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return errors_package.Internal(err)
+	}
+
+	_, err = uc.db.EnqueueDeliveries(ctx, event, payload)
+	return err
+}
+
+// DispatchDeliveries отправляет доставки параллельно и записывает итог каждой попытки;
+// сбой записи уже залогирован хранилищем, и доставка повторится после аренды
+func (uc *WebhookUseCaseImpl) DispatchDeliveries(ctx context.Context, limit int) (int, error) {
+	if err := internal(ctx); err != nil {
+		return 0, err
+	}
+
+	jobs, err := uc.db.ClaimDeliveries(ctx, time.Now().UTC(), uc.lease, limit)
+	if err != nil {
+		return 0, err
+	}
+
+	var wg sync.WaitGroup
+	for i := range jobs {
+		wg.Add(1)
+		go func(job *domain.WebhookJob) {
+			defer wg.Done()
+
+			status, sendErr := uc.sender.Send(ctx, job)
+			job.Delivery.Record(time.Now().UTC(), status, sendErr, uc.retry)
+			_ = uc.db.SaveDelivery(ctx, &job.Delivery)
+		}(&jobs[i])
+	}
+	wg.Wait()
+
+	return len(jobs), nil
+}
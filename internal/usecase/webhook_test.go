@@ -0,0 +1,89 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Aiszhio/Task/internal/domain"
+	"github.com/Aiszhio/Task/internal/repository/memory"
+	"github.com/Aiszhio/Task/internal/usecase"
+	"github.com/google/uuid"
+)
+
+// fakeSender отвечает 204 на все адреса, кроме failing
+type fakeSender struct {
+	mu      sync.Mutex
+	failing string
+	sent    []domain.WebhookJob
+}
+
+func (s *fakeSender) Send(_ context.Context, job *domain.WebhookJob) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sent = append(s.sent, *job)
+	if job.URL == s.failing {
+		return 503, errors.New("unexpected response status 503")
+	}
+
+	return 204, nil
+}
+
+func TestWebhookPublishAndDispatch(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.NewWebhookRepository(newLogger())
+	sender := &fakeSender{failing: "https://down.example.com/hook"}
+	retry := domain.RetryPolicy{MaxAttempts: 3, Base: time.Minute, Max: time.Hour}
+	uc := usecase.NewWebhookUseCase(repo, sender, retry, time.Minute)
+
+	created, _, err := uc.CreateWebhook(ctx, "https://up.example.com/hook",
+		[]domain.WebhookEventType{domain.WebhookSubscriptionCreated}, "")
+	if err != nil {
+		t.Fatalf("CreateWebhook: %v", err)
+	}
+	down, _, err := uc.CreateWebhook(ctx, sender.failing,
+		[]domain.WebhookEventType{domain.WebhookSubscriptionCreated, domain.WebhookSubscriptionDeleted}, "")
+	if err != nil {
+		t.Fatalf("CreateWebhook: %v", err)
+	}
+
+	sub := domain.Subscription{Id: uuid.New(), UserId: uuid.New(), ServiceName: "Okko", Price: 300}
+	event := domain.NewWebhookEvent(domain.WebhookSubscriptionCreated, sub, time.Now().UTC())
+
+	// outbox может опубликовать событие повторно, но доставка на каждый вебхук заводится одна
+	for range 2 {
+		if err = uc.Publish(ctx, event); err != nil {
+			t.Fatalf("Publish: %v", err)
+		}
+	}
+	updated := domain.NewWebhookEvent(domain.WebhookSubscriptionUpdated, sub, time.Now().UTC())
+	if err = uc.Publish(ctx, updated); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+
+	dispatched, err := uc.DispatchDeliveries(ctx, 10)
+	if err != nil || dispatched != 2 || len(sender.sent) != 2 {
+		t.Fatalf("DispatchDeliveries = %d, %v, sent %d, want one delivery per subscribed webhook",
+			dispatched, err, len(sender.sent))
+	}
+
+	for hook, want := range map[uuid.UUID]domain.DeliveryStatus{
+		created.Id: domain.DeliverySucceeded,
+		down.Id:    domain.DeliveryPending,
+	} {
+		deliveries, err := uc.ListDeliveries(ctx, hook, "", 10)
+		if err != nil || len(deliveries) != 1 {
+			t.Fatalf("ListDeliveries = %+v, %v, want one delivery", deliveries, err)
+		}
+		if got := deliveries[0]; got.Status != want || got.EventId != event.Id || got.Attempts != 1 {
+			t.Errorf("delivery = %+v, want %s after one attempt", got, want)
+		}
+	}
+
+	if again, err := uc.DispatchDeliveries(ctx, 10); err != nil || again != 0 {
+		t.Errorf("DispatchDeliveries before the retry = %d, %v, want nothing", again, err)
+	}
+}
@@ -0,0 +1,61 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/Aiszhio/Task/internal/domain"
+)
+
+// responseLimit — сколько байт ответа получателя читается, прежде чем соединение закрывается
+const responseLimit = 64 << 10
+
+// Sender отправляет доставки по HTTP
+type Sender struct {
+	client *http.Client
+}
+
+func NewSender(timeout time.Duration) *Sender {
+	return &Sender{
+		client: &http.Client{
+			Timeout: timeout,
+			// перенаправление могло бы увести подписанное событие на чужой адрес
+			CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+		},
+	}
+}
+
+// Send отправляет событие доставки POST-запросом и возвращает код ответа;
+// попытка удалась, только если получатель ответил 2xx
+func (s *Sender) Send(ctx context.Context, job *domain.WebhookJob) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, job.URL, bytes.NewReader(job.Delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "subscriptions-api-webhooks/1")
+	req.Header.Set(HeaderEvent, string(job.Delivery.EventType))
+	req.Header.Set(HeaderDelivery, job.Delivery.Id.String())
+	req.Header.Set(HeaderTimestamp, fmt.Sprint(timestamp))
+	req.Header.Set(HeaderSignature, Sign(job.Secret, timestamp, job.Delivery.Payload))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, responseLimit))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("unexpected response status %d", resp.StatusCode)
+	}
+
+	return resp.StatusCode, nil
+}
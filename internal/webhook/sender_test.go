@@ -0,0 +1,108 @@
+package webhook
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Aiszhio/Task/internal/domain"
+	"github.com/google/uuid"
+)
+
+func newJob(url string) *domain.WebhookJob {
+	return &domain.WebhookJob{
+		Delivery: domain.WebhookDelivery{
+			Id:        uuid.New(),
+			EventType: domain.WebhookSubscriptionCreated,
+			Payload:   []byte(`{"type":"subscription.created"}`),
+		},
+		URL:    url,
+		Secret: "whsec_test",
+	}
+}
+
+func TestSenderSend(t *testing.T) {
+	job := newJob("")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+
+		if r.Method != http.MethodPost || r.Header.Get(HeaderEvent) != string(job.Delivery.EventType) ||
+			r.Header.Get(HeaderDelivery) != job.Delivery.Id.String() {
+			t.Errorf("request = %s with headers %v, want POST with the event and delivery id", r.Method, r.Header)
+		}
+
+		err := Verify(job.Secret, r.Header.Get(HeaderTimestamp), r.Header.Get(HeaderSignature), body, time.Now(),
+			time.Minute)
+		if err != nil {
+			t.Errorf("Verify on the receiver: %v", err)
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	job.URL = server.URL
+	status, err := NewSender(time.Second).Send(context.Background(), job)
+	if err != nil || status != http.StatusNoContent {
+		t.Fatalf("Send = %d, %v, want 204 without an error", status, err)
+	}
+}
+
+func TestSenderSendFailures(t *testing.T) {
+	unavailable := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "maintenance", http.StatusServiceUnavailable)
+	}))
+	defer unavailable.Close()
+
+	redirect := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, unavailable.URL, http.StatusFound)
+	}))
+	defer redirect.Close()
+
+	release := make(chan struct{})
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer slow.Close()
+	defer close(release)
+
+	cases := []struct {
+		name   string
+		url    string
+		status int
+	}{
+		{"non-2xx response", unavailable.URL, http.StatusServiceUnavailable},
+		{"redirect is not followed", redirect.URL, http.StatusFound},
+		{"timeout", slow.URL, 0},
+	}
+
+	sender := NewSender(100 * time.Millisecond)
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			status, err := sender.Send(context.Background(), newJob(tc.url))
+			if err == nil || status != tc.status {
+				t.Fatalf("Send = %d, %v, want %d with an error", status, err, tc.status)
+			}
+		})
+	}
+}
+
+func TestSenderSendReadsLimitedResponse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, strings.Repeat("x", 4*responseLimit))
+	}))
+	defer server.Close()
+
+	status, err := NewSender(time.Second).Send(context.Background(), newJob(server.URL))
+	if err != nil || status != http.StatusOK {
+		t.Fatalf("Send = %d, %v, want 200 without an error", status, err)
+	}
+}
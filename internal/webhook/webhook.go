@@ -0,0 +1,73 @@
+// Package webhook подписывает и отправляет события подписок на адреса вебхуков,
+// а получателю помогает проверить подпись
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Заголовки запроса с событием
+const (
+	HeaderEvent     = "Webhook-Event"
+	HeaderDelivery  = "Webhook-Delivery"
+	HeaderTimestamp = "Webhook-Timestamp"
+	HeaderSignature = "Webhook-Signature"
+)
+
+const (
+	secretPrefix    = "whsec_"
+	secretBytes     = 32
+	signatureScheme = "sha256="
+)
+
+var (
+	ErrInvalidSignature = errors.New("webhook signature does not match")
+	ErrStaleTimestamp   = errors.New("webhook timestamp is outside the tolerance")
+)
+
+// NewSecret генерирует секрет для подписи запросов
+func NewSecret() (string, error) {
+	secret := make([]byte, secretBytes)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+
+	return secretPrefix + base64.RawURLEncoding.EncodeToString(secret), nil
+}
+
+// Sign возвращает подпись тела запроса: HMAC-SHA256 по секрету от строки "<timestamp>.<body>".
+// Время входит в подпись, чтобы перехваченный запрос нельзя было повторить позже
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+
+	return signatureScheme + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify проверяет подпись запроса и что он отправлен не раньше, чем за tolerance до now
+func Verify(secret, timestamp, signature string, body []byte, now time.Time, tolerance time.Duration) error {
+	sent, err := strconv.ParseInt(strings.TrimSpace(timestamp), 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+
+	if age := now.Sub(time.Unix(sent, 0)); age > tolerance || age < -tolerance {
+		return ErrStaleTimestamp
+	}
+
+	if !hmac.Equal([]byte(Sign(secret, sent, body)), []byte(strings.TrimSpace(signature))) {
+		return ErrInvalidSignature
+	}
+
+	return nil
+}
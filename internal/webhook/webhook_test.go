@@ -0,0 +1,76 @@
+package webhook
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSign(t *testing.T) {
+	// printf '1700000000.{}' | openssl dgst -sha256 -hmac secret
+	want := "sha256=b8569b78799ff9e3cbff0fc2d63a33a2b57f3282abd07c37ae5e8e7d79a5f163"
+	got := Sign("secret", 1700000000, []byte("{}"))
+	if got != want {
+		t.Fatalf("Sign = %q, want %q", got, want)
+	}
+
+	if Sign("secret", 1700000001, []byte("{}")) == got {
+		t.Error("Sign ignores the timestamp")
+	}
+	if Sign("other", 1700000000, []byte("{}")) == got {
+		t.Error("Sign ignores the secret")
+	}
+}
+
+func TestVerify(t *testing.T) {
+	now := time.Date(2025, time.July, 1, 12, 0, 0, 0, time.UTC)
+	body := []byte(`{"type":"subscription.created"}`)
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	signature := Sign("whsec_test", now.Unix(), body)
+
+	cases := []struct {
+		name      string
+		secret    string
+		timestamp string
+		signature string
+		body      []byte
+		now       time.Time
+		want      error
+	}{
+		{"valid", "whsec_test", timestamp, signature, body, now, nil},
+		{"clock skew within tolerance", "whsec_test", timestamp, signature, body, now.Add(-4 * time.Minute), nil},
+		{"tampered body", "whsec_test", timestamp, signature, []byte(`{"type":"subscription.deleted"}`), now,
+			ErrInvalidSignature},
+		{"wrong secret", "whsec_other", timestamp, signature, body, now, ErrInvalidSignature},
+		{"tampered timestamp", "whsec_test", strconv.FormatInt(now.Unix()+1, 10), signature, body, now,
+			ErrInvalidSignature},
+		{"malformed timestamp", "whsec_test", "yesterday", signature, body, now, ErrInvalidSignature},
+		{"replayed later", "whsec_test", timestamp, signature, body, now.Add(6 * time.Minute), ErrStaleTimestamp},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := Verify(tc.secret, tc.timestamp, tc.signature, tc.body, tc.now, 5*time.Minute)
+			if !errors.Is(err, tc.want) {
+				t.Fatalf("Verify = %v, want %v", err, tc.want)
+			}
+		})
+	}
+}
+
+func TestNewSecret(t *testing.T) {
+	first, err := NewSecret()
+	if err != nil {
+		t.Fatalf("NewSecret: %v", err)
+	}
+	second, err := NewSecret()
+	if err != nil {
+		t.Fatalf("NewSecret: %v", err)
+	}
+
+	if !strings.HasPrefix(first, secretPrefix) || first == second {
+		t.Fatalf("NewSecret = %q, %q, want distinct secrets with the %s prefix", first, second, secretPrefix)
+	}
+}
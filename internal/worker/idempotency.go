@@ -0,0 +1,22 @@
+package worker
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/Aiszhio/Task/internal/repository/postgres"
+)
+
+// RunKeyCleanup раз в interval удаляет просроченные ключи идемпотентности. Блокируется до отмены ctx.
+func RunKeyCleanup(ctx context.Context, store postgres.IdempotencyRepository, interval time.Duration,
+	logger *slog.Logger) {
+	every(ctx, interval, func() {
+		deleted, err := store.DeleteExpiredKeys(ctx)
+		if err != nil {
+			logger.ErrorContext(ctx, "failed to delete expired idempotency keys", "err", err)
+		} else if deleted > 0 {
+			logger.InfoContext(ctx, "expired idempotency keys deleted", "count", deleted)
+		}
+	})
+}
@@ -0,0 +1,59 @@
+package worker
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/Aiszhio/Task/internal/domain"
+	"github.com/Aiszhio/Task/internal/repository/postgres"
+	"github.com/Aiszhio/Task/internal/usecase"
+)
+
+// RunOutboxRelay раз в interval публикует сообщения outbox в sink пачками по batch; пока пачки
+// приходят полными, следующая берётся сразу. Публикация одного сообщения ограничена timeout.
+// Блокируется до отмены ctx.
+func RunOutboxRelay(ctx context.Context, outbox postgres.OutboxRepository, sink usecase.EventPublisher,
+	interval time.Duration, batch int, timeout time.Duration, retry domain.RetryPolicy, logger *slog.Logger) {
+	publish := func(ctx context.Context, msg *domain.OutboxMessage) error {
+		publishCtx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+
+		err := sink.Publish(publishCtx, &msg.Event)
+		if err != nil && retry.Exhausted(msg.Attempts+1) {
+			logger.ErrorContext(ctx, "failed to publish outbox message, giving up", "id", msg.Id,
+				"event", msg.Event.Type, "subscription", msg.SubscriptionId, "attempts", msg.Attempts+1, "err", err)
+		} else if err != nil {
+			logger.WarnContext(ctx, "failed to publish outbox message, will retry", "id", msg.Id,
+				"event", msg.Event.Type, "subscription", msg.SubscriptionId, "attempts", msg.Attempts+1, "err", err)
+		}
+		return err
+	}
+
+	every(ctx, interval, func() {
+		for ctx.Err() == nil {
+			relayed, err := outbox.RelayMessages(ctx, time.Now().UTC(), batch, retry, publish)
+			if err != nil {
+				logger.ErrorContext(ctx, "failed to relay outbox", "err", err)
+				return
+			}
+			if relayed < batch {
+				return
+			}
+		}
+	})
+}
+
+// RunOutboxCleanup раз в interval удаляет сообщения, опубликованные дольше retention назад.
+// Блокируется до отмены ctx.
+func RunOutboxCleanup(ctx context.Context, outbox postgres.OutboxRepository, interval, retention time.Duration,
+	logger *slog.Logger) {
+	every(ctx, interval, func() {
+		deleted, err := outbox.DeletePublished(ctx, retention)
+		if err != nil {
+			logger.ErrorContext(ctx, "failed to delete published outbox messages", "err", err)
+		} else if deleted > 0 {
+			logger.InfoContext(ctx, "published outbox messages deleted", "count", deleted)
+		}
+	})
+}
@@ -0,0 +1,140 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Aiszhio/Task/internal/domain"
+	"github.com/Aiszhio/Task/internal/repository/memory"
+	"github.com/google/uuid"
+)
+
+// recordingSink запоминает опубликованные события, отказывает событиям подписки failing
+// и отменяет разбор после calls попыток
+type recordingSink struct {
+	mu        sync.Mutex
+	failing   uuid.UUID
+	calls     int
+	stopAfter int
+	stop      context.CancelFunc
+	published map[uuid.UUID]int
+}
+
+func (s *recordingSink) Publish(_ context.Context, event *domain.WebhookEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.calls++
+	if s.calls == s.stopAfter {
+		s.stop()
+	}
+
+	if event.Subscription.Id == s.failing {
+		return errors.New("sink is down")
+	}
+
+	s.published[event.Id]++
+	return nil
+}
+
+func TestRunOutboxRelay(t *testing.T) {
+	logger := slog.New(slog.DiscardHandler)
+	subs := memory.NewRepository(logger)
+	outbox := memory.NewOutboxRepository(subs, logger)
+
+	start := domain.MonthStart(time.Now().UTC())
+	var ids []uuid.UUID
+	for _, service := range []string{"Okko", "Ivi", "Kion"} {
+		sub := &domain.Subscription{Id: uuid.New(), UserId: uuid.New(), ServiceName: service, Price: 300,
+			StartDate: start}
+		if err := subs.CreateSubscription(context.Background(), sub); err != nil {
+			t.Fatalf("CreateSubscription: %v", err)
+		}
+		ids = append(ids, sub.Id)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	sink := &recordingSink{failing: ids[0], stopAfter: len(ids), stop: cancel, published: make(map[uuid.UUID]int)}
+	retry := domain.RetryPolicy{Base: time.Hour, Max: time.Hour}
+
+	// пачки по одному сообщению приходят полными, поэтому всё разбирается за один тик
+	RunOutboxRelay(ctx, outbox, sink, time.Hour, 1, time.Minute, retry, logger)
+
+	if sink.calls != len(ids) || len(sink.published) != len(ids)-1 {
+		t.Fatalf("relay made %d attempts and published %d events, want %d attempts and %d events",
+			sink.calls, len(sink.published), len(ids), len(ids)-1)
+	}
+	for id, times := range sink.published {
+		if times != 1 {
+			t.Errorf("event %s published %d times, want once", id, times)
+		}
+	}
+
+	var pending []domain.OutboxMessage
+	collect := func(_ context.Context, msg *domain.OutboxMessage) error {
+		pending = append(pending, *msg)
+		return nil
+	}
+
+	now := time.Now().UTC()
+	if relayed, err := outbox.RelayMessages(context.Background(), now, 10, retry, collect); err != nil || relayed != 0 {
+		t.Fatalf("RelayMessages before the retry = %d, %v, want the failed message to wait", relayed, err)
+	}
+
+	if _, err := outbox.RelayMessages(context.Background(), now.Add(retry.Base), 10, retry, collect); err != nil {
+		t.Fatalf("RelayMessages: %v", err)
+	}
+	if len(pending) != 1 || pending[0].SubscriptionId != ids[0] || pending[0].Attempts != 1 ||
+		pending[0].LastError != "sink is down" {
+		t.Fatalf("retried %+v, want the failed message after one attempt", pending)
+	}
+}
+
+// hangingSink не отвечает, пока не отменят контекст публикации, и отменяет разбор после первой попытки
+type hangingSink struct {
+	stop context.CancelFunc
+}
+
+func (s *hangingSink) Publish(ctx context.Context, _ *domain.WebhookEvent) error {
+	defer s.stop()
+
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestRunOutboxRelayTimeout(t *testing.T) {
+	logger := slog.New(slog.DiscardHandler)
+	subs := memory.NewRepository(logger)
+	outbox := memory.NewOutboxRepository(subs, logger)
+
+	sub := &domain.Subscription{Id: uuid.New(), UserId: uuid.New(), ServiceName: "Okko", Price: 300,
+		StartDate: domain.MonthStart(time.Now().UTC())}
+	if err := subs.CreateSubscription(context.Background(), sub); err != nil {
+		t.Fatalf("CreateSubscription: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	retry := domain.RetryPolicy{Base: time.Hour, Max: time.Hour}
+	RunOutboxRelay(ctx, outbox, &hangingSink{stop: cancel}, time.Hour, 1, 10*time.Millisecond, retry, logger)
+
+	var pending []domain.OutboxMessage
+	_, err := outbox.RelayMessages(context.Background(), time.Now().UTC().Add(retry.Base), 10, retry,
+		func(_ context.Context, msg *domain.OutboxMessage) error {
+			pending = append(pending, *msg)
+			return nil
+		})
+	if err != nil {
+		t.Fatalf("RelayMessages: %v", err)
+	}
+	if len(pending) != 1 || pending[0].LastError != context.DeadlineExceeded.Error() {
+		t.Fatalf("retried %+v, want the message that timed out", pending)
+	}
+}
@@ -0,0 +1,40 @@
+// Package worker содержит фоновые задачи сервиса
+package worker
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/Aiszhio/Task/internal/usecase"
+)
+
+// RunPurge раз в interval окончательно удаляет подписки, пролежавшие в корзине дольше retention.
+// Блокируется до отмены ctx.
+func RunPurge(ctx context.Context, uc usecase.SubscriptionUseCase, interval, retention time.Duration,
+	logger *slog.Logger) {
+	every(ctx, interval, func() {
+		purged, err := uc.PurgeSubscriptions(ctx, retention)
+		if err != nil {
+			logger.ErrorContext(ctx, "failed to purge trash", "err", err)
+		} else if purged > 0 {
+			logger.InfoContext(ctx, "trash purged", "count", purged, "retention", retention)
+		}
+	})
+}
+
+// every выполняет task сразу и затем раз в interval, пока не отменён ctx
+func every(ctx context.Context, interval time.Duration, task func()) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		task()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,22 @@
+package worker
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/Aiszhio/Task/internal/repository/postgres"
+)
+
+// RunBucketCleanup раз в interval удаляет вёдра лимитов, не использованные дольше idle. Блокируется до отмены ctx.
+func RunBucketCleanup(ctx context.Context, store postgres.RateLimitRepository, interval, idle time.Duration,
+	logger *slog.Logger) {
+	every(ctx, interval, func() {
+		deleted, err := store.DeleteIdleBuckets(ctx, idle)
+		if err != nil {
+			logger.ErrorContext(ctx, "failed to delete idle rate limit buckets", "err", err)
+		} else if deleted > 0 {
+			logger.Debug("idle rate limit buckets deleted", "count", deleted)
+		}
+	})
+}
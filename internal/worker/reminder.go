@@ -0,0 +1,37 @@
+package worker
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/Aiszhio/Task/internal/repository/postgres"
+	"github.com/Aiszhio/Task/internal/usecase"
+)
+
+// RunReminders раз в interval отправляет напоминания о продлении и окончании подписок.
+// Блокируется до отмены ctx.
+func RunReminders(ctx context.Context, uc usecase.ReminderUseCase, interval time.Duration, logger *slog.Logger) {
+	every(ctx, interval, func() {
+		sent, err := uc.SendReminders(ctx, time.Now().UTC())
+		if err != nil {
+			logger.ErrorContext(ctx, "failed to send reminders", "err", err)
+		} else if sent > 0 {
+			logger.InfoContext(ctx, "reminders sent", "count", sent)
+		}
+	})
+}
+
+// RunReminderCleanup раз в interval удаляет напоминания о событиях, прошедших дольше retention назад.
+// Блокируется до отмены ctx.
+func RunReminderCleanup(ctx context.Context, store postgres.ReminderRepository, interval, retention time.Duration,
+	logger *slog.Logger) {
+	every(ctx, interval, func() {
+		deleted, err := store.DeleteReminders(ctx, retention)
+		if err != nil {
+			logger.ErrorContext(ctx, "failed to delete old reminders", "err", err)
+		} else if deleted > 0 {
+			logger.InfoContext(ctx, "old reminders deleted", "count", deleted)
+		}
+	})
+}
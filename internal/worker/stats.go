@@ -0,0 +1,26 @@
+package worker
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/Aiszhio/Task/internal/metrics"
+	"github.com/Aiszhio/Task/internal/usecase"
+)
+
+// RunStats раз в interval пересчитывает показатели подписок для метрик. Блокируется до отмены ctx.
+func RunStats(ctx context.Context, uc usecase.SubscriptionUseCase, interval time.Duration, m *metrics.Metrics,
+	logger *slog.Logger) {
+	every(ctx, interval, func() {
+		now := time.Now().UTC()
+
+		stats, err := uc.GetSubscriptionStats(ctx, now)
+		if err != nil {
+			logger.ErrorContext(ctx, "failed to refresh subscription stats", "err", err)
+			return
+		}
+
+		m.SetSubscriptionStats(stats.Active, stats.MonthlySpend, now)
+	})
+}
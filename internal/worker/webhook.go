@@ -0,0 +1,41 @@
+package worker
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/Aiszhio/Task/internal/usecase"
+)
+
+// RunWebhookDispatcher раз в interval отправляет наступившие доставки пачками по batch; пока пачки
+// приходят полными, следующая берётся сразу. Блокируется до отмены ctx.
+func RunWebhookDispatcher(ctx context.Context, uc usecase.WebhookUseCase, interval time.Duration, batch int,
+	logger *slog.Logger) {
+	every(ctx, interval, func() {
+		for ctx.Err() == nil {
+			sent, err := uc.DispatchDeliveries(ctx, batch)
+			if err != nil {
+				logger.ErrorContext(ctx, "failed to dispatch webhook deliveries", "err", err)
+				return
+			}
+			if sent < batch {
+				return
+			}
+		}
+	})
+}
+
+// RunEndingSoon раз в interval публикует события о подписках, которые закончатся в ближайшие within.
+// Блокируется до отмены ctx.
+func RunEndingSoon(ctx context.Context, uc usecase.SubscriptionUseCase, interval, within time.Duration,
+	logger *slog.Logger) {
+	every(ctx, interval, func() {
+		notified, err := uc.NotifyEndingSubscriptions(ctx, time.Now().UTC(), within)
+		if err != nil {
+			logger.ErrorContext(ctx, "failed to notify about ending subscriptions", "err", err)
+		} else if notified > 0 {
+			logger.InfoContext(ctx, "ending subscriptions found", "count", notified, "within", within)
+		}
+	})
+}
@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE UNIQUE INDEX IF NOT EXISTS idx_subscriptions_user_service_start
+    ON subscriptions (user_id, service_name, start_date);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_subscriptions_user_service_start;
+-- +goose StatementEnd
@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE subscriptions
+    ADD COLUMN IF NOT EXISTS billing_interval TEXT    NOT NULL DEFAULT 'month'
+        CHECK (billing_interval IN ('week', 'month', 'quarter', 'year')),
+    ADD COLUMN IF NOT EXISTS interval_count   INTEGER NOT NULL DEFAULT 1
+        CHECK (interval_count > 0);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE subscriptions
+    DROP COLUMN IF EXISTS interval_count,
+    DROP COLUMN IF EXISTS billing_interval;
+-- +goose StatementEnd
@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE subscriptions
+    ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP NULL;
+
+CREATE INDEX IF NOT EXISTS idx_subscriptions_deleted_at
+    ON subscriptions (deleted_at)
+    WHERE deleted_at IS NOT NULL;
+
+DROP INDEX IF EXISTS idx_subscriptions_user_service_start;
+CREATE UNIQUE INDEX idx_subscriptions_user_service_start
+    ON subscriptions (user_id, service_name, start_date)
+    WHERE deleted_at IS NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_subscriptions_user_service_start;
+DELETE FROM subscriptions WHERE deleted_at IS NOT NULL;
+CREATE UNIQUE INDEX idx_subscriptions_user_service_start
+    ON subscriptions (user_id, service_name, start_date);
+
+DROP INDEX IF EXISTS idx_subscriptions_deleted_at;
+ALTER TABLE subscriptions
+    DROP COLUMN IF EXISTS deleted_at;
+-- +goose StatementEnd
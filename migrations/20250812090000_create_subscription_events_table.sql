@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS subscription_events (
+    id              BIGSERIAL   PRIMARY KEY,
+    subscription_id UUID        NOT NULL,
+    event_type      TEXT        NOT NULL
+        CHECK (event_type IN ('created', 'updated', 'deleted', 'restored', 'purged')),
+    actor           TEXT        NOT NULL,
+    request_id      TEXT        NULL,
+    before          JSONB       NULL,
+    after           JSONB       NULL,
+    created_at      TIMESTAMP   NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_subscription_events_subscription
+    ON subscription_events (subscription_id, id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_subscription_events_subscription;
+DROP TABLE IF EXISTS subscription_events;
+-- +goose StatementEnd
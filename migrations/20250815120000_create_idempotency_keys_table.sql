@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    key           TEXT        PRIMARY KEY,
+    request_hash  TEXT        NOT NULL,
+    status_code   INTEGER     NULL,
+    content_type  TEXT        NULL,
+    body          BYTEA       NULL,
+    created_at    TIMESTAMP   NOT NULL DEFAULT NOW(),
+    locked_until  TIMESTAMP   NOT NULL,
+    expires_at    TIMESTAMP   NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_idempotency_keys_expires_at
+    ON idempotency_keys (expires_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_idempotency_keys_expires_at;
+DROP TABLE IF EXISTS idempotency_keys;
+-- +goose StatementEnd
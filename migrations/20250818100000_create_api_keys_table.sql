@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS api_keys (
+    id            UUID        PRIMARY KEY,
+    name          TEXT        NOT NULL,
+    prefix        TEXT        NOT NULL,
+    key_hash      TEXT        NOT NULL UNIQUE,
+    scopes        TEXT[]      NOT NULL,
+    created_at    TIMESTAMP   NOT NULL DEFAULT NOW(),
+    last_used_at  TIMESTAMP   NULL,
+    revoked_at    TIMESTAMP   NULL
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS api_keys;
+-- +goose StatementEnd
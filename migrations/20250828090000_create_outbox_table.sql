@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS outbox (
+    id               BIGSERIAL   PRIMARY KEY,
+    subscription_id  UUID        NOT NULL,
+    event_id         UUID        NOT NULL UNIQUE,
+    event_type       TEXT        NOT NULL,
+    payload          JSONB       NOT NULL,
+    attempts         INTEGER     NOT NULL DEFAULT 0,
+    next_attempt_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_error       TEXT        NULL,
+    created_at       TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    published_at     TIMESTAMPTZ NULL,
+    failed_at        TIMESTAMPTZ NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_outbox_unpublished
+    ON outbox (subscription_id, id) WHERE published_at IS NULL AND failed_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_outbox_published_at
+    ON outbox (published_at) WHERE published_at IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS outbox;
+-- +goose StatementEnd
@@ -0,0 +1,36 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS reminders (
+    id               UUID        PRIMARY KEY,
+    subscription_id  UUID        NOT NULL,
+    user_id          UUID        NOT NULL,
+    kind             TEXT        NOT NULL,
+    due_at           TIMESTAMPTZ NOT NULL,
+    window_seconds   BIGINT      NOT NULL,
+    channel          TEXT        NOT NULL,
+    status           TEXT        NOT NULL DEFAULT 'pending',
+    attempts         INTEGER     NOT NULL DEFAULT 0,
+    next_attempt_at  TIMESTAMPTZ NULL,
+    last_error       TEXT        NULL,
+    created_at       TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    sent_at          TIMESTAMPTZ NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_reminders_user
+    ON reminders (user_id, created_at DESC);
+
+CREATE INDEX IF NOT EXISTS idx_reminders_due_at
+    ON reminders (due_at);
+
+CREATE TABLE IF NOT EXISTS reminder_contacts (
+    user_id     UUID        PRIMARY KEY,
+    email       TEXT        NOT NULL,
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS reminder_contacts;
+DROP TABLE IF EXISTS reminders;
+-- +goose StatementEnd
@@ -0,0 +1,7 @@
+// Package migrations встраивает SQL-миграции goose в бинарник, чтобы сервис применял их сам
+package migrations
+
+import "embed"
+
+//go:embed *.sql
+var FS embed.FS